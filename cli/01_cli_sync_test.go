@@ -0,0 +1,203 @@
+// /home/krylon/go/src/github.com/blicero/jobq/cli/01_cli_sync_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 14:37:09 krylon>
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/client"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+	"github.com/blicero/jobq/monitor"
+)
+
+var (
+	mon   *monitor.Monitor
+	shell *CLI
+)
+
+func TestCLICreate(t *testing.T) {
+	const name = "TestCLI"
+
+	var err error
+
+	socketPath = fmt.Sprintf("/tmp/jobq.%s.%s.%d",
+		os.Getenv("USER"),
+		name,
+		os.Getpid())
+
+	if mon, err = monitor.Create(name, socketPath, 2); err != nil {
+		mon = nil
+		t.Fatalf("Cannot create Monitor: %s", err.Error())
+	}
+
+	mon.Start()
+
+	if shell, err = Create(); err != nil {
+		shell = nil
+		t.Fatalf("Cannot create CLI: %s", err.Error())
+	} else if shell.client, err = client.DialPath(socketPath); err != nil {
+		shell = nil
+		t.Fatalf("Cannot connect to Monitor: %s", err.Error())
+	}
+} // func TestCLICreate(t *testing.T)
+
+// cancelJob cancels the running Job whose command is cmd, once there is one.
+func cancelJob(t *testing.T, cmd string) {
+	var ctx = context.Background()
+
+	for i := 0; i < 100; i++ {
+		var jobs, err = shell.client.Status(ctx)
+
+		if err != nil {
+			t.Errorf("Cannot query status: %s", err.Error())
+			return
+		}
+
+		for _, j := range jobs {
+			if j.Status() != status.Started || strings.Join(j.Cmd, " ") != cmd {
+				continue
+			} else if err = shell.client.Cancel(ctx, j.ID); err != nil {
+				t.Errorf("Cannot cancel Job %d: %s", j.ID, err.Error())
+			}
+			return
+		}
+
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	t.Errorf("Job %q was never started", cmd)
+} // func cancelJob(t *testing.T, cmd string)
+
+func TestCLISync(t *testing.T) {
+	if shell == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		opt    job.Options
+		script string
+		cancel bool
+		stdout string
+		stderr string
+		code   int
+	}
+
+	var (
+		err error
+		tmp string
+		env = job.EnvFilter{Mode: job.EnvFull}
+	)
+
+	if tmp, err = os.MkdirTemp("", "jobq-sync"); err != nil {
+		t.Fatalf("Cannot create temporary directory: %s", err.Error())
+	}
+
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	var (
+		marker = filepath.Join(tmp, "flag")
+		retry  = fmt.Sprintf(
+			"if [ -e %[1]s ]; then echo second; exit 4; else touch %[1]s; echo first; echo oops >&2; exit 3; fi",
+			marker)
+		testCases = []testCase{
+			// Finished
+			{
+				script: "echo hello; echo oops >&2; sleep 0.5; echo world",
+				stdout: "hello\nworld\n",
+				stderr: "oops\n",
+			},
+			// Failed
+			{
+				script: "echo hello; exit 3",
+				stdout: "hello\n",
+				code:   3,
+			},
+			// Merged output is relayed to stdout only.
+			{
+				opt:    job.Options{Output: job.OutputMerge},
+				script: "echo hello; echo oops >&2; exit 2",
+				stdout: "hello\noops\n",
+				code:   2,
+			},
+			// Timed out
+			{
+				opt:    job.Options{MaxDuration: time.Millisecond * 500},
+				script: "echo hello; sleep 5; echo world",
+				stdout: "hello\n",
+				code:   1,
+			},
+			// Cancelled
+			{
+				script: "echo hello; sleep 6; echo world",
+				cancel: true,
+				stdout: "hello\n",
+				code:   1,
+			},
+			// Retried, the output of each attempt is relayed, and the
+			// exit code is that of the last one.
+			{
+				opt: job.Options{
+					Retry: job.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond * 100},
+				},
+				script: retry,
+				stdout: "first\nsecond\n",
+				stderr: "oops\n",
+				code:   4,
+			},
+		}
+	)
+
+	for idx, c := range testCases {
+		var (
+			code           int
+			stdout, stderr bytes.Buffer
+			proto          = job.Job{Options: c.opt}
+		)
+
+		proto.Directory = tmp
+
+		shell.stdout = &stdout
+		shell.stderr = &stderr
+
+		if c.cancel {
+			go cancelJob(t, "/bin/sh -c "+c.script)
+		}
+
+		if code, err = shell.syncJob(context.Background(), &proto, &env, []string{"/bin/sh", "-c", c.script}); err != nil {
+			t.Errorf("Test case %d: syncJob failed: %s", idx, err.Error())
+			continue
+		} else if code != c.code {
+			t.Errorf("Test case %d: unexpected exit code %d (expected %d)",
+				idx,
+				code,
+				c.code)
+		}
+
+		// The first line on stderr is ours.
+		var _, relayed, _ = strings.Cut(stderr.String(), "\n")
+
+		if stdout.String() != c.stdout {
+			t.Errorf("Test case %d: unexpected stdout:\nExpected: %q\nActual:   %q",
+				idx,
+				c.stdout,
+				stdout.String())
+		} else if relayed != c.stderr {
+			t.Errorf("Test case %d: unexpected stderr:\nExpected: %q\nActual:   %q",
+				idx,
+				c.stderr,
+				relayed)
+		}
+	}
+} // func TestCLISync(t *testing.T)
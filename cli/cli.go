@@ -8,111 +8,269 @@
 package cli
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/blicero/jobq/client"
 	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
 	"github.com/blicero/jobq/logdomain"
 	"github.com/blicero/jobq/monitor"
-	"github.com/blicero/jobq/monitor/request"
+	"github.com/blicero/jobq/schedule"
 )
 
-func socketPath(queueName string) string {
-	return fmt.Sprintf("/tmp/jobq.%s.%s.socket",
-		os.Getenv("USER"),
-		queueName)
-}
+// stringList is a flag.Value that collects all values given for a flag that
+// is used more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+} // func (l *stringList) String() string
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+} // func (l *stringList) Set(s string) error
 
 // CLI provides the terminal based user interface of the application.
 type CLI struct {
-	log  *log.Logger
-	conn *net.UnixConn
-	addr net.UnixAddr
+	log    *log.Logger
+	client *client.Client
+	path   string
+	// stdout and stderr receive the output of Jobs run with -sync.
+	stdout io.Writer
+	stderr io.Writer
 }
 
 // Create creates a new CLI instance which connects to the given socket.
 func Create() (*CLI, error) {
 	var (
 		err   error
-		shell = new(CLI)
+		shell = &CLI{
+			stdout: os.Stdout,
+			stderr: os.Stderr,
+		}
 	)
 
 	// We cannot connect before parsing the command line arguments, obviously.
 	if shell.log, err = common.GetLogger(logdomain.CLI); err != nil {
 		return nil, err
-	} /* else if shell.conn, err = net.DialUnix(common.NetName, nil, &addr); err != nil {
-		shell.log.Printf("[ERROR] Cannot connect to Unix socket %s: %s\n",
-			path,
-			err.Error())
-		return nil, err
-	} */
+	}
 
 	return shell, nil
 } // func Create(path string) (*CLI, error)
 
-func (c *CLI) Execute() {
+// Execute parses the command line arguments and does whatever they ask for.
+// It returns the exit code for the process.
+//
+// Any arguments that are left after parsing the flags are treated as a
+// command to submit as a new Job, e.g.
+//
+//	jobq -dir /srv/build -timeout 2h -nice 10 -- make -j4
+func (c *CLI) Execute() int {
 	var (
 		startServer, clean bool
+		listSchedules      bool
+		wait, waitAny      bool
+		syncMode           bool
+		waitTimeout        time.Duration
 		slots              int
+		cancelID, prioID   int64
+		pauseID, resumeID  int64
+		unscheduleID       int64
+		rerunID, tailID    int64
+		tailErr            bool
+		cronSpec, missed   string
+		aging              time.Duration
 		queueName          string
+		ioclass, envMode   string
+		restart, output    string
+		at                 string
+		retention          monitor.Retention
+		eventPipe          string
+		proto              job.Job
+		envFilter          job.EnvFilter
 		err                error
+		code               int
 	)
 
 	flag.StringVar(&queueName, "name", "default", "Name of the job queue to use")
 	flag.BoolVar(&startServer, "server", false, "Start the JobQ daemon.")
-	flag.BoolVar(&clean, "clean", false, "clean up finished jobs")
+	flag.BoolVar(&clean, "clean", false, "Remove finished Jobs and their output, all of them, unless -keep, -older-than or -max-spool say otherwise")
+	flag.IntVar(&retention.Keep, "keep", 0, "With -clean or -server, keep this many of the most recently finished Jobs")
+	flag.Func("older-than", "With -clean or -server, keep finished Jobs that are younger than this, e.g. 36h or 7d", func(s string) error {
+		var err error
+		retention.MaxAge, err = monitor.ParseAge(s)
+		return err
+	})
+	flag.Int64Var(&retention.MaxSize, "max-spool", 0, "With -clean or -server, remove older Jobs once the output of the Jobs we keep takes up more than this many bytes")
 	flag.IntVar(&slots, "slots", 1, "Number of jobs to run in parallel")
+	flag.Int64Var(&cancelID, "cancel", 0, "Cancel the Job with the given ID")
+	flag.Int64Var(&prioID, "set-priority", 0, "Change the priority of the pending Job with the given ID to the value of -priority")
+	flag.Int64Var(&tailID, "tail", 0, "Show the output of the Job with the given ID, until the Job has finished")
+	flag.BoolVar(&tailErr, "stderr", false, "With -tail, show the Job's stderr instead of its stdout")
+	flag.BoolVar(&wait, "wait", false, "Wait for the Jobs whose IDs are given as arguments to finish, and exit with the exit code of the last one, like the shell's wait")
+	flag.BoolVar(&waitAny, "any", false, "With -wait, return as soon as any of the Jobs has finished, and exit with its exit code")
+	flag.DurationVar(&waitTimeout, "wait-timeout", 0, "With -wait, give up after this long and exit with 124 (0 means wait forever)")
+	flag.BoolVar(&syncMode, "sync", false, "Submit the Job, show its output as it runs, and exit with its exit code (with -retry, the output of every attempt and the last exit code)")
+	flag.Int64Var(&rerunID, "rerun", 0, "Submit a copy of the finished Job with the given ID, a command and Job options given on the command line replace those of the original")
+	flag.StringVar(&cronSpec, "cron", "", "Run the command repeatedly, according to this cron expression, e.g. \"*/15 * * * *\" or @daily")
+	flag.StringVar(&missed, "missed", "skip", "What to do about missed runs of a Schedule (skip, once, catchup)")
+	flag.BoolVar(&listSchedules, "schedules", false, "List all Schedules")
+	flag.Int64Var(&pauseID, "pause", 0, "Pause the Schedule with the given ID")
+	flag.Int64Var(&resumeID, "resume", 0, "Resume the paused Schedule with the given ID")
+	flag.Int64Var(&unscheduleID, "unschedule", 0, "Delete the Schedule with the given ID")
+	flag.StringVar(&eventPipe, "events", "", "Write a line of JSON to this named pipe whenever a Job has finished (server only)")
+	flag.DurationVar(&aging, "aging", 0, "Raise the priority of pending Jobs by one each time this interval passes (server only, 0 means never)")
+
+	// Options for submitting Jobs
+	flag.IntVar(&proto.Priority, "priority", 0, "Priority of the Job, Jobs with higher priority are started first")
+	flag.StringVar(&proto.Directory, "dir", "", "Directory to run the Job in (default: the current directory)")
+	flag.DurationVar(&proto.MaxDuration, "timeout", 0, "Terminate the Job if it runs longer than this")
+	flag.IntVar(&proto.Nice, "nice", 0, "Nice value to run the Job with")
+	flag.BoolFunc("gzip", "Compress the Job's output with gzip", func(string) error {
+		proto.Compress = job.CompressGzip
+		return nil
+	})
+	flag.BoolFunc("zstd", "Compress the Job's output with zstd", func(string) error {
+		proto.Compress = job.CompressZstd
+		return nil
+	})
+	flag.StringVar(&output, "output", "none", "How to spool the Job's output, a list of merge (stdout and stderr go to one file), timestamps, tags (prefix each line with the time or the stream)")
+	flag.StringVar(&ioclass, "ioclass", "none", "I/O scheduling class (none, realtime, best-effort, idle)")
+	flag.IntVar(&proto.IOPriority, "ioprio", 0, "I/O priority within the scheduling class (0-7)")
+	flag.Int64Var(&proto.Limits.AddressSpace, "mem", 0, "Maximum size of the Job's address space in bytes")
+	flag.Int64Var(&proto.Limits.CPUSeconds, "cpu", 0, "Maximum CPU time of the Job in seconds")
+	flag.Int64Var(&proto.Limits.OpenFiles, "nofile", 0, "Maximum number of open files for the Job")
+	flag.Int64Var(&proto.Limits.Processes, "nproc", 0, "Maximum number of processes for the Job")
+	flag.StringVar(&proto.Hook, "hook", "", "Shell command to run when the Job has finished, with JOBQ_ID, JOBQ_EXIT, JOBQ_STDOUT, JOBQ_STDERR etc. set; with -server, run it for every Job")
+	flag.StringVar(&restart, "restart", "never", "What to do if the Job's process is gone after the server was restarted (never, requeue)")
+	flag.IntVar(&proto.Retry.MaxAttempts, "attempts", 1, "Run the Job up to this many times until it succeeds")
+	flag.DurationVar(&proto.Retry.Backoff, "backoff", time.Second*10, "Wait this long before retrying a failed Job, doubling the delay with each attempt")
+	flag.DurationVar(&proto.Retry.MaxBackoff, "max-backoff", time.Hour, "Maximum delay between attempts to run a failed Job")
+	flag.Func("retry-on", "Only retry the Job if it fails with one of these exit codes, e.g. 1,75 (default: any failure)", func(s string) error {
+		var codes, err = job.ParseExitCodes(s)
+		proto.Retry.ExitCodes = append(proto.Retry.ExitCodes, codes...)
+		return err
+	})
+	flag.StringVar(&envMode, "env", "full", "Environment to pass to the Job (full, select, clean, inherit)")
+	flag.Var((*stringList)(&envFilter.Allow), "env-allow", "Pass this variable to the Job (may be used more than once, wildcards are allowed)")
+	flag.Var((*stringList)(&envFilter.Deny), "env-deny", "Do not pass this variable to the Job (may be used more than once, wildcards are allowed)")
+	flag.Var((*stringList)(&envFilter.Set), "setenv", "Set KEY=VALUE in the Job's environment (may be used more than once)")
+	flag.StringVar(&at, "at", "", "Do not start the Job before this time, e.g. \"2026-10-19 02:00\", \"02:00\" or \"+2h\"")
+	flag.Func("after", "Start the Job only after other Jobs have finished, e.g. afterok:12:13 (afterok, afternotok, afterany; may be used more than once)", func(s string) error {
+		var deps, err = job.ParseDependency(s)
+		proto.Depends = append(proto.Depends, deps...)
+		return err
+	})
 
 	flag.Parse()
 
-	c.addr = net.UnixAddr{
-		Net:  common.NetName,
-		Name: socketPath(queueName),
-	}
+	c.path = common.SocketPath(queueName)
 
 	if startServer {
-		c.runMonitor(queueName, slots)
-		return
-	} else if err = c.connect(); err != nil {
-		return
+		c.runMonitor(queueName, slots, aging, retention, proto.Hook, eventPipe)
+		return 0
+	} else if proto.IOClass, err = job.ParseIOClass(ioclass); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	} else if envFilter.Mode, err = job.ParseEnvMode(envMode); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	} else if proto.Restart, err = job.ParseRestartPolicy(restart); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	} else if proto.Output, err = job.ParseOutputMode(output); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
 	}
 
-	defer c.conn.Close() // nolint: errcheck
+	if at != "" {
+		if proto.NotBefore, err = job.ParseNotBefore(at, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	}
+
+	if err = c.connect(); err != nil {
+		return 1
+	}
+
+	defer c.client.Close() // nolint: errcheck
+
+	var ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	if clean {
-		// Later
+		err = c.clean(ctx, &retention)
+	} else if cancelID != 0 {
+		err = c.cancelJob(ctx, cancelID)
+	} else if prioID != 0 {
+		err = c.setPriority(ctx, prioID, proto.Priority)
+	} else if listSchedules {
+		err = c.displaySchedules(ctx)
+	} else if pauseID != 0 {
+		err = c.updateSchedule(ctx, c.client.PauseSchedule, pauseID, "paused")
+	} else if resumeID != 0 {
+		err = c.updateSchedule(ctx, c.client.ResumeSchedule, resumeID, "resumed")
+	} else if unscheduleID != 0 {
+		err = c.updateSchedule(ctx, c.client.DeleteSchedule, unscheduleID, "deleted")
+	} else if tailID != 0 {
+		err = c.tailJob(ctx, tailID, tailErr)
+	} else if rerunID != 0 {
+		err = c.rerunJob(ctx, rerunID, &proto, &envFilter, flag.Args())
+	} else if wait {
+		code, err = c.waitJobs(ctx, flag.Args(), waitAny, waitTimeout)
 	} else if len(flag.Args()) == 0 {
-		c.displayQueue()
+		err = c.displayQueue(ctx)
+	} else if cronSpec != "" {
+		err = c.createSchedule(ctx, cronSpec, missed, &proto, &envFilter, flag.Args())
+	} else if syncMode {
+		code, err = c.syncJob(ctx, &proto, &envFilter, flag.Args())
+	} else {
+		err = c.submitJob(ctx, &proto, &envFilter, flag.Args())
+	}
+
+	if err != nil && code == 0 {
+		return 1
 	}
-} // func (c *CLI) Execute()
+
+	return code
+} // func (c *CLI) Execute() int
 
 func (c *CLI) connect() error {
 	var err error
-	if c.conn, err = net.DialUnix(common.NetName, nil, &c.addr); err != nil {
-		c.conn = nil
+	if c.client, err = client.DialPath(c.path); err != nil {
+		c.client = nil
 		c.log.Printf("[ERROR] Cannot connect to socket %s: %s\n",
-			c.addr.Name,
+			c.path,
 			err.Error())
+		fmt.Fprintf(os.Stderr, "Cannot connect to Monitor: %s\n", err.Error())
 	}
 	return err
 } // func (c *CLI) connect() error
 
-func (c *CLI) runMonitor(name string, slots int) {
+func (c *CLI) runMonitor(name string, slots int, aging time.Duration, retention monitor.Retention, hook, eventPipe string) {
 	var (
 		sock string
 		err  error
 		mon  *monitor.Monitor
 	)
 
-	sock = socketPath(name)
+	sock = common.SocketPath(name)
 
 	if mon, err = monitor.Create(name, sock, slots); err != nil {
 		c.log.Printf("[ERROR] Failed to create Monitor: %s\n",
@@ -120,8 +278,19 @@ func (c *CLI) runMonitor(name string, slots int) {
 		return
 	}
 
+	defer os.Remove(sock) // nolint: errcheck
+
+	mon.SetAging(aging)
+	mon.SetRetention(retention)
+	mon.SetHook(hook)
+
+	if eventPipe != "" {
+		if err = mon.SetEventPipe(eventPipe); err != nil {
+			return
+		}
+	}
+
 	mon.Start()
-	defer os.Remove(sock)
 
 	var sigQ = make(chan os.Signal, 1)
 	var ticker = time.NewTicker(time.Second)
@@ -137,53 +306,561 @@ func (c *CLI) runMonitor(name string, slots int) {
 			break
 		}
 	}
-} // func (c *CLI) runMonitor(name string, slots int)
+} // func (c *CLI) runMonitor(name string, slots int, aging time.Duration, retention monitor.Retention, hook, eventPipe string)
+
+// report tells the user why a request failed and returns the error.
+func (c *CLI) report(err error) error {
+	var e *monitor.Error
+
+	if errors.Is(err, context.Canceled) {
+		// The user has interrupted us, they know why.
+		return err
+	} else if errors.As(err, &e) {
+		fmt.Fprintln(os.Stderr, e.Status)
+		return err
+	}
+
+	c.log.Printf("[ERROR] Request to Monitor at %s failed: %s\n",
+		c.path,
+		err.Error())
+	fmt.Fprintf(os.Stderr, "Cannot talk to Monitor: %s\n", err.Error())
+	return err
+} // func (c *CLI) report(err error) error
 
-func (c *CLI) displayQueue() {
+// newJob creates a Job running cmd. The Options, Priority, Depends and
+// NotBefore are taken from proto.
+func (c *CLI) newJob(proto *job.Job, env *job.EnvFilter, cmd []string) (*job.Job, error) {
 	var (
-		err            error
-		msg            monitor.Message
-		cnt            int
-		res            monitor.Response
-		sndbuf, rcvbuf []byte
+		err error
+		j   *job.Job
 	)
 
-	rcvbuf = make([]byte, common.BufferSize)
+	if proto.Directory == "" {
+		if proto.Directory, err = os.Getwd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot determine current directory: %s\n",
+				err.Error())
+			return nil, err
+		}
+	}
 
-	msg = monitor.Message{
-		Timestamp: time.Now(),
-		Request:   request.QueueQueryStatus.String(),
+	if j, err = job.New(proto.Options, cmd...); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create Job: %s\n", err.Error())
+		return nil, err
+	} else if j.Env, err = env.Apply(os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot prepare environment for Job: %s\n", err.Error())
+		return nil, err
 	}
 
-	if sndbuf, err = json.Marshal(&msg); err != nil {
-		c.log.Printf("[ERROR] Cannot serialize Message: %s\n",
-			err.Error())
-		return
-	} else if _, err = c.conn.Write(sndbuf); err != nil {
-		c.log.Printf("[ERROR] Failed to send via socket %s: %s\n",
-			c.addr,
-			err.Error())
-		return
-	} else if cnt, err = c.conn.Read(rcvbuf); err != nil {
-		c.log.Printf("[ERROR] Failed to read from socket: %s\n",
-			err.Error())
-		return
-	} else if err = json.Unmarshal(rcvbuf[:cnt], &res); err != nil {
-		c.log.Printf("[ERROR] Cannot parse response: %s\n\n%s\n",
-			err.Error(),
-			rcvbuf[:cnt])
-		return
+	j.EnvSet = env.Inherited()
+	j.Priority = proto.Priority
+	j.Depends = proto.Depends
+	j.NotBefore = proto.NotBefore
+
+	return j, nil
+} // func (c *CLI) newJob(proto *job.Job, env *job.EnvFilter, cmd []string) (*job.Job, error)
+
+// submitJob submits a new Job running cmd, see newJob.
+func (c *CLI) submitJob(ctx context.Context, proto *job.Job, env *job.EnvFilter, cmd []string) error {
+	var (
+		err error
+		id  int64
+		j   *job.Job
+	)
+
+	if j, err = c.newJob(proto, env, cmd); err != nil {
+		return err
+	} else if id, err = c.client.Submit(ctx, j); err != nil {
+		return c.report(err)
 	}
 
-	const jobTmpl = "%6d %6d %3d %s\n"
+	fmt.Println(id)
+	return nil
+} // func (c *CLI) submitJob(ctx context.Context, proto *job.Job, env *job.EnvFilter, cmd []string) error
+
+// overrideFields maps the command line flags for Job options to the fields
+// of job.Job they set, see job.Job.Override.
+var overrideFields = map[string]string{
+	"priority":    "Priority",
+	"dir":         "Directory",
+	"timeout":     "MaxDuration",
+	"nice":        "Nice",
+	"gzip":        "Compress",
+	"zstd":        "Compress",
+	"output":      "Output",
+	"ioclass":     "IOClass",
+	"ioprio":      "IOPriority",
+	"mem":         "Limits.AddressSpace",
+	"cpu":         "Limits.CPUSeconds",
+	"nofile":      "Limits.OpenFiles",
+	"nproc":       "Limits.Processes",
+	"restart":     "Restart",
+	"hook":        "Hook",
+	"attempts":    "Retry.MaxAttempts",
+	"backoff":     "Retry.Backoff",
+	"max-backoff": "Retry.MaxBackoff",
+	"retry-on":    "Retry.ExitCodes",
+	"env":         "Env",
+	"env-allow":   "Env",
+	"env-deny":    "Env",
+	"setenv":      "Env",
+	"at":          "NotBefore",
+	"after":       "Depends",
+}
 
-	for _, j := range res.Jobs {
-		var cmd = strings.Join(j.Cmd, " ")
-		fmt.Printf(jobTmpl, j.ID, j.PID, j.ExitCode, cmd)
+// rerunJob submits a copy of the finished Job with the given ID. Options
+// that were given explicitly on the command line are taken from proto, and
+// if cmd is not empty, it replaces the original command.
+func (c *CLI) rerunJob(ctx context.Context, id int64, proto *job.Job, env *job.EnvFilter, cmd []string) error {
+	var (
+		err      error
+		newID    int64
+		override []string
+		seen     = make(map[string]bool)
+	)
+
+	flag.Visit(func(f *flag.Flag) {
+		if field, ok := overrideFields[f.Name]; ok && !seen[field] {
+			seen[field] = true
+			override = append(override, field)
+		}
+	})
+
+	if len(cmd) > 0 {
+		proto.Cmd = cmd
+		override = append(override, "Cmd")
+	}
+
+	if seen["Env"] {
+		if proto.Env, err = env.Apply(os.Environ()); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot prepare environment for Job: %s\n", err.Error())
+			return err
+		}
+
+		proto.EnvSet = env.Inherited()
+	}
+
+	if newID, err = c.client.Rerun(ctx, id, proto, override); err != nil {
+		return c.report(err)
+	}
+
+	fmt.Println(newID)
+	return nil
+} // func (c *CLI) rerunJob(ctx context.Context, id int64, proto *job.Job, env *job.EnvFilter, cmd []string) error
+
+// createSchedule creates a Schedule that runs cmd according to the cron
+// expression spec. The Options and Priority of the Jobs are taken from proto.
+func (c *CLI) createSchedule(ctx context.Context, spec, missed string, proto *job.Job, env *job.EnvFilter, cmd []string) error {
+	var (
+		err error
+		s   = &schedule.Schedule{
+			Spec:     spec,
+			Cmd:      cmd,
+			Options:  proto.Options,
+			Priority: proto.Priority,
+		}
+	)
+
+	if s.Missed, err = schedule.ParsePolicy(missed); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return err
+	} else if s.Options.Directory == "" {
+		if s.Options.Directory, err = os.Getwd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot determine current directory: %s\n",
+				err.Error())
+			return err
+		}
+	}
+
+	if s.Env, err = env.Apply(os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot prepare environment for Schedule: %s\n", err.Error())
+		return err
+	}
+
+	s.EnvSet = env.Inherited()
+
+	if s, err = c.client.CreateSchedule(ctx, s); err != nil {
+		return c.report(err)
+	}
+
+	fmt.Println(s.ID)
+	return nil
+} // func (c *CLI) createSchedule(ctx context.Context, spec, missed string, proto *job.Job, env *job.EnvFilter, cmd []string) error
+
+// updateSchedule pauses, resumes or deletes a Schedule, depending on op.
+func (c *CLI) updateSchedule(ctx context.Context, op func(context.Context, int64) error, id int64, verb string) error {
+	if err := op(ctx, id); err != nil {
+		return c.report(err)
+	}
+
+	fmt.Printf("Schedule %d has been %s\n", id, verb)
+	return nil
+} // func (c *CLI) updateSchedule(ctx context.Context, op func(context.Context, int64) error, id int64, verb string) error
+
+func (c *CLI) displaySchedules(ctx context.Context) error {
+	var (
+		err   error
+		sched []schedule.Schedule
+	)
+
+	if sched, err = c.client.Schedules(ctx); err != nil {
+		return c.report(err)
+	}
+
+	const schedTmpl = "%6d %-6s %-7s %-16s %-16s %-20s %s\n"
+
+	for _, s := range sched {
+		var (
+			state   = "active"
+			lastRun = "never"
+			cmd     = strings.Join(s.Cmd, " ")
+		)
+
+		if s.Paused {
+			state = "paused"
+		}
+
+		if !s.LastRun.IsZero() {
+			lastRun = s.LastRun.Format(common.TimestampFormatMinute)
+		}
+
+		fmt.Printf(schedTmpl,
+			s.ID,
+			state,
+			s.Missed,
+			lastRun,
+			s.NextRun.Format(common.TimestampFormatMinute),
+			s.Spec,
+			cmd)
+	}
+
+	fmt.Println("")
+	return nil
+} // func (c *CLI) displaySchedules(ctx context.Context) error
+
+func (c *CLI) cancelJob(ctx context.Context, id int64) error {
+	if err := c.client.Cancel(ctx, id); err != nil {
+		return c.report(err)
+	}
+
+	fmt.Printf("Job %d has been cancelled\n", id)
+	return nil
+} // func (c *CLI) cancelJob(ctx context.Context, id int64) error
+
+// clean removes the finished Jobs the Retention policy does not keep, or
+// all of them, if it is zero.
+func (c *CLI) clean(ctx context.Context, r *monitor.Retention) error {
+	var (
+		err     error
+		removed []job.Job
+	)
+
+	if r.IsZero() {
+		r = nil
+	}
+
+	if removed, err = c.client.Clear(ctx, r); err != nil {
+		return c.report(err)
+	}
+
+	for _, j := range removed {
+		fmt.Printf("%d\t%s\n", j.ID, strings.Join(j.Cmd, " "))
+	}
+
+	fmt.Printf("Removed %d finished Jobs\n", len(removed))
+	return nil
+} // func (c *CLI) clean(ctx context.Context, r *monitor.Retention) error
+
+func (c *CLI) setPriority(ctx context.Context, id int64, prio int) error {
+	if err := c.client.SetPriority(ctx, id, prio); err != nil {
+		return c.report(err)
+	}
+
+	fmt.Printf("Priority of Job %d is now %d\n", id, prio)
+	return nil
+} // func (c *CLI) setPriority(ctx context.Context, id int64, prio int) error
+
+// tailJob copies the output of a Job to our own stdout, or stderr, if
+// stderr is true, until the Job has finished.
+func (c *CLI) tailJob(ctx context.Context, id int64, stderr bool) error {
+	var out = os.Stdout
+
+	if stderr {
+		out = os.Stderr
+	}
+
+	if err := c.client.Tail(ctx, id, stderr, out); err != nil {
+		return c.report(err)
+	}
+
+	return nil
+} // func (c *CLI) tailJob(ctx context.Context, id int64, stderr bool) error
+
+// waitTimedOut is the exit code of -wait if the Jobs were not done in time,
+// the same timeout(1) uses.
+const waitTimedOut = 124
+
+// exitStatus returns the exit code for a Job that is done: the Job's own, if
+// it ran to completion, or 1 if it did not, unless its process was killed
+// after it had exited with an error.
+func exitStatus(j *job.Job) int {
+	if j.Status() == status.Finished && j.ExitCode >= 0 {
+		return j.ExitCode
+	} else if j.ExitCode > 0 {
+		return j.ExitCode
+	}
+
+	return 1
+} // func exitStatus(j *job.Job) int
+
+// waitJobs waits for the Jobs whose IDs are given in args to finish, or any
+// one of them, if anyJob is true. Like the shell's wait, it returns the exit
+// code of the last Job listed, or the one that finished first.
+func (c *CLI) waitJobs(ctx context.Context, args []string, anyJob bool, timeout time.Duration) (int, error) {
+	var (
+		err  error
+		jobs []job.Job
+		ids  = make([]int64, len(args))
+	)
+
+	if len(args) == 0 {
+		err = errors.New("-wait requires the IDs of the Jobs to wait for")
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1, err
+	}
+
+	for i, arg := range args {
+		if ids[i], err = strconv.ParseInt(arg, 10, 64); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid Job ID %q: %s\n",
+				arg,
+				err.Error())
+			return 1, err
+		}
+	}
+
+	if jobs, err = c.client.WaitJobs(ctx, ids, anyJob, timeout); err != nil {
+		if errors.Is(err, client.ErrTimeout) {
+			return waitTimedOut, c.report(err)
+		}
+		return 1, c.report(err)
+	} else if len(jobs) != len(ids) {
+		fmt.Fprintln(os.Stderr, client.ErrUnexpectedResponse.Error())
+		return 1, client.ErrUnexpectedResponse
+	} else if !anyJob {
+		return exitStatus(&jobs[len(jobs)-1]), nil
+	}
+
+	for i := range jobs {
+		if jobs[i].Status().Final() {
+			return exitStatus(&jobs[i]), nil
+		}
+	}
+
+	return 1, client.ErrUnexpectedResponse
+} // func (c *CLI) waitJobs(ctx context.Context, args []string, anyJob bool, timeout time.Duration) (int, error)
+
+// interrupted is the exit code of -sync if the user interrupted us and left
+// the Job in the queue, the same a shell uses for a process killed by SIGINT.
+const interrupted = 130
+
+// syncJob submits a new Job running cmd, see newJob, and relays its stdout
+// and stderr to our own until it has finished. It returns the Job's exit
+// code, see exitStatus. If the Job is retried, the output of each attempt is
+// shown in turn, and the exit code is that of the last attempt.
+// If the user interrupts us, we offer to cancel the Job. If they decline, we
+// leave the Job in the queue and return right away. Interrupting us again
+// while we ask counts as a yes.
+func (c *CLI) syncJob(ctx context.Context, proto *job.Job, env *job.EnvFilter, cmd []string) (int, error) {
+	var (
+		err     error
+		id      int64
+		j       *job.Job
+		tails   sync.WaitGroup
+		waitErr error
+		waitQ   = make(chan *job.Job, 1)
+		sigQ    = make(chan os.Signal, 1)
+	)
+
+	if j, err = c.newJob(proto, env, cmd); err != nil {
+		return 1, err
+	} else if id, err = c.client.Submit(ctx, j); err != nil {
+		return 1, c.report(err)
+	}
+
+	fmt.Fprintf(c.stderr, "Submitted Job %d\n", id)
+
+	// Interrupting us must not stop the output or the waiting, unless the
+	// user wants to leave the Job alone, so those get a Context of their
+	// own.
+	var bg, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var tail = func(stderr bool, w io.Writer) {
+		defer tails.Done()
+
+		// Once the Job has finished, asking for the output of an
+		// attempt it did not make fails with ErrInvalidState. The
+		// same goes for a Job that ends without having been started,
+		// which has no output to show. Neither is worth complaining
+		// about.
+		for attempt := 1; ; attempt++ {
+			var err = c.client.TailAttempt(bg, id, attempt, stderr, w)
+
+			if err == nil {
+				continue
+			} else if bg.Err() == nil && !errors.Is(err, client.ErrInvalidState) {
+				c.report(err) // nolint: errcheck
+			}
+
+			return
+		}
+	}
+
+	tails.Add(1)
+	go tail(false, c.stdout)
+
+	// With merged output, the Monitor sends both streams either way.
+	if j.Output&job.OutputMerge == 0 {
+		tails.Add(1)
+		go tail(true, c.stderr)
+	}
+
+	go func() {
+		var j *job.Job
+		j, waitErr = c.client.Wait(bg, id)
+		waitQ <- j
+	}()
+
+	// ctx is cancelled by the first interrupt, so we watch for signals
+	// ourselves to notice the ones that follow.
+	signal.Notify(sigQ, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigQ)
+
+	var intr = sigQ
+
+	for {
+		select {
+		case <-intr:
+			// Further interrupts are not our concern anymore, except
+			// as an answer to our question.
+			intr = nil
+
+			if !c.confirm(fmt.Sprintf("Cancel Job %d?", id), sigQ) {
+				fmt.Fprintf(c.stderr, "Job %d is still in the queue\n", id)
+				return interrupted, context.Canceled
+			} else if err = c.client.Cancel(bg, id); err != nil {
+				return 1, c.report(err)
+			}
+		case j = <-waitQ:
+			// The Job is done, so the output ends, too.
+			tails.Wait()
+
+			if j == nil {
+				return 1, c.report(waitErr)
+			}
+
+			return exitStatus(j), nil
+		}
+	}
+} // func (c *CLI) syncJob(ctx context.Context, proto *job.Job, env *job.EnvFilter, cmd []string) (int, error)
+
+// confirm asks the user a yes-or-no question on their terminal, which need
+// not be our stdin. If there is no terminal to ask on, or a signal arrives on
+// intr before the user has answered, the answer is yes.
+func (c *CLI) confirm(question string, intr <-chan os.Signal) bool {
+	var (
+		err     error
+		tty     *os.File
+		answerQ = make(chan string, 1)
+	)
+
+	if tty, err = os.OpenFile("/dev/tty", os.O_RDWR, 0); err != nil {
+		return true
+	}
+
+	defer tty.Close() // nolint: errcheck
+
+	fmt.Fprintf(tty, "\n%s [Y/n] ", question)
+
+	go func() {
+		var answer, err = bufio.NewReader(tty).ReadString('\n')
+
+		if err != nil && answer == "" {
+			answer = "y"
+		}
+
+		answerQ <- answer
+	}()
+
+	select {
+	case <-intr:
+		fmt.Fprintln(tty)
+		return true
+	case answer := <-answerQ:
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+			return true
+		default:
+			return false
+		}
+	}
+} // func (c *CLI) confirm(question string, intr <-chan os.Signal) bool
+
+func (c *CLI) displayQueue(ctx context.Context) error {
+	var (
+		err  error
+		jobs []job.Job
+	)
+
+	if jobs, err = c.client.Status(ctx); err != nil {
+		return c.report(err)
+	}
+
+	const (
+		jobTmpl = "%6d %4d %6d %3d %-9s %10s %s\n"
+		runTmpl = "%6s %4s %6d %3d %-9s %10s started %s\n"
+	)
+
+	for _, j := range jobs {
+		var (
+			cmd     = strings.Join(j.Cmd, " ")
+			elapsed = j.Runtime().Truncate(time.Second)
+		)
+		fmt.Printf(jobTmpl, j.ID, j.Priority, j.PID, j.ExitCode, j.Status(), elapsed, cmd)
+
+		// For Jobs that were run more than once, we list all attempts.
+		if len(j.Runs) < 2 {
+			continue
+		}
+
+		for _, r := range j.Runs {
+			var (
+				state = "ok"
+				end   = r.TimeEnded
+			)
+
+			if r.Running() {
+				state = "running"
+				end = time.Now()
+			} else if r.TimedOut {
+				state = "timeout"
+			} else if r.ExitCode != 0 {
+				state = "failed"
+			}
+
+			fmt.Printf(runTmpl,
+				"",
+				fmt.Sprintf("#%d", r.Seq),
+				r.PID,
+				r.ExitCode,
+				state,
+				end.Sub(r.TimeStarted).Truncate(time.Second),
+				r.TimeStarted.Format(common.TimestampFormat))
+		}
 	}
 
 	fmt.Println("")
-} // func (c *CLI) displayQueue()
+	return nil
+} // func (c *CLI) displayQueue(ctx context.Context) error
 
 // func (c *CLI) Parse(s string) error {
 // 	var (
@@ -0,0 +1,232 @@
+// /home/krylon/go/src/github.com/blicero/jobq/client/01_client_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 23. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-23 12:58:40 krylon>
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+	"github.com/blicero/jobq/monitor"
+)
+
+var (
+	mon *monitor.Monitor
+	cl  *Client
+)
+
+func TestClientCreate(t *testing.T) {
+	const name = "TestClient"
+
+	var err error
+
+	socketPath = fmt.Sprintf("/tmp/jobq.%s.%s.%d",
+		os.Getenv("USER"),
+		name,
+		os.Getpid())
+
+	if mon, err = monitor.Create(name, socketPath, 2); err != nil {
+		mon = nil
+		t.Fatalf("Cannot create Monitor: %s", err.Error())
+	}
+
+	mon.Start()
+
+	if cl, err = DialPath(socketPath); err != nil {
+		cl = nil
+		t.Fatalf("Cannot connect to Monitor: %s", err.Error())
+	}
+} // func TestClientCreate(t *testing.T)
+
+func TestClientSubmit(t *testing.T) {
+	if cl == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		j      *job.Job
+		id     int64
+		output bytes.Buffer
+		ctx    = context.Background()
+	)
+
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", "echo hello; sleep 0.5; echo world; exit 3"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if id, err = cl.Submit(ctx, j); err != nil {
+		t.Fatalf("Failed to submit Job: %s", err.Error())
+	} else if err = cl.Tail(ctx, id, false, &output); err != nil {
+		t.Fatalf("Cannot tail Job %d: %s", id, err.Error())
+	} else if output.String() != "hello\nworld\n" {
+		t.Errorf("Unexpected output of Job %d: %q", id, output.String())
+	}
+
+	if j, err = cl.Wait(ctx, id); err != nil {
+		t.Fatalf("Failed to wait for Job %d: %s", id, err.Error())
+	} else if j.ID != id || j.Status() != status.Finished || j.ExitCode != 3 {
+		t.Errorf("Unexpected result of Job %d: %s, exit code %d",
+			j.ID,
+			j.Status(),
+			j.ExitCode)
+	}
+
+	var jobs []job.Job
+
+	if jobs, err = cl.Status(ctx); err != nil {
+		t.Errorf("Cannot query queue: %s", err.Error())
+	} else if len(jobs) == 0 {
+		t.Error("Queue should not be empty")
+	}
+} // func TestClientSubmit(t *testing.T)
+
+func TestClientErrors(t *testing.T) {
+	if cl == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err error
+		ctx = context.Background()
+	)
+
+	if err = cl.Cancel(ctx, 999999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancelling an unknown Job should fail with %s, not %v",
+			ErrNotFound,
+			err)
+	}
+
+	if _, err = cl.Get(ctx, 999999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Looking up an unknown Job should fail with %s, not %v",
+			ErrNotFound,
+			err)
+	}
+
+	if _, err = cl.Submit(ctx, &job.Job{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Submitting a Job without a command should fail with %s, not %v",
+			ErrInvalidArgument,
+			err)
+	}
+
+	if err = cl.DeleteSchedule(ctx, 999999); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidState) {
+		t.Errorf("Deleting an unknown Schedule should fail with %s, not %v",
+			ErrNotFound,
+			err)
+	}
+} // func TestClientErrors(t *testing.T)
+
+func TestClientContext(t *testing.T) {
+	if cl == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		id     int64
+		j      *job.Job
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Hour)
+
+	if id, err = cl.Submit(context.Background(), j); err != nil {
+		t.Fatalf("Failed to submit Job: %s", err.Error())
+	}
+
+	defer cl.Cancel(context.Background(), id) // nolint: errcheck
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*300)
+	defer cancel()
+
+	if _, err = cl.Wait(ctx, id); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Waiting for a deferred Job should time out, not %v", err)
+	}
+
+	var jobs []job.Job
+
+	if jobs, err = cl.WaitJobs(context.Background(), []int64{id}, false, time.Millisecond*300); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Waiting for a deferred Job should fail with %s, not %v", ErrTimeout, err)
+	} else if len(jobs) != 1 || jobs[0].Status() != status.Deferred {
+		t.Errorf("WaitJobs should return deferred Job %d", id)
+	}
+
+	// Tail blocks in I/O until the context is cancelled.
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*300, cancel)
+
+	if err = cl.Tail(ctx, id, false, &bytes.Buffer{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Tail should have been cancelled, not %v", err)
+	}
+
+	if _, err = cl.Status(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Request with a cancelled Context should fail, not %v", err)
+	}
+
+	// The Client is still usable.
+	if j, err = cl.Get(context.Background(), id); err != nil {
+		t.Errorf("Cannot look up Job %d: %s", id, err.Error())
+	} else if j.Status() != status.Deferred {
+		t.Errorf("Job %d should be deferred, not %s", id, j.Status())
+	}
+} // func TestClientContext(t *testing.T)
+
+func TestClientReconnect(t *testing.T) {
+	if cl == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err error
+		j   *job.Job
+		ctx = context.Background()
+	)
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	// Pull the rug out from under the Client.
+	cl.lock.Lock()
+	cl.conn.Close() // nolint: errcheck
+	cl.lock.Unlock()
+
+	if _, err = cl.Status(ctx); err != nil {
+		t.Errorf("Client did not reconnect: %s", err.Error())
+	}
+
+	cl.lock.Lock()
+	cl.conn.Close() // nolint: errcheck
+	cl.lock.Unlock()
+
+	if _, err = cl.Submit(ctx, j); err != nil {
+		t.Errorf("Client did not reconnect to submit Job: %s", err.Error())
+	}
+
+	var c *Client
+
+	if c, err = DialPath(socketPath); err != nil {
+		t.Fatalf("Cannot connect to Monitor: %s", err.Error())
+	} else if err = c.Close(); err != nil {
+		t.Errorf("Cannot close Client: %s", err.Error())
+	} else if _, err = c.Status(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Closed Client should fail with %s, not %v", ErrClosed, err)
+	} else if err = c.Tail(ctx, 1, false, &bytes.Buffer{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Tail on a closed Client should fail with %s, not %v", ErrClosed, err)
+	} else if _, err = c.WaitJobs(ctx, []int64{1}, false, 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("WaitJobs on a closed Client should fail with %s, not %v", ErrClosed, err)
+	}
+} // func TestClientReconnect(t *testing.T)
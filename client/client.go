@@ -0,0 +1,523 @@
+// /home/krylon/go/src/github.com/blicero/jobq/client/client.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 23. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-23 11:48:32 krylon>
+
+// Package client provides a client for the Monitor, so other programs can
+// submit and manage Jobs without dealing with the protocol.
+//
+// A Client is safe for concurrent use. If the connection to the Monitor
+// breaks, the Client reconnects on the next request. Errors the Monitor
+// reports are *monitor.Error values, so callers can check for e.g. a Job
+// that does not exist with errors.Is(err, client.ErrNotFound).
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/logdomain"
+	"github.com/blicero/jobq/monitor"
+	"github.com/blicero/jobq/monitor/errcode"
+	"github.com/blicero/jobq/schedule"
+)
+
+// DefaultTimeout is how long a request may take by default, see
+// Client.SetTimeout.
+const DefaultTimeout = time.Second * 30
+
+// These errors match the *monitor.Error the Monitor sends when it cannot
+// carry out a request, use errors.Is to check for them.
+var (
+	ErrNotFound        = &monitor.Error{Code: errcode.NotFound}
+	ErrInvalidArgument = &monitor.Error{Code: errcode.InvalidArgument}
+	ErrInvalidState    = &monitor.Error{Code: errcode.InvalidState}
+	ErrUnknownRequest  = &monitor.Error{Code: errcode.UnknownRequest}
+	ErrInternal        = &monitor.Error{Code: errcode.Internal}
+	ErrTimeout         = &monitor.Error{Code: errcode.Timeout}
+)
+
+// ErrClosed is returned by requests on a Client that has been closed.
+// ErrUnexpectedResponse is returned if the Monitor's Response lacks the data
+// we asked for.
+var (
+	ErrClosed             = errors.New("Client is closed")
+	ErrUnexpectedResponse = errors.New("Unexpected Response from Monitor")
+)
+
+// Client talks to the Monitor of a queue.
+type Client struct {
+	log     *log.Logger
+	path    string
+	lock    sync.Mutex
+	conn    *monitor.Conn
+	timeout time.Duration
+	closed  bool
+}
+
+// Dial connects to the Monitor of the named queue.
+func Dial(queueName string) (*Client, error) {
+	return DialPath(common.SocketPath(queueName))
+} // func Dial(queueName string) (*Client, error)
+
+// DialPath connects to the Monitor listening on the Unix socket at path.
+func DialPath(path string) (*Client, error) {
+	var (
+		err error
+		c   = &Client{
+			path:    path,
+			timeout: DefaultTimeout,
+		}
+	)
+
+	if c.log, err = common.GetLogger(logdomain.Client); err != nil {
+		return nil, err
+	} else if err = c.connect(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+} // func DialPath(path string) (*Client, error)
+
+// SetTimeout sets how long a request may take, unless the Context passed to
+// it expires earlier. Tail and Wait are not subject to the timeout, since
+// they may take as long as the Job runs. A timeout of 0 means requests may
+// take forever.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.lock.Lock()
+	c.timeout = d
+	c.lock.Unlock()
+} // func (c *Client) SetTimeout(d time.Duration)
+
+// Close closes the connection to the Monitor.
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+
+	if c.conn == nil {
+		return nil
+	}
+
+	var err = c.conn.Close()
+	c.conn = nil
+	return err
+} // func (c *Client) Close() error
+
+// connect opens a connection to the Monitor, unless we have one already.
+// The caller must hold the lock.
+func (c *Client) connect() error {
+	var err error
+
+	if c.closed {
+		return ErrClosed
+	} else if c.conn != nil {
+		return nil
+	} else if c.conn, err = monitor.Dial(c.path); err != nil {
+		c.conn = nil
+		c.log.Printf("[ERROR] Cannot connect to Monitor at %s: %s\n",
+			c.path,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (c *Client) connect() error
+
+// disconnect drops a connection we can no longer use. The next request opens
+// a new one. The caller must hold the lock.
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		c.conn.Close() // nolint: errcheck
+		c.conn = nil
+	}
+} // func (c *Client) disconnect()
+
+// watch makes I/O on conn fail once ctx expires or is cancelled. The
+// returned function must be called when the I/O is done.
+func watch(ctx context.Context, conn *monitor.Conn) func() {
+	var (
+		deadline, _ = ctx.Deadline()
+		done        = make(chan struct{})
+		exited      = make(chan struct{})
+	)
+
+	conn.SetDeadline(deadline) // nolint: errcheck
+
+	go func() {
+		defer close(exited)
+
+		select {
+		case <-ctx.Done():
+			// A deadline in the past interrupts any pending I/O.
+			conn.SetDeadline(time.Unix(1, 0)) // nolint: errcheck
+		case <-done:
+		}
+	}()
+
+	// We wait for the goroutine to exit, so it cannot set the deadline
+	// after the caller has moved on to the next request.
+	return func() {
+		close(done)
+		<-exited
+	}
+} // func watch(ctx context.Context, conn *monitor.Conn) func()
+
+// ctxErr returns the Context's error if I/O failed because the Context
+// expired or was cancelled, err otherwise. The deadline watch sets on the
+// connection may pass a moment before the Context notices, so in that case we
+// wait for it.
+func ctxErr(ctx context.Context, err error) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		<-ctx.Done()
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+} // func ctxErr(ctx context.Context, err error) error
+
+// idempotent returns true for requests that can safely be sent again if we
+// do not know whether the Monitor received them.
+func idempotent(args monitor.Args) bool {
+	switch args.(type) {
+	case *monitor.QueueQueryStatusArgs, *monitor.ScheduleListArgs:
+		return true
+	default:
+		return false
+	}
+} // func idempotent(args monitor.Args) bool
+
+// Request sends a request to the Monitor and returns its Response. If the
+// Monitor could not carry out the request, the error is a *monitor.Error.
+// If the connection to the Monitor is broken, Request reconnects and tries
+// again, as long as it is safe to do so.
+//
+// Most callers will want to use one of the more specific methods instead.
+func (c *Client) Request(ctx context.Context, args monitor.Args) (*monitor.Response, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var res, sent, err = c.roundTrip(ctx, args)
+
+	if err != nil && ctx.Err() == nil && (!sent || idempotent(args)) {
+		var e *monitor.Error
+
+		if !errors.As(err, &e) {
+			c.log.Printf("[INFO] Request %s failed, reconnecting: %s\n",
+				args.Request(),
+				err.Error())
+			res, _, err = c.roundTrip(ctx, args)
+		}
+	}
+
+	return res, err
+} // func (c *Client) Request(ctx context.Context, args monitor.Args) (*monitor.Response, error)
+
+// roundTrip sends one request and receives the Response. sent is true if
+// the request may have reached the Monitor. The caller must hold the lock.
+func (c *Client) roundTrip(ctx context.Context, args monitor.Args) (res *monitor.Response, sent bool, err error) {
+	if err = c.connect(); err != nil {
+		return nil, false, err
+	}
+
+	var (
+		msg  = monitor.MakeMsg(args)
+		stop = watch(ctx, c.conn)
+	)
+
+	if err = c.conn.Send(&msg); err == nil {
+		sent = true
+		if res, err = c.conn.Receive(); err == nil && res.ID != msg.ID {
+			err = fmt.Errorf("Response %d does not match request %d",
+				res.ID,
+				msg.ID)
+		}
+	}
+
+	stop()
+
+	if err != nil {
+		// We cannot tell what state the connection is in.
+		c.disconnect()
+		return nil, sent, ctxErr(ctx, err)
+	}
+
+	return res, true, res.Err()
+} // func (c *Client) roundTrip(ctx context.Context, args monitor.Args) (res *monitor.Response, sent bool, err error)
+
+// Submit submits a new Job and returns its ID.
+func (c *Client) Submit(ctx context.Context, j *job.Job) (int64, error) {
+	var res, err = c.Request(ctx, &monitor.JobSubmitArgs{Job: j})
+
+	if err != nil {
+		return 0, err
+	} else if len(res.Jobs) != 1 {
+		return 0, ErrUnexpectedResponse
+	}
+
+	return res.Jobs[0].ID, nil
+} // func (c *Client) Submit(ctx context.Context, j *job.Job) (int64, error)
+
+// Rerun submits a copy of the finished Job with the given ID and returns the
+// ID of the new Job. The fields listed in override are taken from proto
+// instead of the original Job, see job.Job.Override.
+func (c *Client) Rerun(ctx context.Context, id int64, proto *job.Job, override []string) (int64, error) {
+	var res, err = c.Request(ctx, &monitor.JobRerunArgs{
+		JobID:    id,
+		Job:      proto,
+		Override: override,
+	})
+
+	if err != nil {
+		return 0, err
+	} else if len(res.Jobs) != 1 {
+		return 0, ErrUnexpectedResponse
+	}
+
+	return res.Jobs[0].ID, nil
+} // func (c *Client) Rerun(ctx context.Context, id int64, proto *job.Job, override []string) (int64, error)
+
+// Cancel cancels the Job with the given ID. If the Job is running, it is
+// terminated, which may take a moment after Cancel has returned.
+func (c *Client) Cancel(ctx context.Context, id int64) error {
+	var _, err = c.Request(ctx, &monitor.JobCancelArgs{JobID: id})
+	return err
+} // func (c *Client) Cancel(ctx context.Context, id int64) error
+
+// SetPriority changes the priority of a pending Job.
+func (c *Client) SetPriority(ctx context.Context, id int64, prio int) error {
+	var _, err = c.Request(ctx, &monitor.JobSetPriorityArgs{JobID: id, Priority: prio})
+	return err
+} // func (c *Client) SetPriority(ctx context.Context, id int64, prio int) error
+
+// Status returns all Jobs in the queue.
+func (c *Client) Status(ctx context.Context) ([]job.Job, error) {
+	var res, err = c.Request(ctx, &monitor.QueueQueryStatusArgs{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+} // func (c *Client) Status(ctx context.Context) ([]job.Job, error)
+
+// Get returns the Job with the given ID.
+func (c *Client) Get(ctx context.Context, id int64) (*job.Job, error) {
+	var res, err = c.Request(ctx, &monitor.QueueQueryStatusArgs{IDs: []int64{id}})
+
+	if err != nil {
+		return nil, err
+	} else if len(res.Jobs) == 0 {
+		return nil, &monitor.Error{
+			Code:   errcode.NotFound,
+			Status: fmt.Sprintf("Did not find Job %d", id),
+		}
+	}
+
+	return &res.Jobs[0], nil
+} // func (c *Client) Get(ctx context.Context, id int64) (*job.Job, error)
+
+// Wait waits for the Job with the given ID to finish, and returns it.
+func (c *Client) Wait(ctx context.Context, id int64) (*job.Job, error) {
+	var jobs, err = c.WaitJobs(ctx, []int64{id}, false, 0)
+
+	if err != nil {
+		return nil, err
+	} else if len(jobs) != 1 {
+		return nil, ErrUnexpectedResponse
+	}
+
+	return &jobs[0], nil
+} // func (c *Client) Wait(ctx context.Context, id int64) (*job.Job, error)
+
+// WaitJobs waits for the Jobs with the given IDs to finish, or for any one
+// of them, if anyJob is true, and returns them in the same order. If timeout
+// is positive and the Jobs are not done by then, WaitJobs fails with
+// ErrTimeout, but still returns the Jobs as they were at that point.
+// The Monitor answers as soon as the Jobs are done, so there is no polling
+// involved. Like Tail, WaitJobs uses a connection of its own.
+func (c *Client) WaitJobs(ctx context.Context, ids []int64, anyJob bool, timeout time.Duration) ([]job.Job, error) {
+	var (
+		err  error
+		conn *monitor.Conn
+		res  *monitor.Response
+		msg  = monitor.MakeMsg(&monitor.JobWaitArgs{
+			IDs:     ids,
+			Any:     anyJob,
+			Timeout: timeout,
+		})
+	)
+
+	if conn, err = c.dialExtra(); err != nil {
+		return nil, err
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	var stop = watch(ctx, conn)
+	defer stop()
+
+	if res, err = conn.Request(&msg); err != nil {
+		return nil, ctxErr(ctx, err)
+	}
+
+	return res.Jobs, res.Err()
+} // func (c *Client) WaitJobs(ctx context.Context, ids []int64, anyJob bool, timeout time.Duration) ([]job.Job, error)
+
+// dialExtra opens a connection to the Monitor in addition to the Client's
+// own, for requests that may block for a long time.
+func (c *Client) dialExtra() (*monitor.Conn, error) {
+	var (
+		err    error
+		conn   *monitor.Conn
+		closed bool
+	)
+
+	c.lock.Lock()
+	closed = c.closed
+	c.lock.Unlock()
+
+	if closed {
+		return nil, ErrClosed
+	} else if conn, err = monitor.Dial(c.path); err != nil {
+		c.log.Printf("[ERROR] Cannot connect to Monitor at %s: %s\n",
+			c.path,
+			err.Error())
+		return nil, err
+	}
+
+	return conn, nil
+} // func (c *Client) dialExtra() (*monitor.Conn, error)
+
+// Tail copies the output of the Job with the given ID to w - its stdout, or
+// stderr, if stderr is true - until the Job has finished. If the Job has not
+// been started, yet, Tail waits for it.
+// Tail uses a connection of its own, so the Client can be used for other
+// requests in the meantime.
+// If the Job is retried, Tail copies the output of its current attempt only,
+// see TailAttempt.
+func (c *Client) Tail(ctx context.Context, id int64, stderr bool, w io.Writer) error {
+	return c.TailAttempt(ctx, id, 0, stderr, w)
+} // func (c *Client) Tail(ctx context.Context, id int64, stderr bool, w io.Writer) error
+
+// TailAttempt works like Tail, but copies the output of the given attempt of
+// the Job, starting at 1. If the attempt has not been started, yet,
+// TailAttempt waits for it. If the Job has finished without making that
+// attempt, TailAttempt returns ErrInvalidState.
+func (c *Client) TailAttempt(ctx context.Context, id int64, attempt int, stderr bool, w io.Writer) error {
+	var (
+		err  error
+		conn *monitor.Conn
+		res  *monitor.Response
+		msg  = monitor.MakeMsg(&monitor.JobOutputArgs{
+			JobID:   id,
+			Stderr:  stderr,
+			Follow:  true,
+			Attempt: attempt,
+		})
+	)
+
+	if conn, err = c.dialExtra(); err != nil {
+		return err
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	var stop = watch(ctx, conn)
+	defer stop()
+
+	if res, err = conn.Request(&msg); err != nil {
+		goto FAIL
+	}
+
+	for {
+		if err = res.Err(); err != nil {
+			return err
+		} else if _, err = w.Write(res.Output); err != nil || !res.More {
+			return err
+		} else if res, err = conn.Receive(); err != nil {
+			goto FAIL
+		} else if res.ID != msg.ID {
+			return fmt.Errorf("Response %d does not match request %d",
+				res.ID,
+				msg.ID)
+		}
+	}
+
+FAIL:
+	return ctxErr(ctx, err)
+} // func (c *Client) TailAttempt(ctx context.Context, id int64, attempt int, stderr bool, w io.Writer) error
+
+// Clear removes the finished Jobs the Retention policy does not keep, or all
+// of them, if r is nil, and returns the Jobs it removed.
+func (c *Client) Clear(ctx context.Context, r *monitor.Retention) ([]job.Job, error) {
+	var res, err = c.Request(ctx, &monitor.JobClearArgs{Retention: r})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+} // func (c *Client) Clear(ctx context.Context, r *monitor.Retention) ([]job.Job, error)
+
+// Schedules returns all Schedules.
+func (c *Client) Schedules(ctx context.Context) ([]schedule.Schedule, error) {
+	var res, err = c.Request(ctx, &monitor.ScheduleListArgs{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Schedules, nil
+} // func (c *Client) Schedules(ctx context.Context) ([]schedule.Schedule, error)
+
+// CreateSchedule creates a new Schedule and returns it, with its ID and the
+// time of its next run filled in.
+func (c *Client) CreateSchedule(ctx context.Context, s *schedule.Schedule) (*schedule.Schedule, error) {
+	var res, err = c.Request(ctx, &monitor.ScheduleCreateArgs{Schedule: s})
+
+	if err != nil {
+		return nil, err
+	} else if len(res.Schedules) != 1 {
+		return nil, ErrUnexpectedResponse
+	}
+
+	return &res.Schedules[0], nil
+} // func (c *Client) CreateSchedule(ctx context.Context, s *schedule.Schedule) (*schedule.Schedule, error)
+
+// PauseSchedule pauses the Schedule with the given ID.
+func (c *Client) PauseSchedule(ctx context.Context, id int64) error {
+	var _, err = c.Request(ctx, &monitor.SchedulePauseArgs{ScheduleID: id})
+	return err
+} // func (c *Client) PauseSchedule(ctx context.Context, id int64) error
+
+// ResumeSchedule resumes the paused Schedule with the given ID.
+func (c *Client) ResumeSchedule(ctx context.Context, id int64) error {
+	var _, err = c.Request(ctx, &monitor.ScheduleResumeArgs{ScheduleID: id})
+	return err
+} // func (c *Client) ResumeSchedule(ctx context.Context, id int64) error
+
+// DeleteSchedule deletes the Schedule with the given ID.
+func (c *Client) DeleteSchedule(ctx context.Context, id int64) error {
+	var _, err = c.Request(ctx, &monitor.ScheduleDeleteArgs{ScheduleID: id})
+	return err
+} // func (c *Client) DeleteSchedule(ctx context.Context, id int64) error
@@ -35,6 +35,8 @@ import (
 // TimestampFormat is the format string used to render datetime values.
 // HeartBeat is the interval for worker goroutines to wake up and check
 // their status.
+// KillGrace is the time a Job has to terminate after being sent SIGTERM
+// before it is killed with SIGKILL.
 const (
 	Debug                    = true
 	Version                  = "0.0.1"
@@ -46,8 +48,8 @@ const (
 	HeartBeat                = time.Millisecond * 500
 	RCTimeout                = time.Millisecond * 10
 	Interval                 = time.Second * 120
-	NetName                  = "unixpacket"
-	BufferSize               = 65536 // 64 KiB
+	KillGrace                = time.Second * 5
+	NetName                  = "unix"
 )
 
 // LogLevels are the names of the log levels supported by the logger.
@@ -111,6 +113,15 @@ func SetBaseDir(path string) error {
 	return nil
 } // func SetBaseDir(path string)
 
+// SocketPath returns the path of the Unix socket the Monitor for the named
+// queue listens on.
+func SocketPath(queueName string) string {
+	return fmt.Sprintf("/tmp/%s.%s.%s.socket",
+		AppName,
+		os.Getenv("USER"),
+		queueName)
+} // func SocketPath(queueName string) string
+
 // GetLogger Tries to create a named logger instance and return it.
 // If the directory to hold the log file does not exist, try to create it.
 func GetLogger(dom logdomain.ID) (*log.Logger, error) {
@@ -127,20 +138,22 @@ func GetLogger(dom logdomain.ID) (*log.Logger, error) {
 		AppName,
 		dom)
 
-	fmt.Printf("Creating Logger for %s\n", dom)
+	// Diagnostics go to stderr, so they do not get mixed up with the
+	// output of the CLI, such as the IDs of submitted Jobs.
+	fmt.Fprintf(os.Stderr, "Creating Logger for %s\n", dom)
 
 	var logfile *os.File
 	logfile, err = os.OpenFile(LogPath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
 	if err != nil {
 		msg := fmt.Sprintf("Error opening log file: %s\n", err.Error())
-		fmt.Println(msg)
+		fmt.Fprint(os.Stderr, msg)
 		return nil, errors.New(msg)
 	}
 
 	filter := &logutils.LevelFilter{
 		Levels:   LogLevels,
 		MinLevel: MinLogLevel,
-		Writer:   io.MultiWriter(os.Stdout, logfile),
+		Writer:   io.MultiWriter(os.Stderr, logfile),
 	}
 
 	logger := log.New(filter, logName, log.Ldate|log.Ltime|log.Lshortfile)
@@ -7,7 +7,11 @@
 package database
 
 import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/blicero/jobq/common"
 	"github.com/blicero/jobq/job"
@@ -89,7 +93,7 @@ func TestJobGetPending(t *testing.T) {
 		jobs []job.Job
 	)
 
-	if jobs, err = db.JobGetPending(-1); err != nil {
+	if jobs, err = db.JobGetPending(-1, 0); err != nil {
 		t.Fatalf("Failed to get list of pending Jobs: %s",
 			err.Error())
 	} else if len(jobs) != 1 {
@@ -97,3 +101,221 @@ func TestJobGetPending(t *testing.T) {
 			len(jobs))
 	}
 } // func TestJobGetPending(t *testing.T)
+
+func TestJobCancel(t *testing.T) {
+	if db == nil || tj == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		ok   bool
+		j    *job.Job
+		jobs []job.Job
+	)
+
+	if ok, err = db.JobCancel(tj); err != nil {
+		t.Fatalf("Failed to cancel Job %d: %s",
+			tj.ID,
+			err.Error())
+	} else if !ok {
+		t.Fatalf("Job %d was not cancelled", tj.ID)
+	} else if ok, err = db.JobCancel(tj); err != nil {
+		t.Fatalf("Failed to cancel Job %d a second time: %s",
+			tj.ID,
+			err.Error())
+	} else if ok {
+		t.Errorf("Cancelling Job %d twice should not succeed", tj.ID)
+	}
+
+	if jobs, err = db.JobGetPending(-1, 0); err != nil {
+		t.Fatalf("Failed to get list of pending Jobs: %s",
+			err.Error())
+	} else if len(jobs) != 0 {
+		t.Errorf("Unexpected number of Jobs pending: %d (expected 0)",
+			len(jobs))
+	} else if ok, err = db.JobClaim(tj); err != nil {
+		t.Fatalf("Failed to claim Job %d: %s",
+			tj.ID,
+			err.Error())
+	} else if ok {
+		t.Errorf("Claiming cancelled Job %d should not succeed", tj.ID)
+	} else if j, err = db.JobGetByID(tj.ID); err != nil {
+		t.Fatalf("Failed to fetch Job from Database: %s",
+			err.Error())
+	} else if j.TimeCancelled.IsZero() {
+		t.Errorf("Job %d should have a cancellation time", j.ID)
+	}
+} // func TestJobCancel(t *testing.T)
+
+func TestJobOptions(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		j, j2 *job.Job
+		jobs  []job.Job
+		opt   = job.Options{
+			MaxDuration: time.Minute * 90,
+			Directory:   "/srv/build",
+			Compress:    "gzip",
+			Output:      job.OutputMerge | job.OutputTags,
+			Hook:        "notify-send \"Job $JOBQ_ID finished\"",
+			Nice:        10,
+			IOClass:     job.IOClassBestEffort,
+			IOPriority:  7,
+			Limits: job.Limits{
+				AddressSpace: 1 << 32,
+				CPUSeconds:   3600,
+				OpenFiles:    1024,
+				Processes:    256,
+			},
+			Restart: job.RestartRequeue,
+			Retry: job.RetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     time.Second * 30,
+				MaxBackoff:  time.Minute * 10,
+				ExitCodes:   []int{1, 75},
+			},
+		}
+		env = []string{"PATH=/usr/bin:/bin", "MAKEFLAGS=-s"}
+	)
+
+	if j, err = job.New(opt, "make", "-j4"); err != nil {
+		t.Fatalf("Cannot create new Job: %s",
+			err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+	j.Env = env
+
+	if err = db.JobSubmit(j); err != nil {
+		t.Fatalf("Error submitting Job: %s",
+			err.Error())
+	} else if j2, err = db.JobGetByID(j.ID); err != nil {
+		t.Fatalf("Failed to fetch Job from Database: %s",
+			err.Error())
+	} else if j2 == nil {
+		t.Fatalf("Looking for Job #%d should not return nil",
+			j.ID)
+	} else if !reflect.DeepEqual(j2.Options, opt) {
+		t.Errorf("Options of Job %d do not match:\nExpected: %#v\nActual:   %#v",
+			j.ID,
+			opt,
+			j2.Options)
+	} else if !reflect.DeepEqual(j2.Env, env) {
+		t.Errorf("Environment of Job %d does not match:\nExpected: %#v\nActual:   %#v",
+			j.ID,
+			env,
+			j2.Env)
+	}
+
+	if jobs, err = db.JobGetPending(-1, 0); err != nil {
+		t.Fatalf("Failed to get list of pending Jobs: %s",
+			err.Error())
+	} else if len(jobs) != 1 {
+		t.Fatalf("Unexpected number of Jobs pending: %d (expected 1)",
+			len(jobs))
+	} else if !reflect.DeepEqual(jobs[0].Options, opt) {
+		t.Errorf("Options of pending Job %d do not match:\nExpected: %#v\nActual:   %#v",
+			jobs[0].ID,
+			opt,
+			jobs[0].Options)
+	} else if !reflect.DeepEqual(jobs[0].Env, env) {
+		t.Errorf("Environment of pending Job %d does not match:\nExpected: %#v\nActual:   %#v",
+			jobs[0].ID,
+			env,
+			jobs[0].Env)
+	}
+} // func TestJobOptions(t *testing.T)
+
+// TestMigrate creates a database with the schema of the first release and
+// checks that opening it brings the schema up to date.
+func TestMigrate(t *testing.T) {
+	const qOld = `
+CREATE TABLE job (
+    id		INTEGER PRIMARY KEY,
+    submitted	INTEGER NOT NULL,
+    started	INTEGER,
+    ended	INTEGER,
+    exitcode    INTEGER,
+    cmd         TEXT NOT NULL,
+    spoolout    TEXT UNIQUE,
+    spoolerr    TEXT UNIQUE,
+    pid         INTEGER,
+    CHECK (ended IS NULL OR (started IS NOT NULL AND started <= ended)),
+    CHECK (ended IS NULL OR exitcode IS NOT NULL)
+) STRICT
+`
+
+	const qOldFinished = `
+INSERT INTO job (submitted, started, ended, exitcode, cmd, spoolout, spoolerr, pid)
+VALUES (?1, ?1, ?1 + 5, 3, '["/bin/false"]', '/nonexistent/old.out', '/nonexistent/old.err', 4242)
+`
+
+	var (
+		err  error
+		raw  *sql.DB
+		odb  *Database
+		j    *job.Job
+		runs []job.Run
+		path = filepath.Join(common.BaseDir, "old.db")
+	)
+
+	if raw, err = sql.Open("sqlite3", path); err != nil {
+		t.Fatalf("Cannot open database %s: %s",
+			path,
+			err.Error())
+	} else if _, err = raw.Exec(qOld); err != nil {
+		t.Fatalf("Cannot create old schema: %s",
+			err.Error())
+	} else if _, err = raw.Exec(`INSERT INTO job (submitted, cmd) VALUES (?, '["/bin/true"]')`, time.Now().Unix()); err != nil {
+		t.Fatalf("Cannot insert Job: %s",
+			err.Error())
+	} else if _, err = raw.Exec(qOldFinished, time.Now().Unix()); err != nil {
+		t.Fatalf("Cannot insert finished Job: %s",
+			err.Error())
+	} else if err = raw.Close(); err != nil {
+		t.Fatalf("Cannot close database: %s",
+			err.Error())
+	}
+
+	if odb, err = Open(path); err != nil {
+		t.Fatalf("Cannot open old database: %s",
+			err.Error())
+	}
+
+	defer odb.Close() // nolint: errcheck
+
+	if j, err = odb.JobGetByID(1); err != nil {
+		t.Fatalf("Cannot load Job from migrated database: %s",
+			err.Error())
+	} else if j == nil {
+		t.Fatal("Job from old database was not found")
+	} else if !reflect.DeepEqual(j.Options, job.Options{}) {
+		t.Errorf("Job from old database should have default Options, not %#v",
+			j.Options)
+	}
+
+	// The data of Jobs that have run is moved into the run table.
+	if j, err = odb.JobGetByID(2); err != nil {
+		t.Fatalf("Cannot load finished Job from migrated database: %s",
+			err.Error())
+	} else if j == nil {
+		t.Fatal("Finished Job from old database was not found")
+	} else if j.Attempts != 1 || j.ExitCode != 3 || j.PID != 4242 || j.SpoolOut != "/nonexistent/old.out" {
+		t.Errorf("Unexpected data of finished Job from old database: %#v",
+			j)
+	} else if runs, err = odb.RunGetByJob(2); err != nil {
+		t.Fatalf("Cannot load runs of finished Job: %s",
+			err.Error())
+	} else if len(runs) != 1 {
+		t.Fatalf("Expected 1 run of finished Job, got %d",
+			len(runs))
+	} else if r := runs[0]; r.Seq != 1 || r.ExitCode != 3 || r.SpoolErr != "/nonexistent/old.err" || r.TimeEnded.Sub(r.TimeStarted) != 5*time.Second {
+		t.Errorf("Unexpected run of finished Job: %#v",
+			r)
+	}
+} // func TestMigrate(t *testing.T)
@@ -0,0 +1,196 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/02_database_depend_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 20:41:09 krylon>
+
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// submitDepJob submits a Job that depends on the given Jobs.
+func submitDepJob(t *testing.T, deps ...job.Dependency) *job.Job {
+	var (
+		err error
+		j   *job.Job
+	)
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Cannot create new Job: %s",
+			err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+	j.Depends = deps
+
+	if err = db.JobSubmit(j); err != nil {
+		t.Fatalf("Error submitting Job: %s",
+			err.Error())
+	}
+
+	return j
+} // func submitDepJob(t *testing.T, deps ...job.Dependency) *job.Job
+
+// pendingIDs returns the IDs of all Jobs that are ready to be started.
+func pendingIDs(t *testing.T) map[int64]bool {
+	var (
+		err  error
+		jobs []job.Job
+		ids  map[int64]bool
+	)
+
+	if jobs, err = db.JobGetPending(-1, 0); err != nil {
+		t.Fatalf("Failed to get list of pending Jobs: %s",
+			err.Error())
+	}
+
+	ids = make(map[int64]bool, len(jobs))
+	for _, j := range jobs {
+		ids[j.ID] = true
+	}
+
+	return ids
+} // func pendingIDs(t *testing.T) map[int64]bool
+
+func TestJobDependencies(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		ok      bool
+		ids     []int64
+		j       *job.Job
+		pending map[int64]bool
+		a       = submitDepJob(t)
+		b       = submitDepJob(t, job.Dependency{ID: a.ID, Condition: job.AfterOK})
+		c       = submitDepJob(t, job.Dependency{ID: a.ID, Condition: job.AfterNotOK})
+		d       = submitDepJob(t, job.Dependency{ID: a.ID, Condition: job.AfterAny})
+		e       = submitDepJob(t, job.Dependency{ID: b.ID, Condition: job.AfterOK})
+	)
+
+	if j, err = db.JobGetByID(b.ID); err != nil {
+		t.Fatalf("Failed to fetch Job from Database: %s",
+			err.Error())
+	} else if !reflect.DeepEqual(j.Depends, b.Depends) {
+		t.Errorf("Dependencies of Job %d do not match:\nExpected: %#v\nActual:   %#v",
+			b.ID,
+			b.Depends,
+			j.Depends)
+	}
+
+	pending = pendingIDs(t)
+	if !pending[a.ID] {
+		t.Errorf("Job %d has no dependencies, it should be pending", a.ID)
+	}
+	for _, x := range []*job.Job{b, c, d, e} {
+		if pending[x.ID] {
+			t.Errorf("Job %d should wait for its dependencies", x.ID)
+		}
+	}
+
+	// Now a fails.
+	if ok, err = db.JobClaim(a); err != nil {
+		t.Fatalf("Failed to claim Job %d: %s",
+			a.ID,
+			err.Error())
+	} else if !ok {
+		t.Fatalf("Job %d was not claimed", a.ID)
+	}
+
+	a.ExitCode = 1
+
+	if err = db.JobFinish(a); err != nil {
+		t.Fatalf("Failed to finish Job %d: %s",
+			a.ID,
+			err.Error())
+	}
+
+	// Cancelling b makes the dependency of e unsatisfiable, so that
+	// takes two rounds.
+	for _, expect := range [][]int64{{b.ID}, {e.ID}, nil} {
+		if ids, err = db.JobCancelUnsatisfiable(); err != nil {
+			t.Fatalf("Failed to cancel Jobs with unsatisfiable dependencies: %s",
+				err.Error())
+		} else if !reflect.DeepEqual(ids, expect) {
+			t.Errorf("Unexpected Jobs cancelled: %v (expected %v)",
+				ids,
+				expect)
+		}
+	}
+
+	pending = pendingIDs(t)
+	if !pending[c.ID] || !pending[d.ID] {
+		t.Errorf("Jobs %d and %d should be pending now", c.ID, d.ID)
+	} else if pending[b.ID] || pending[e.ID] {
+		t.Errorf("Jobs %d and %d should have been cancelled", b.ID, e.ID)
+	}
+} // func TestJobDependencies(t *testing.T)
+
+func TestJobDependencyInvalid(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		j    *job.Job
+		jobs []job.Job
+		next int64
+	)
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Cannot create new Job: %s",
+			err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+	j.Depends = []job.Dependency{{ID: 1 << 40}}
+
+	if err = db.JobSubmit(j); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Submitting a Job that depends on a non-existent Job should fail with ErrObjectNotFound, not %v",
+			err)
+	} else if j, err = db.JobGetByID(j.ID); err != nil {
+		t.Fatalf("Failed to fetch Job from Database: %s",
+			err.Error())
+	} else if j != nil {
+		t.Errorf("Job %d should have been rolled back", j.ID)
+	}
+
+	// The only way to create a cycle is a Job that depends on itself.
+	// SQLite hands out the next higher ID for new rows, so we can guess
+	// it.
+	if jobs, err = db.JobGetAll(); err != nil {
+		t.Fatalf("Failed to load all Jobs: %s",
+			err.Error())
+	}
+
+	for _, x := range jobs {
+		if x.ID > next {
+			next = x.ID
+		}
+	}
+
+	next++
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Cannot create new Job: %s",
+			err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+	j.Depends = []job.Dependency{{ID: next, Condition: job.AfterAny}}
+
+	if err = db.JobSubmit(j); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Submitting a Job that depends on itself should fail with ErrDependencyCycle, not %v",
+			err)
+	}
+} // func TestJobDependencyInvalid(t *testing.T)
@@ -0,0 +1,142 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/03_database_priority_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 21:18:26 krylon>
+
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// pendingOrder returns the IDs of the given Jobs in the order JobGetPending
+// returns them.
+func pendingOrder(t *testing.T, aging time.Duration, jobs ...*job.Job) []int64 {
+	var (
+		err     error
+		pending []job.Job
+		ids     = make([]int64, 0, len(jobs))
+		wanted  = make(map[int64]bool, len(jobs))
+	)
+
+	for _, j := range jobs {
+		wanted[j.ID] = true
+	}
+
+	if pending, err = db.JobGetPending(-1, aging); err != nil {
+		t.Fatalf("Failed to get list of pending Jobs: %s",
+			err.Error())
+	}
+
+	for _, j := range pending {
+		if wanted[j.ID] {
+			ids = append(ids, j.ID)
+		}
+	}
+
+	return ids
+} // func pendingOrder(t *testing.T, aging time.Duration, jobs ...*job.Job) []int64
+
+func TestJobPriority(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		ok   bool
+		now  = time.Now()
+		jobs = []struct {
+			prio int
+			age  time.Duration
+		}{
+			{-5, time.Hour * 10},
+			{0, time.Hour},
+			{5, 0},
+			{5, time.Minute},
+		}
+		low, mid, high, older *job.Job
+	)
+
+	for idx, x := range jobs {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+			t.Fatalf("Cannot create new Job: %s",
+				err.Error())
+		}
+
+		j.Priority = x.prio
+		j.TimeSubmitted = now.Add(-x.age)
+
+		if err = db.JobSubmit(j); err != nil {
+			t.Fatalf("Error submitting Job: %s",
+				err.Error())
+		}
+
+		switch idx {
+		case 0:
+			low = j
+		case 1:
+			mid = j
+		case 2:
+			high = j
+		case 3:
+			older = j
+		}
+	}
+
+	var order = []struct {
+		aging  time.Duration
+		expect []int64
+	}{
+		{0, []int64{older.ID, high.ID, mid.ID, low.ID}},
+		// With aging, low has waited long enough to catch up with the
+		// high priority Jobs, and it has been waiting longer than any
+		// of them.
+		{time.Hour, []int64{low.ID, older.ID, high.ID, mid.ID}},
+	}
+
+	for _, o := range order {
+		if ids := pendingOrder(t, o.aging, low, mid, high, older); !reflect.DeepEqual(ids, o.expect) {
+			t.Errorf("Unexpected order of pending Jobs with aging %s:\nExpected: %v\nActual:   %v",
+				o.aging,
+				o.expect,
+				ids)
+		}
+	}
+
+	if ok, err = db.JobSetPriority(mid, 10); err != nil {
+		t.Fatalf("Failed to set priority of Job %d: %s",
+			mid.ID,
+			err.Error())
+	} else if !ok {
+		t.Fatalf("Priority of pending Job %d was not changed", mid.ID)
+	} else if ids := pendingOrder(t, 0, low, mid, high, older); ids[0] != mid.ID {
+		t.Errorf("Job %d should come first after raising its priority: %v",
+			mid.ID,
+			ids)
+	}
+
+	for _, j := range []*job.Job{low, mid, high, older} {
+		if _, err = db.JobCancel(j); err != nil {
+			t.Fatalf("Failed to cancel Job %d: %s",
+				j.ID,
+				err.Error())
+		}
+	}
+
+	if ok, err = db.JobSetPriority(mid, 0); err != nil {
+		t.Fatalf("Failed to set priority of Job %d: %s",
+			mid.ID,
+			err.Error())
+	} else if ok {
+		t.Errorf("Changing the priority of cancelled Job %d should not succeed",
+			mid.ID)
+	}
+} // func TestJobPriority(t *testing.T)
@@ -0,0 +1,85 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/04_database_notbefore_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 22:04:51 krylon>
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+func TestJobNotBefore(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err            error
+		next           time.Time
+		j              *job.Job
+		later, overdue *job.Job
+		now            = time.Now().Truncate(time.Second)
+	)
+
+	for _, nb := range []time.Time{now.Add(time.Hour), now.Add(-time.Minute)} {
+		if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+			t.Fatalf("Cannot create new Job: %s",
+				err.Error())
+		}
+
+		j.TimeSubmitted = now
+		j.NotBefore = nb
+
+		if err = db.JobSubmit(j); err != nil {
+			t.Fatalf("Error submitting Job: %s",
+				err.Error())
+		}
+
+		if later == nil {
+			later = j
+		} else {
+			overdue = j
+		}
+	}
+
+	defer func() {
+		db.JobCancel(later)   // nolint: errcheck
+		db.JobCancel(overdue) // nolint: errcheck
+	}()
+
+	var pending = pendingIDs(t)
+
+	if pending[later.ID] {
+		t.Errorf("Job %d should not be pending before %s",
+			later.ID,
+			later.NotBefore)
+	} else if !pending[overdue.ID] {
+		t.Errorf("Job %d should be pending since %s",
+			overdue.ID,
+			overdue.NotBefore)
+	}
+
+	if j, err = db.JobGetByID(later.ID); err != nil {
+		t.Fatalf("Failed to fetch Job from Database: %s",
+			err.Error())
+	} else if !j.NotBefore.Equal(later.NotBefore) {
+		t.Errorf("NotBefore of Job %d does not match: %s (expected %s)",
+			j.ID,
+			j.NotBefore,
+			later.NotBefore)
+	}
+
+	if next, err = db.JobGetNextDeferred(); err != nil {
+		t.Fatalf("Failed to query next deferred Job: %s",
+			err.Error())
+	} else if !next.Equal(later.NotBefore) {
+		t.Errorf("Unexpected time for next deferred Job: %s (expected %s)",
+			next,
+			later.NotBefore)
+	}
+} // func TestJobNotBefore(t *testing.T)
@@ -0,0 +1,96 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/05_database_schedule_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 23:55:12 krylon>
+
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/schedule"
+)
+
+func TestSchedule(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		s    *schedule.Schedule
+		list []schedule.Schedule
+		now  = time.Now().Truncate(time.Second)
+		orig = schedule.Schedule{
+			Spec:     "*/5 * * * *",
+			Cmd:      []string{"/bin/echo", "Hello", "World"},
+			Options:  job.Options{Directory: "/tmp", Nice: 5},
+			Env:      []string{"FOO=bar"},
+			Priority: 3,
+			Missed:   schedule.MissedCatchUp,
+			Created:  now,
+		}
+	)
+
+	if orig.NextRun, err = orig.Next(now); err != nil {
+		t.Fatalf("Cannot compute next run: %s", err.Error())
+	} else if err = db.ScheduleAdd(&orig); err != nil {
+		t.Fatalf("Cannot add Schedule: %s", err.Error())
+	} else if orig.ID == 0 {
+		t.Fatalf("Schedule did not get an ID")
+	}
+
+	if s, err = db.ScheduleGetByID(orig.ID); err != nil {
+		t.Fatalf("Cannot load Schedule %d: %s",
+			orig.ID,
+			err.Error())
+	} else if s == nil {
+		t.Fatalf("Schedule %d was not found", orig.ID)
+	} else if !reflect.DeepEqual(s, &orig) {
+		t.Errorf("Schedule from database does not match:\n%#v\nexpected\n%#v",
+			s,
+			&orig)
+	}
+
+	if list, err = db.ScheduleGetAll(); err != nil {
+		t.Fatalf("Cannot load Schedules: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != orig.ID {
+		t.Errorf("Unexpected list of Schedules: %#v", list)
+	}
+
+	var next = orig.NextRun.Add(time.Minute * 5)
+
+	if err = db.ScheduleSetPaused(s, true, next); err != nil {
+		t.Fatalf("Cannot pause Schedule %d: %s", s.ID, err.Error())
+	} else if s, err = db.ScheduleGetByID(orig.ID); err != nil {
+		t.Fatalf("Cannot load Schedule %d: %s", orig.ID, err.Error())
+	} else if !s.Paused || !s.NextRun.Equal(next) {
+		t.Errorf("Schedule %d should be paused until %s: %#v",
+			s.ID,
+			next,
+			s)
+	}
+
+	if err = db.ScheduleFired(s, orig.NextRun, next); err != nil {
+		t.Fatalf("Cannot update Schedule %d: %s", s.ID, err.Error())
+	} else if s, err = db.ScheduleGetByID(orig.ID); err != nil {
+		t.Fatalf("Cannot load Schedule %d: %s", orig.ID, err.Error())
+	} else if !s.LastRun.Equal(orig.NextRun) {
+		t.Errorf("Unexpected last run of Schedule %d: %s (expected %s)",
+			s.ID,
+			s.LastRun,
+			orig.NextRun)
+	}
+
+	if err = db.ScheduleDelete(s); err != nil {
+		t.Fatalf("Cannot delete Schedule %d: %s", s.ID, err.Error())
+	} else if s, err = db.ScheduleGetByID(orig.ID); err != nil {
+		t.Fatalf("Cannot load Schedule %d: %s", orig.ID, err.Error())
+	} else if s != nil {
+		t.Errorf("Schedule %d should have been deleted", s.ID)
+	}
+} // func TestSchedule(t *testing.T)
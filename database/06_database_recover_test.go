@@ -0,0 +1,117 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/06_database_recover_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 11:40:26 krylon>
+
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// TestJobRecover simulates Jobs left running by a Monitor that died.
+func TestJobRecover(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err           error
+		ok            bool
+		running       []job.Job
+		unfinished    []job.Job
+		requeue, lost *job.Job
+	)
+
+	for _, policy := range []job.RestartPolicy{job.RestartRequeue, job.RestartNever} {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{Restart: policy}, "/bin/true"); err != nil {
+			t.Fatalf("Cannot create new Job: %s", err.Error())
+		}
+
+		j.TimeSubmitted = time.Now()
+
+		if err = db.JobSubmit(j); err != nil {
+			t.Fatalf("Error submitting Job: %s", err.Error())
+		} else if ok, err = db.JobClaim(j); err != nil || !ok {
+			t.Fatalf("Cannot claim Job %d: %v", j.ID, err)
+		}
+
+		j.PID = 99999
+		j.SpoolOut = fmt.Sprintf("/nonexistent/recover.%d.out", j.ID)
+		j.SpoolErr = fmt.Sprintf("/nonexistent/recover.%d.err", j.ID)
+
+		if err = db.JobStart(j); err != nil {
+			t.Fatalf("Cannot start Job %d: %s", j.ID, err.Error())
+		}
+
+		if policy == job.RestartRequeue {
+			requeue = j
+		} else {
+			lost = j
+		}
+	}
+
+	if running, err = db.JobGetRunning(); err != nil {
+		t.Fatalf("Cannot query running Jobs: %s", err.Error())
+	} else if len(running) != 2 {
+		t.Fatalf("Expected 2 running Jobs, got %d", len(running))
+	} else if running[0].ID != requeue.ID || running[0].PID != requeue.PID {
+		t.Errorf("Unexpected running Job: %#v", running[0])
+	}
+
+	if unfinished, err = db.JobGetUnfinished(); err != nil {
+		t.Fatalf("Cannot query unfinished Jobs: %s", err.Error())
+	} else if len(unfinished) < 2 {
+		t.Errorf("Expected at least 2 unfinished Jobs, got %d", len(unfinished))
+	}
+
+	var j *job.Job
+
+	if j, err = db.JobGetByID(requeue.ID); err != nil {
+		t.Fatalf("Cannot load Job %d: %s", requeue.ID, err.Error())
+	} else if j.Restart != job.RestartRequeue || j.PID != requeue.PID {
+		t.Errorf("Unexpected restart policy or PID of Job %d: %s / %d",
+			j.ID,
+			j.Restart,
+			j.PID)
+	}
+
+	if ok, err = db.JobRequeue(requeue); err != nil {
+		t.Fatalf("Cannot requeue Job %d: %s", requeue.ID, err.Error())
+	} else if !ok {
+		t.Errorf("Job %d was not requeued", requeue.ID)
+	} else if !pendingIDs(t)[requeue.ID] {
+		t.Errorf("Job %d should be pending after requeueing it", requeue.ID)
+	}
+
+	defer db.JobCancel(requeue) // nolint: errcheck
+
+	if err = db.JobLost(lost); err != nil {
+		t.Fatalf("Cannot mark Job %d as lost: %s", lost.ID, err.Error())
+	} else if j, err = db.JobGetByID(lost.ID); err != nil {
+		t.Fatalf("Cannot load Job %d: %s", lost.ID, err.Error())
+	} else if s := j.Status(); s != status.Lost {
+		t.Errorf("Unexpected status of Job %d: %s (expected %s)",
+			j.ID,
+			s,
+			status.Lost)
+	} else if ok, err = db.JobRequeue(lost); err != nil {
+		t.Fatalf("Error requeueing Job %d: %s", lost.ID, err.Error())
+	} else if ok {
+		t.Errorf("Job %d has finished, it should not be requeued", lost.ID)
+	}
+
+	if running, err = db.JobGetRunning(); err != nil {
+		t.Fatalf("Cannot query running Jobs: %s", err.Error())
+	} else if len(running) != 0 {
+		t.Errorf("Expected no running Jobs, got %d", len(running))
+	}
+} // func TestJobRecover(t *testing.T)
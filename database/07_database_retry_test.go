@@ -0,0 +1,118 @@
+// /home/krylon/go/src/github.com/blicero/jobq/database/07_database_retry_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:07:33 krylon>
+
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+func TestJobRetry(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		ok   bool
+		j    *job.Job
+		runs []job.Run
+		opt  = job.Options{
+			Compress: "zstd",
+			Retry:    job.RetryPolicy{MaxAttempts: 2},
+		}
+	)
+
+	if j, err = job.New(opt, "/bin/false"); err != nil {
+		t.Fatalf("Cannot create new Job: %s", err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+
+	if err = db.JobSubmit(j); err != nil {
+		t.Fatalf("Error submitting Job: %s", err.Error())
+	}
+
+	// Two attempts, the first one fails, the second one succeeds.
+	for attempt := 1; attempt <= 2; attempt++ {
+		if ok, err = db.JobClaim(j); err != nil || !ok {
+			t.Fatalf("Cannot claim Job %d: %v", j.ID, err)
+		} else if j.Attempts != attempt {
+			t.Errorf("Unexpected number of attempts for Job %d: %d (expected %d)",
+				j.ID,
+				j.Attempts,
+				attempt)
+		}
+
+		j.PID = int64(10000 + attempt)
+		j.SpoolOut = fmt.Sprintf("/nonexistent/retry.%d.%d.out", j.ID, attempt)
+		j.SpoolErr = fmt.Sprintf("/nonexistent/retry.%d.%d.err", j.ID, attempt)
+		j.ExitCode = 2 - attempt
+
+		if err = db.JobStart(j); err != nil {
+			t.Fatalf("Cannot start Job %d: %s", j.ID, err.Error())
+		}
+
+		j.TimeEnded = time.Now()
+
+		if err = db.RunFinish(j); err != nil {
+			t.Fatalf("Cannot record attempt %d of Job %d: %s",
+				attempt,
+				j.ID,
+				err.Error())
+		}
+
+		if attempt == 1 {
+			if ok, err = db.JobRetry(j, time.Now().Add(-time.Second)); err != nil {
+				t.Fatalf("Cannot requeue Job %d: %s", j.ID, err.Error())
+			} else if !ok {
+				t.Fatalf("Job %d was not requeued", j.ID)
+			} else if !pendingIDs(t)[j.ID] {
+				t.Fatalf("Job %d should be pending after requeueing it", j.ID)
+			}
+		} else if err = db.JobFinish(j); err != nil {
+			t.Fatalf("Cannot finish Job %d: %s", j.ID, err.Error())
+		}
+	}
+
+	if j, err = db.JobGetByID(j.ID); err != nil {
+		t.Fatalf("Cannot load Job: %s", err.Error())
+	} else if j.Attempts != 2 || j.ExitCode != 0 {
+		t.Errorf("Unexpected attempts/exit code of Job %d: %d/%d",
+			j.ID,
+			j.Attempts,
+			j.ExitCode)
+	} else if runs, err = db.RunGetByJob(j.ID); err != nil {
+		t.Fatalf("Cannot load runs of Job %d: %s", j.ID, err.Error())
+	} else if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs of Job %d, got %d", j.ID, len(runs))
+	}
+
+	for idx, r := range runs {
+		var expect = job.Run{
+			Seq:      idx + 1,
+			ExitCode: 1 - idx,
+			PID:      int64(10001 + idx),
+			SpoolOut: fmt.Sprintf("/nonexistent/retry.%d.%d.out", j.ID, idx+1),
+			SpoolErr: fmt.Sprintf("/nonexistent/retry.%d.%d.err", j.ID, idx+1),
+			Compress: job.CompressZstd,
+		}
+
+		r.TimeStarted = time.Time{}
+		r.TimeEnded = time.Time{}
+
+		if r != expect {
+			t.Errorf("Unexpected run %d:\nExpected: %#v\nActual:   %#v",
+				idx,
+				expect,
+				r)
+		}
+	}
+} // func TestJobRetry(t *testing.T)
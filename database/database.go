@@ -24,6 +24,7 @@ import (
 	"github.com/blicero/jobq/database/query"
 	"github.com/blicero/jobq/job"
 	"github.com/blicero/jobq/logdomain"
+	"github.com/blicero/jobq/schedule"
 	"github.com/blicero/krylib"
 	_ "github.com/mattn/go-sqlite3" // Import the database driver
 )
@@ -52,6 +53,10 @@ var ErrInvalidValue = errors.New("Invalid value for parameter")
 // ErrObjectNotFound indicates that an Object was not found in the database.
 var ErrObjectNotFound = errors.New("object was not found in database")
 
+// ErrDependencyCycle indicates that a Job could not be submitted because
+// it depends on itself, so it could never be started.
+var ErrDependencyCycle = errors.New("Dependencies form a cycle")
+
 // ErrInvalidSavepoint is returned when a user of the Database uses an unkown
 // (or expired) savepoint name.
 var ErrInvalidSavepoint = errors.New("that save point does not exist")
@@ -139,6 +144,9 @@ func Open(path string) (*Database, error) {
 		}
 		db.log.Printf("[INFO] Database at %s has been initialized\n",
 			path)
+	} else if err = db.migrate(); err != nil {
+		db.db.Close() // nolint: errcheck,gosec
+		return nil, err
 	}
 
 	return db, nil
@@ -175,6 +183,19 @@ func (db *Database) initialize() error {
 		}
 	}
 
+	var vq = fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)
+
+	if _, err = tx.Exec(vq); err != nil {
+		db.log.Printf("[ERROR] Cannot set schema version: %s\n",
+			err.Error())
+		if rbErr := tx.Rollback(); rbErr != nil {
+			db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+				rbErr.Error())
+			return rbErr
+		}
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		db.log.Printf("[CANTHAPPEN] Failed to commit init transaction: %s\n",
 			err.Error())
@@ -184,6 +205,75 @@ func (db *Database) initialize() error {
 	return nil
 } // func (db *Database) initialize() error
 
+// migrate brings the schema of an existing database up to date.
+func (db *Database) migrate() error {
+	var (
+		err     error
+		tx      *sql.Tx
+		version int
+	)
+
+	if err = db.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.log.Printf("[ERROR] Cannot query schema version: %s\n",
+			err.Error())
+		return err
+	} else if version == schemaVersion {
+		return nil
+	} else if version > schemaVersion {
+		db.log.Printf("[ERROR] Database schema version %d is newer than what we support (%d)\n",
+			version,
+			schemaVersion)
+		return fmt.Errorf("Unsupported schema version %d", version)
+	}
+
+	db.log.Printf("[INFO] Upgrade database schema from version %d to %d\n",
+		version,
+		schemaVersion)
+
+	if tx, err = db.db.Begin(); err != nil {
+		db.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	for v := version; v < schemaVersion; v++ {
+		for _, q := range qUpgrade[v] {
+			db.log.Printf("[TRACE] Execute upgrade query:\n%s\n",
+				q)
+			if _, err = tx.Exec(q); err != nil {
+				db.log.Printf("[ERROR] Cannot execute upgrade query: %s\n%s\n",
+					err.Error(),
+					q)
+				if rbErr := tx.Rollback(); rbErr != nil {
+					db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+						rbErr.Error())
+					return rbErr
+				}
+				return err
+			}
+		}
+	}
+
+	var vq = fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)
+
+	if _, err = tx.Exec(vq); err != nil {
+		db.log.Printf("[ERROR] Cannot set schema version: %s\n",
+			err.Error())
+		if rbErr := tx.Rollback(); rbErr != nil {
+			db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+				rbErr.Error())
+			return rbErr
+		}
+		return err
+	} else if err = tx.Commit(); err != nil {
+		db.log.Printf("[CANTHAPPEN] Failed to commit upgrade transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) migrate() error
+
 // Close closes the database.
 // If there is a pending transaction, it is rolled back.
 func (db *Database) Close() error {
@@ -315,14 +405,37 @@ func (db *Database) Commit() error {
 	return nil
 } // func (db *Database) Commit() error
 
-// JobSubmit adds a new Job to the database.
+// JobSubmit adds a new Job to the database, along with its dependencies.
+// If the Job depends on a Job that does not exist, it returns an error
+// wrapping ErrObjectNotFound, if it depends on itself, it returns
+// ErrDependencyCycle.
+// A Job can only depend on Jobs that exist already, and dependencies cannot
+// be added later on, so depending on itself is the only cycle a Job can be
+// part of. It is possible nonetheless, because the ID of the new Job can be
+// guessed.
 func (db *Database) JobSubmit(j *job.Job) error {
 	const qid query.ID = query.JobSubmit
 	var (
-		err  error
-		stmt *sql.Stmt
+		err     error
+		stmt    *sql.Stmt
+		txOwned bool
 	)
 
+	// The Job and its dependencies have to be added atomically. If the
+	// caller has not started a transaction, we do it ourselves.
+	if len(j.Depends) > 0 && db.tx == nil {
+		if err = db.Begin(); err != nil {
+			return err
+		}
+
+		txOwned = true
+		defer func() {
+			if txOwned {
+				db.Rollback() // nolint: errcheck
+			}
+		}()
+	}
+
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
@@ -332,10 +445,77 @@ func (db *Database) JobSubmit(j *job.Job) error {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var rows *sql.Rows
+	var (
+		rows        *sql.Rows
+		env, envset *string
+		codes       *string
+		notbefore   int64
+	)
+
+	if !j.NotBefore.IsZero() {
+		notbefore = j.NotBefore.Unix()
+	}
+
+	if len(j.Retry.ExitCodes) > 0 {
+		var buf []byte
+		if buf, err = json.Marshal(j.Retry.ExitCodes); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize exit codes for retrying Job: %s\n",
+				err.Error())
+			return err
+		}
+		codes = new(string)
+		*codes = string(buf)
+	}
+
+	// A nil environment means the Job inherits the Monitor's
+	// environment, which is not the same as an empty one.
+	if j.Env != nil {
+		var buf []byte
+		if buf, err = json.Marshal(j.Env); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize environment of Job: %s\n",
+				err.Error())
+			return err
+		}
+		env = new(string)
+		*env = string(buf)
+	}
+
+	if len(j.EnvSet) > 0 {
+		var buf []byte
+		if buf, err = json.Marshal(j.EnvSet); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize variables to set for Job: %s\n",
+				err.Error())
+			return err
+		}
+		envset = new(string)
+		*envset = string(buf)
+	}
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(j.TimeSubmitted.Unix(), j.CmdString()); err != nil {
+	if rows, err = stmt.Query(
+		j.TimeSubmitted.Unix(),
+		j.CmdString(),
+		j.Directory,
+		int64(j.MaxDuration),
+		j.Compress,
+		j.Output,
+		j.Hook,
+		j.Nice,
+		j.IOClass,
+		j.IOPriority,
+		j.Limits.AddressSpace,
+		j.Limits.CPUSeconds,
+		j.Limits.OpenFiles,
+		j.Limits.Processes,
+		env,
+		envset,
+		j.Priority,
+		notbefore,
+		j.Restart,
+		j.Retry.MaxAttempts,
+		int64(j.Retry.Backoff),
+		int64(j.Retry.MaxBackoff),
+		codes); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -346,22 +526,42 @@ EXEC_QUERY:
 		return err
 	}
 
-	defer rows.Close() // nolint: errcheck,gosec
-
 	rows.Next()
+	err = rows.Scan(&j.ID)
+	rows.Close() // nolint: errcheck,gosec
 
-	if err = rows.Scan(&j.ID); err != nil {
+	if err != nil {
 		db.log.Printf("[ERROR] Cannot extract Job ID from row: %s\n",
 			err.Error())
 		return err
 	}
 
+	for _, d := range j.Depends {
+		if d.ID == j.ID {
+			db.log.Printf("[ERROR] Job %d depends on itself\n",
+				j.ID)
+			return ErrDependencyCycle
+		} else if err = db.dependencyAdd(j.ID, d); err != nil {
+			return err
+		}
+	}
+
+	if txOwned {
+		if err = db.Commit(); err != nil {
+			db.log.Printf("[ERROR] Cannot commit Job %d: %s\n",
+				j.ID,
+				err.Error())
+			return err
+		}
+		txOwned = false
+	}
+
 	return nil
 } // func (db *Database) JobSubmit(j *job.Job) error
 
-// JobStart marks a Job as having started.
-func (db *Database) JobStart(j *job.Job) error {
-	const qid query.ID = query.JobStart
+// dependencyAdd records that the Job identified by id depends on another Job.
+func (db *Database) dependencyAdd(id int64, d job.Dependency) error {
+	const qid query.ID = query.DependencyAdd
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -376,27 +576,41 @@ func (db *Database) JobStart(j *job.Job) error {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var stamp = time.Now()
+	var (
+		res sql.Result
+		cnt int64
+	)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(stamp.Unix(), j.PID, j.SpoolOut, j.SpoolErr, j.ID); err != nil {
+	if res, err = stmt.Exec(id, d.Condition, d.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to mark Job as started: %s\n",
+		db.log.Printf("[ERROR] Failed to add dependency of Job %d on Job %d: %s\n",
+			id,
+			d.ID,
+			err.Error())
+		return err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows inserted: %s\n",
 			err.Error())
 		return err
+	} else if cnt == 0 {
+		db.log.Printf("[ERROR] Job %d depends on Job %d, which does not exist\n",
+			id,
+			d.ID)
+		return fmt.Errorf("Job %d: %w", d.ID, ErrObjectNotFound)
 	}
 
-	j.TimeStarted = stamp
 	return nil
-} // func (db *Database) JobStart(j *job.Job) error
+} // func (db *Database) dependencyAdd(id int64, d job.Dependency) error
 
-// JobFinish marks a Job as finished.
-func (db *Database) JobFinish(j *job.Job) error {
-	const qid query.ID = query.JobFinish
+// JobGetDependencies returns the list of Jobs the Job identified by id
+// depends on.
+func (db *Database) JobGetDependencies(id int64) ([]job.Dependency, error) {
+	const qid query.ID = query.DependencyGetByJob
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -406,33 +620,51 @@ func (db *Database) JobFinish(j *job.Job) error {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return err
+		return nil, err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var stamp = time.Now()
+	var rows *sql.Rows
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(stamp.Unix(), j.ExitCode, j.ID); err != nil {
+	if rows, err = stmt.Query(id); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to mark Job as finished: %s\n",
+		db.log.Printf("[ERROR] Failed to query dependencies of Job %d: %s\n",
+			id,
 			err.Error())
-		return err
+		return nil, err
 	}
 
-	j.TimeEnded = stamp
-	return nil
-} // func (db *Database) JobFinish(j *job.Job) error
+	defer rows.Close() // nolint: errcheck
+	var deps []job.Dependency
 
-// JobGetByID looks up a Job by its ID. If no Job with the given ID exists, it
-// is not considered an error, in that case (nil, nil) is returned.
-func (db *Database) JobGetByID(id int64) (*job.Job, error) {
-	const qid query.ID = query.JobGetByID
+	for rows.Next() {
+		var d job.Dependency
+
+		if err = rows.Scan(&d.ID, &d.Condition); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		deps = append(deps, d)
+	}
+
+	return deps, nil
+} // func (db *Database) JobGetDependencies(id int64) ([]job.Dependency, error)
+
+// JobClaim attempts to mark a pending Job as started. Since the Monitor may
+// run several Jobs in parallel, this is how we make sure no two workers pick
+// up the same Job.
+// It returns true if the Job was claimed successfully, false if it had been
+// claimed by someone else already.
+func (db *Database) JobClaim(j *job.Job) (bool, error) {
+	const qid query.ID = query.JobClaim
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -442,78 +674,95 @@ func (db *Database) JobGetByID(id int64) (*job.Job, error) {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return false, err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var rows *sql.Rows
+	var (
+		res   sql.Result
+		cnt   int64
+		stamp = time.Now()
+	)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(id); err != nil {
+	if res, err = stmt.Exec(stamp.Unix(), j.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for Job %d: %s\n",
-			id,
+		db.log.Printf("[ERROR] Failed to claim Job %d: %s\n",
+			j.ID,
 			err.Error())
-		return nil, err
+		return false, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows updated: %s\n",
+			err.Error())
+		return false, err
+	} else if cnt == 0 {
+		return false, nil
 	}
 
-	defer rows.Close() // nolint: errcheck
-
-	if rows.Next() {
-		var (
-			submit             int64
-			start, end, exit   *int64
-			cmd                string
-			spoolout, spoolerr *string
-			j                  = &job.Job{ID: id}
-		)
+	j.TimeStarted = stamp
+	j.Attempts++
+	return true, nil
+} // func (db *Database) JobClaim(j *job.Job) (bool, error)
 
-		if err = rows.Scan(&submit, &start, &end, &exit, &cmd, &spoolout, &spoolerr); err != nil {
-			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
-				err.Error())
-			return nil, err
-		}
+// JobStart records that the current attempt of a Job has started, see
+// job.Run. JobClaim must have been called before.
+func (db *Database) JobStart(j *job.Job) error {
+	const qid query.ID = query.JobStart
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
 
-		j.TimeSubmitted = time.Unix(submit, 0)
-		if start != nil {
-			j.TimeStarted = time.Unix(*start, 0)
-		}
-		if end != nil {
-			j.TimeEnded = time.Unix(*end, 0)
-		}
-		if exit != nil {
-			j.ExitCode = int(*exit)
-		}
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
 
-		if spoolout != nil {
-			j.SpoolOut = *spoolout
-		}
+	var (
+		stamp              = time.Now()
+		pid                *int64
+		spoolout, spoolerr *string
+	)
 
-		if spoolerr != nil {
-			j.SpoolErr = *spoolerr
-		}
+	// Jobs that failed to start have no PID and possibly no spool files.
+	if j.PID != 0 {
+		pid = &j.PID
+	}
+	if j.SpoolOut != "" {
+		spoolout = &j.SpoolOut
+	}
+	if j.SpoolErr != "" {
+		spoolerr = &j.SpoolErr
+	}
 
-		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
-				err.Error(),
-				cmd)
-			return nil, err
+EXEC_QUERY:
+	if _, err = stmt.Exec(j.ID, j.Attempts, stamp.Unix(), pid, spoolout, spoolerr, j.Compression()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
 		}
 
-		return j, nil
+		db.log.Printf("[ERROR] Failed to mark Job as started: %s\n",
+			err.Error())
+		return err
 	}
 
-	return nil, nil
-} // func (db *Database) JobGetByID(id int64) (*job.Job, error)
+	j.TimeStarted = stamp
+	return nil
+} // func (db *Database) JobStart(j *job.Job) error
 
-// JobGetPending returns up to <max> Jobs that have been submitted but not yet started.
-func (db *Database) JobGetPending(max int64) ([]job.Job, error) {
-	const qid query.ID = query.JobGetPending
+// JobFinish marks a Job as finished.
+func (db *Database) JobFinish(j *job.Job) error {
+	const qid query.ID = query.JobFinish
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -523,64 +772,35 @@ func (db *Database) JobGetPending(max int64) ([]job.Job, error) {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var rows *sql.Rows
+	var stamp = time.Now()
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(max); err != nil {
+	if _, err = stmt.Exec(stamp.Unix(), j.ExitCode, j.TimedOut, j.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for pending Jobs: %s\n",
+		db.log.Printf("[ERROR] Failed to mark Job as finished: %s\n",
 			err.Error())
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck
-	var jobs = make([]job.Job, 0)
-
-	for rows.Next() {
-		var (
-			submit     int64
-			cmd        string
-			jout, jerr *string
-			j          job.Job
-		)
-
-		if err = rows.Scan(&j.ID, &submit, &cmd, &jout, &jerr); err != nil {
-			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
-				err.Error())
-			return nil, err
-		} else if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot restore Job command arguments from JSON: %s\nRaw: %s\n",
-				err.Error(),
-				cmd)
-			return nil, err
-		}
-
-		if jout != nil {
-			j.SpoolOut = *jout
-		}
-		if jerr != nil {
-			j.SpoolErr = *jerr
-		}
-
-		j.TimeSubmitted = time.Unix(submit, 0)
-		jobs = append(jobs, j)
+		return err
 	}
 
-	return jobs, nil
-} // func (db *Database) JobGetPending(max int64) ([]job.Job, error)
+	j.TimeEnded = stamp
+	return nil
+} // func (db *Database) JobFinish(j *job.Job) error
 
-// JobGetRunning returns the list of Jobs (possibly empty) that are currently being executed.
-func (db *Database) JobGetRunning() ([]job.Job, error) {
-	const qid query.ID = query.JobGetRunning
+// JobCancel marks a Job as cancelled. A Job that has been cancelled before it
+// was started will not be started anymore.
+// It returns false if the Job could not be cancelled because it had finished
+// or been cancelled already.
+func (db *Database) JobCancel(j *job.Job) (bool, error) {
+	const qid query.ID = query.JobCancel
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -590,74 +810,1172 @@ func (db *Database) JobGetRunning() ([]job.Job, error) {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return false, err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var rows *sql.Rows
+	var (
+		res   sql.Result
+		cnt   int64
+		stamp = time.Now()
+	)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
+	if res, err = stmt.Exec(stamp.Unix(), j.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+		db.log.Printf("[ERROR] Failed to mark Job %d as cancelled: %s\n",
+			j.ID,
 			err.Error())
-		return nil, err
+		return false, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows updated: %s\n",
+			err.Error())
+		return false, err
+	} else if cnt == 0 {
+		return false, nil
 	}
 
-	defer rows.Close() // nolint: errcheck
-	var jobs = make([]job.Job, 0)
+	j.TimeCancelled = stamp
+	return true, nil
+} // func (db *Database) JobCancel(j *job.Job) (bool, error)
+
+// JobSetPriority changes the priority of a Job. Only Jobs that are still
+// waiting to be started can be changed.
+// It returns false if the Job has been started or cancelled already.
+func (db *Database) JobSetPriority(j *job.Job, prio int) (bool, error) {
+	const qid query.ID = query.JobSetPriority
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		res sql.Result
+		cnt int64
+	)
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(prio, j.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to set priority of Job %d to %d: %s\n",
+			j.ID,
+			prio,
+			err.Error())
+		return false, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows updated: %s\n",
+			err.Error())
+		return false, err
+	} else if cnt == 0 {
+		return false, nil
+	}
+
+	j.Priority = prio
+	return true, nil
+} // func (db *Database) JobSetPriority(j *job.Job, prio int) (bool, error)
+
+// JobRequeue puts a Job that was running when the Monitor went away back
+// into the queue, so it gets started again.
+// It returns false if the Job has finished or been cancelled in the meantime.
+func (db *Database) JobRequeue(j *job.Job) (bool, error) {
+	const qid query.ID = query.JobRequeue
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		res sql.Result
+		cnt int64
+	)
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(j.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to requeue Job %d: %s\n",
+			j.ID,
+			err.Error())
+		return false, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows updated: %s\n",
+			err.Error())
+		return false, err
+	} else if cnt == 0 {
+		return false, nil
+	}
+
+	j.TimeStarted = time.Time{}
+	j.TimeEnded = time.Time{}
+	j.ExitCode = -1
+	j.TimedOut = false
+	j.PID = 0
+	j.SpoolOut = ""
+	j.SpoolErr = ""
+	return true, nil
+} // func (db *Database) JobRequeue(j *job.Job) (bool, error)
+
+// JobLost marks a Job as finished whose process disappeared while the Monitor
+// was not running. Since we do not know its exit code, it is set to -1.
+func (db *Database) JobLost(j *job.Job) error {
+	const qid query.ID = query.JobLost
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var stamp = time.Now()
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(stamp.Unix(), j.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to mark Job %d as lost: %s\n",
+			j.ID,
+			err.Error())
+		return err
+	}
+
+	j.TimeEnded = stamp
+	j.ExitCode = -1
+	j.Lost = true
+	return nil
+} // func (db *Database) JobLost(j *job.Job) error
+
+// JobRetry puts a Job whose last attempt failed back into the queue, to be
+// started again no earlier than notBefore. The attempt itself should be
+// recorded with RunFinish first.
+// It returns false if the Job has been cancelled in the meantime.
+func (db *Database) JobRetry(j *job.Job, notBefore time.Time) (bool, error) {
+	const qid query.ID = query.JobRetry
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var (
+		res sql.Result
+		cnt int64
+	)
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(notBefore.Unix(), j.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to requeue Job %d for another attempt: %s\n",
+			j.ID,
+			err.Error())
+		return false, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows updated: %s\n",
+			err.Error())
+		return false, err
+	} else if cnt == 0 {
+		return false, nil
+	}
+
+	j.TimeStarted = time.Time{}
+	j.TimeEnded = time.Time{}
+	j.ExitCode = -1
+	j.TimedOut = false
+	j.PID = 0
+	j.SpoolOut = ""
+	j.SpoolErr = ""
+	j.NotBefore = time.Unix(notBefore.Unix(), 0)
+	return true, nil
+} // func (db *Database) JobRetry(j *job.Job, notBefore time.Time) (bool, error)
+
+// JobCancelUnsatisfiable cancels all pending Jobs that depend on a Job that
+// has finished without meeting the condition, e.g. a Job that failed when
+// the dependency's condition is AfterOK.
+// Since cancelling a Job may render the dependencies of other Jobs
+// unsatisfiable, the caller should repeat this until no more Jobs are
+// cancelled.
+// It returns the IDs of the Jobs that were cancelled.
+func (db *Database) JobCancelUnsatisfiable() ([]int64, error) {
+	const qid query.ID = query.JobCancelUnsatisfiable
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(time.Now().Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to cancel Jobs with unsatisfiable dependencies: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err = rows.Scan(&id); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+} // func (db *Database) JobCancelUnsatisfiable() ([]int64, error)
+
+// JobGetByID looks up a Job by its ID. If no Job with the given ID exists, it
+// is not considered an error, in that case (nil, nil) is returned.
+func (db *Database) JobGetByID(id int64) (*job.Job, error) {
+	const qid query.ID = query.JobGetByID
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for Job %d: %s\n",
+			id,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	if rows.Next() {
+		var (
+			submit             int64
+			start, end, exit   *int64
+			cancelled, pid     *int64
+			maxdur, notbefore  int64
+			backoff, maxback   int64
+			cmd                string
+			spoolout, spoolerr *string
+			env, envset, codes *string
+			j                  = &job.Job{ID: id, ExitCode: -1}
+		)
+
+		if err = rows.Scan(
+			&submit,
+			&start,
+			&end,
+			&exit,
+			&cmd,
+			&spoolout,
+			&spoolerr,
+			&cancelled,
+			&j.Directory,
+			&maxdur,
+			&j.Compress,
+			&j.Output,
+			&j.Hook,
+			&j.Nice,
+			&j.TimedOut,
+			&j.IOClass,
+			&j.IOPriority,
+			&j.Limits.AddressSpace,
+			&j.Limits.CPUSeconds,
+			&j.Limits.OpenFiles,
+			&j.Limits.Processes,
+			&env,
+			&envset,
+			&j.Priority,
+			&notbefore,
+			&pid,
+			&j.Restart,
+			&j.Lost,
+			&j.Retry.MaxAttempts,
+			&backoff,
+			&maxback,
+			&codes,
+			&j.Attempts); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		} else if env != nil {
+			if err = json.Unmarshal([]byte(*env), &j.Env); err != nil {
+				db.log.Printf("[ERROR] Cannot parse JSON into Env: %s\nRaw: %s\n",
+					err.Error(),
+					*env)
+				return nil, err
+			}
+		}
+
+		if envset != nil {
+			if err = json.Unmarshal([]byte(*envset), &j.EnvSet); err != nil {
+				db.log.Printf("[ERROR] Cannot parse JSON into EnvSet: %s\nRaw: %s\n",
+					err.Error(),
+					*envset)
+				return nil, err
+			}
+		}
+
+		if codes != nil {
+			if err = json.Unmarshal([]byte(*codes), &j.Retry.ExitCodes); err != nil {
+				db.log.Printf("[ERROR] Cannot parse JSON into ExitCodes: %s\nRaw: %s\n",
+					err.Error(),
+					*codes)
+				return nil, err
+			}
+		}
+
+		j.Retry.Backoff = time.Duration(backoff)
+		j.Retry.MaxBackoff = time.Duration(maxback)
+
+		j.TimeSubmitted = time.Unix(submit, 0)
+		j.MaxDuration = time.Duration(maxdur)
+		if notbefore != 0 {
+			j.NotBefore = time.Unix(notbefore, 0)
+		}
+		if start != nil {
+			j.TimeStarted = time.Unix(*start, 0)
+		}
+		if end != nil {
+			j.TimeEnded = time.Unix(*end, 0)
+		}
+		if exit != nil {
+			j.ExitCode = int(*exit)
+		}
+		if cancelled != nil {
+			j.TimeCancelled = time.Unix(*cancelled, 0)
+		}
+		if pid != nil {
+			j.PID = *pid
+		}
+
+		if spoolout != nil {
+			j.SpoolOut = *spoolout
+		}
+
+		if spoolerr != nil {
+			j.SpoolErr = *spoolerr
+		}
+
+		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		}
+
+		rows.Close() // nolint: errcheck,gosec
+
+		if j.Depends, err = db.JobGetDependencies(id); err != nil {
+			return nil, err
+		}
+
+		return j, nil
+	}
+
+	return nil, nil
+} // func (db *Database) JobGetByID(id int64) (*job.Job, error)
+
+// JobGetPending returns up to <max> Jobs that have been submitted but not yet
+// started, and whose dependencies are satisfied, in the order they should be
+// started.
+// Jobs are ordered by priority first, then by the time they were submitted.
+// If aging is positive, a Job's priority is raised by one for every interval
+// of that length it has been waiting, so Jobs with a low priority are not
+// starved by a steady stream of more urgent ones.
+func (db *Database) JobGetPending(max int64, aging time.Duration) ([]job.Job, error) {
+	const qid query.ID = query.JobGetPending
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(time.Now().Unix(), int64(aging.Seconds()), max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for pending Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var jobs = make([]job.Job, 0)
+
+	for rows.Next() {
+		var (
+			submit, maxdur, notbefore int64
+			backoff, maxback          int64
+			cmd                       string
+			env, envset, codes        *string
+			j                         = job.Job{ExitCode: -1}
+		)
+
+		if err = rows.Scan(
+			&j.ID,
+			&submit,
+			&cmd,
+			&j.Directory,
+			&maxdur,
+			&j.Compress,
+			&j.Output,
+			&j.Hook,
+			&j.Nice,
+			&j.IOClass,
+			&j.IOPriority,
+			&j.Limits.AddressSpace,
+			&j.Limits.CPUSeconds,
+			&j.Limits.OpenFiles,
+			&j.Limits.Processes,
+			&env,
+			&envset,
+			&j.Priority,
+			&notbefore,
+			&j.Restart,
+			&j.Retry.MaxAttempts,
+			&backoff,
+			&maxback,
+			&codes,
+			&j.Attempts); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		} else if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot restore Job command arguments from JSON: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		} else if env != nil {
+			if err = json.Unmarshal([]byte(*env), &j.Env); err != nil {
+				db.log.Printf("[ERROR] Cannot restore Job environment from JSON: %s\nRaw: %s\n",
+					err.Error(),
+					*env)
+				return nil, err
+			}
+		}
+
+		if envset != nil {
+			if err = json.Unmarshal([]byte(*envset), &j.EnvSet); err != nil {
+				db.log.Printf("[ERROR] Cannot restore variables to set for Job from JSON: %s\nRaw: %s\n",
+					err.Error(),
+					*envset)
+				return nil, err
+			}
+		}
+
+		if codes != nil {
+			if err = json.Unmarshal([]byte(*codes), &j.Retry.ExitCodes); err != nil {
+				db.log.Printf("[ERROR] Cannot restore exit codes for retrying Job from JSON: %s\nRaw: %s\n",
+					err.Error(),
+					*codes)
+				return nil, err
+			}
+		}
+
+		j.TimeSubmitted = time.Unix(submit, 0)
+		j.MaxDuration = time.Duration(maxdur)
+		j.Retry.Backoff = time.Duration(backoff)
+		j.Retry.MaxBackoff = time.Duration(maxback)
+		if notbefore != 0 {
+			j.NotBefore = time.Unix(notbefore, 0)
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+} // func (db *Database) JobGetPending(max int64, aging time.Duration) ([]job.Job, error)
+
+// JobGetNextDeferred returns the earliest time in the future at which a
+// pending Job becomes eligible to run. If there is no such Job, it returns
+// the zero time.
+func (db *Database) JobGetNextDeferred() (time.Time, error) {
+	const qid query.ID = query.JobGetNextDeferred
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return time.Time{}, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var next *int64
+
+EXEC_QUERY:
+	if err = stmt.QueryRow(time.Now().Unix()).Scan(&next); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for deferred Jobs: %s\n",
+			err.Error())
+		return time.Time{}, err
+	} else if next == nil {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(*next, 0), nil
+} // func (db *Database) JobGetNextDeferred() (time.Time, error)
+
+// JobGetRunning returns the list of Jobs (possibly empty) that are currently being executed.
+func (db *Database) JobGetRunning() ([]job.Job, error) {
+	const qid query.ID = query.JobGetRunning
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var jobs = make([]job.Job, 0)
+
+	for rows.Next() {
+		var (
+			submit, start int64
+			pid           *int64
+			cmd           string
+			jerr, jout    *string
+			j             = job.Job{ExitCode: -1}
+		)
+
+		if err = rows.Scan(&j.ID, &submit, &start, &cmd, &pid, &jout, &jerr); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		if pid != nil {
+			j.PID = *pid
+		}
+
+		if jout != nil {
+			j.SpoolOut = *jout
+		}
+		if jerr != nil {
+			j.SpoolErr = *jerr
+		}
+
+		j.TimeSubmitted = time.Unix(submit, 0)
+		j.TimeStarted = time.Unix(start, 0)
+
+		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+} // func (db *Database) JobGetRunning() ([]job.Job, error)
+
+// JobGetUnfinished returns a slice of jobs that are currently running or
+// enqueued to be run.
+func (db *Database) JobGetUnfinished() ([]job.Job, error) {
+	const qid query.ID = query.JobGetUnfinished
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for unfinished Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var jobs = make([]job.Job, 0)
+
+	for rows.Next() {
+		var (
+			submit     int64
+			start, pid *int64
+			cmd        string
+			jerr, jout *string
+			j          = job.Job{ExitCode: -1}
+		)
+
+		if err = rows.Scan(&j.ID, &submit, &start, &cmd, &pid, &jout, &jerr); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		if pid != nil {
+			j.PID = *pid
+		}
+
+		if jout != nil {
+			j.SpoolOut = *jout
+		}
+		if jerr != nil {
+			j.SpoolErr = *jerr
+		}
+
+		j.TimeSubmitted = time.Unix(submit, 0)
+		if start != nil {
+			j.TimeStarted = time.Unix(*start, 0)
+		}
+
+		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+} // func (db *Database) JobGetUnfinished() ([]*job.Job, error)
+
+// JobGetFinished returns the <max> most recently finished Jobs.
+// Passing -1 for max means all of them.
+func (db *Database) JobGetFinished(max int64) ([]job.Job, error) {
+	const qid query.ID = query.JobGetFinished
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(max); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var jobs = make([]job.Job, 0)
 
 	for rows.Next() {
 		var (
-			submit, start int64
-			pid           *int64
-			cmd           string
-			jerr, jout    *string
-			j             job.Job
+			submit, maxdur, notbefore       int64
+			start, end, exitcode, cancelled *int64
+			cmd                             string
+			jout, jerr                      *string
+			j                               = job.Job{ExitCode: -1}
+		)
+
+		if err = rows.Scan(
+			&j.ID,
+			&submit,
+			&start,
+			&end,
+			&exitcode,
+			&cmd,
+			&jout,
+			&jerr,
+			&cancelled,
+			&j.Directory,
+			&maxdur,
+			&j.Compress,
+			&j.Output,
+			&j.Hook,
+			&j.Nice,
+			&j.TimedOut,
+			&j.IOClass,
+			&j.IOPriority,
+			&j.Limits.AddressSpace,
+			&j.Limits.CPUSeconds,
+			&j.Limits.OpenFiles,
+			&j.Limits.Processes,
+			&j.Priority,
+			&notbefore,
+			&j.Lost,
+			&j.Attempts); err != nil {
+			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+				err.Error())
+			return nil, err
+		}
+
+		// Jobs that were cancelled before they were started have
+		// neither start nor end time nor an exit code.
+		j.TimeSubmitted = time.Unix(submit, 0)
+		j.MaxDuration = time.Duration(maxdur)
+		if notbefore != 0 {
+			j.NotBefore = time.Unix(notbefore, 0)
+		}
+		if start != nil {
+			j.TimeStarted = time.Unix(*start, 0)
+		}
+		if end != nil {
+			j.TimeEnded = time.Unix(*end, 0)
+		}
+		if exitcode != nil {
+			j.ExitCode = int(*exitcode)
+		}
+		if cancelled != nil {
+			j.TimeCancelled = time.Unix(*cancelled, 0)
+		}
+		if jout != nil {
+			j.SpoolOut = *jout
+		}
+		if jerr != nil {
+			j.SpoolErr = *jerr
+		}
+
+		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+} // func (db *Database) JobGetFinished(max int64) ([]*job.Job, error)
+
+// JobGetAll loads *all* Jobs from the database, regardless of age or status.
+// Beware that this might be a lot.
+func (db *Database) JobGetAll() ([]job.Job, error) {
+	const qid query.ID = query.JobGetAll
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var jobs = make([]job.Job, 0)
+
+	for rows.Next() {
+		var (
+			submit, maxdur, notbefore int64
+			start, end, exitcode      *int64
+			pid, cancelled            *int64
+			cmd                       string
+			jout, jerr                *string
+			j                         = job.Job{ExitCode: -1}
 		)
 
-		if err = rows.Scan(&submit, &start, &cmd, &pid, &jout, &jerr); err != nil {
+		if err = rows.Scan(
+			&j.ID,
+			&submit,
+			&start,
+			&end,
+			&exitcode,
+			&cmd,
+			&jout,
+			&jerr,
+			&pid,
+			&cancelled,
+			&j.Directory,
+			&maxdur,
+			&j.Compress,
+			&j.Output,
+			&j.Hook,
+			&j.Nice,
+			&j.TimedOut,
+			&j.IOClass,
+			&j.IOPriority,
+			&j.Limits.AddressSpace,
+			&j.Limits.CPUSeconds,
+			&j.Limits.OpenFiles,
+			&j.Limits.Processes,
+			&j.Priority,
+			&notbefore,
+			&j.Lost,
+			&j.Attempts); err != nil {
 			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
 				err.Error())
 			return nil, err
 		}
 
-		if pid != nil {
-			j.PID = *pid
-		}
+		// Jobs that are pending or running have no start/end time and
+		// no exit code, yet.
+		j.TimeSubmitted = time.Unix(submit, 0)
+		j.MaxDuration = time.Duration(maxdur)
+		if notbefore != 0 {
+			j.NotBefore = time.Unix(notbefore, 0)
+		}
+		if start != nil {
+			j.TimeStarted = time.Unix(*start, 0)
+		}
+		if end != nil {
+			j.TimeEnded = time.Unix(*end, 0)
+		}
+		if exitcode != nil {
+			j.ExitCode = int(*exitcode)
+		}
+		if cancelled != nil {
+			j.TimeCancelled = time.Unix(*cancelled, 0)
+		}
+		if jout != nil {
+			j.SpoolOut = *jout
+		}
+		if jerr != nil {
+			j.SpoolErr = *jerr
+		}
+		if pid != nil {
+			j.PID = *pid
+		}
+
+		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+				err.Error(),
+				cmd)
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+} // func (db *Database) JobGetAll() ([]job.Job, error)
+
+// JobDelete removes a Job from the database.
+func (db *Database) JobDelete(j *job.Job) error {
+	const qid query.ID = query.JobDelete
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(j.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to delete Job %d from database: %s\n",
+			j.ID,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) JobDelete(j *job.Job) error
+
+// JobCleanFinished removes all finished Jobs from the database.
+func (db *Database) JobCleanFinished() (int64, error) {
+	const qid query.ID = query.JobCleanFinished
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return 0, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var res sql.Result
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to delete finished Jobs from database: %s\n",
+			err.Error())
+		return 0, err
+	}
+
+	var cnt int64
+
+	if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot query number of rows deleted: %s\n",
+			err.Error())
+		return 0, err
+	}
+
+	return cnt, nil
+} // func (db *Database) JobCleanFinished() (int64, error)
+
+// RunFinish records the outcome of the Job's current attempt, see job.Run.
+// It does not mark the Job itself as finished, see JobFinish and JobRetry.
+func (db *Database) RunFinish(j *job.Job) error {
+	const qid query.ID = query.RunFinish
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(
+		j.TimeEnded.Unix(),
+		j.ExitCode,
+		j.TimedOut,
+		j.ID,
+		j.Attempts); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to record the end of attempt %d of Job %d: %s\n",
+			j.Attempts,
+			j.ID,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) RunFinish(j *job.Job) error
+
+// RunGetByJob returns the attempts to run the Job with the given ID, in the
+// order they happened.
+func (db *Database) RunGetByJob(id int64) ([]job.Run, error) {
+	const qid query.ID = query.RunGetByJob
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to query database for runs of Job %d: %s\n",
+			id,
+			err.Error())
+		return nil, err
+	}
 
-		if jout != nil {
-			j.SpoolOut = *jout
-		}
-		if jerr != nil {
-			j.SpoolErr = *jerr
-		}
+	defer rows.Close() // nolint: errcheck
+	var runs = make([]job.Run, 0)
 
-		j.TimeSubmitted = time.Unix(submit, 0)
-		j.TimeStarted = time.Unix(start, 0)
+	for rows.Next() {
+		var r job.Run
 
-		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
-				err.Error(),
-				cmd)
+		if r, err = db.scanRun(rows, nil); err != nil {
 			return nil, err
 		}
 
-		jobs = append(jobs, j)
+		runs = append(runs, r)
 	}
 
-	return jobs, nil
-} // func (db *Database) JobGetRunning() ([]job.Job, error)
+	return runs, nil
+} // func (db *Database) RunGetByJob(id int64) ([]job.Run, error)
 
-// JobGetUnfinished returns a slice of jobs that are currently running or
-// enqueued to be run.
-func (db *Database) JobGetUnfinished() ([]job.Job, error) {
-	const qid query.ID = query.JobGetRunning
+// RunGetAll returns the attempts to run all Jobs in the database, indexed by
+// the Job's ID.
+func (db *Database) RunGetAll() (map[int64][]job.Run, error) {
+	const qid query.ID = query.RunGetAll
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -681,60 +1999,87 @@ EXEC_QUERY:
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+		db.log.Printf("[ERROR] Failed to query database for runs: %s\n",
 			err.Error())
 		return nil, err
 	}
 
 	defer rows.Close() // nolint: errcheck
-	var jobs = make([]job.Job, 0)
+	var runs = make(map[int64][]job.Run)
 
 	for rows.Next() {
 		var (
-			submit, start int64
-			pid           *int64
-			cmd           string
-			jerr, jout    *string
-			j             job.Job
+			id int64
+			r  job.Run
 		)
 
-		if err = rows.Scan(&submit, &start, &cmd, &jout, &jerr, &pid); err != nil {
-			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
-				err.Error())
+		if r, err = db.scanRun(rows, &id); err != nil {
 			return nil, err
 		}
 
-		if pid != nil {
-			j.PID = *pid
-		}
+		runs[id] = append(runs[id], r)
+	}
 
-		if jout != nil {
-			j.SpoolOut = *jout
-		}
-		if jerr != nil {
-			j.SpoolErr = *jerr
+	return runs, nil
+} // func (db *Database) RunGetAll() (map[int64][]job.Run, error)
+
+// scanRun extracts a Run from the current row of a cursor. If id is not nil,
+// the first column is expected to be the ID of the Job and stored in it.
+func (db *Database) scanRun(rows *sql.Rows, id *int64) (job.Run, error) {
+	var (
+		err        error
+		start      int64
+		end        *int64
+		code       *int
+		pid        *int64
+		jout, jerr *string
+		r          = job.Run{ExitCode: -1}
+		dest       = []any{
+			&r.Seq,
+			&start,
+			&end,
+			&code,
+			&r.TimedOut,
+			&pid,
+			&jout,
+			&jerr,
+			&r.Compress,
 		}
+	)
 
-		j.TimeSubmitted = time.Unix(submit, 0)
-		j.TimeStarted = time.Unix(start, 0)
+	if id != nil {
+		dest = append([]any{id}, dest...)
+	}
 
-		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
-				err.Error(),
-				cmd)
-			return nil, err
-		}
+	if err = rows.Scan(dest...); err != nil {
+		db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+			err.Error())
+		return r, err
+	}
 
-		jobs = append(jobs, j)
+	r.TimeStarted = time.Unix(start, 0)
+	if end != nil {
+		r.TimeEnded = time.Unix(*end, 0)
+	}
+	if code != nil {
+		r.ExitCode = *code
+	}
+	if pid != nil {
+		r.PID = *pid
+	}
+	if jout != nil {
+		r.SpoolOut = *jout
+	}
+	if jerr != nil {
+		r.SpoolErr = *jerr
 	}
 
-	return jobs, nil
-} // func (db *Database) JobGetUnfinished() ([]*job.Job, error)
+	return r, nil
+} // func (db *Database) scanRun(rows *sql.Rows, id *int64) (job.Run, error)
 
-// JobGetFinished returns the <max> most recently finished Jobs.
-// Passing -1 for max means all of them.
-func (db *Database) JobGetFinished(max int64) ([]job.Job, error) {
-	const qid query.ID = query.JobGetFinished
+// ScheduleAdd adds a new Schedule to the database.
+func (db *Database) ScheduleAdd(s *schedule.Schedule) error {
+	const qid query.ID = query.ScheduleAdd
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -744,69 +2089,139 @@ func (db *Database) JobGetFinished(max int64) ([]job.Job, error) {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var rows *sql.Rows
+	var (
+		cmd, opt    []byte
+		env, envset *string
+	)
+
+	if cmd, err = json.Marshal(s.Cmd); err != nil {
+		db.log.Printf("[ERROR] Cannot serialize command of Schedule: %s\n",
+			err.Error())
+		return err
+	} else if opt, err = json.Marshal(&s.Options); err != nil {
+		db.log.Printf("[ERROR] Cannot serialize Options of Schedule: %s\n",
+			err.Error())
+		return err
+	} else if s.Env != nil {
+		var buf []byte
+		if buf, err = json.Marshal(s.Env); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize environment of Schedule: %s\n",
+				err.Error())
+			return err
+		}
+		env = new(string)
+		*env = string(buf)
+	}
+
+	if len(s.EnvSet) > 0 {
+		var buf []byte
+		if buf, err = json.Marshal(s.EnvSet); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize variables to set for Schedule: %s\n",
+				err.Error())
+			return err
+		}
+		envset = new(string)
+		*envset = string(buf)
+	}
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(max); err != nil {
+	if err = stmt.QueryRow(
+		s.Created.Unix(),
+		s.Spec,
+		string(cmd),
+		string(opt),
+		env,
+		envset,
+		s.Priority,
+		s.Missed,
+		s.Paused,
+		s.NextRun.Unix()).Scan(&s.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+		db.log.Printf("[ERROR] Failed to add Schedule to database: %s\n",
 			err.Error())
-		return nil, err
+		return err
 	}
 
-	defer rows.Close() // nolint: errcheck
-	var jobs = make([]job.Job, 0)
+	return nil
+} // func (db *Database) ScheduleAdd(s *schedule.Schedule) error
 
-	for rows.Next() {
-		var (
-			submit, start, end, exitcode int64
-			cmd                          string
-			jout, jerr                   *string
-			j                            job.Job
-		)
+// scanSchedule extracts a Schedule from the current row of a cursor.
+func (db *Database) scanSchedule(rows *sql.Rows) (*schedule.Schedule, error) {
+	var (
+		err              error
+		created, nextrun int64
+		lastrun          *int64
+		cmd, opt         string
+		env, envset      *string
+		s                = new(schedule.Schedule)
+	)
 
-		if err = rows.Scan(&j.ID, &submit, &start, &end, &exitcode, &cmd, &jout, &jerr); err != nil {
-			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
-				err.Error())
+	if err = rows.Scan(
+		&s.ID,
+		&created,
+		&s.Spec,
+		&cmd,
+		&opt,
+		&env,
+		&envset,
+		&s.Priority,
+		&s.Missed,
+		&s.Paused,
+		&lastrun,
+		&nextrun); err != nil {
+		db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
+			err.Error())
+		return nil, err
+	} else if err = json.Unmarshal([]byte(cmd), &s.Cmd); err != nil {
+		db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+			err.Error(),
+			cmd)
+		return nil, err
+	} else if err = json.Unmarshal([]byte(opt), &s.Options); err != nil {
+		db.log.Printf("[ERROR] Cannot parse JSON into Options: %s\nRaw: %s\n",
+			err.Error(),
+			opt)
+		return nil, err
+	} else if env != nil {
+		if err = json.Unmarshal([]byte(*env), &s.Env); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into Env: %s\nRaw: %s\n",
+				err.Error(),
+				*env)
 			return nil, err
 		}
+	}
 
-		j.ExitCode = int(exitcode)
-		j.TimeSubmitted = time.Unix(submit, 0)
-		j.TimeStarted = time.Unix(start, 0)
-		if jout != nil {
-			j.SpoolOut = *jout
-		}
-		if jerr != nil {
-			j.SpoolErr = *jerr
-		}
-
-		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
+	if envset != nil {
+		if err = json.Unmarshal([]byte(*envset), &s.EnvSet); err != nil {
+			db.log.Printf("[ERROR] Cannot parse JSON into EnvSet: %s\nRaw: %s\n",
 				err.Error(),
-				cmd)
+				*envset)
 			return nil, err
 		}
+	}
 
-		jobs = append(jobs, j)
+	s.Created = time.Unix(created, 0)
+	s.NextRun = time.Unix(nextrun, 0)
+	if lastrun != nil {
+		s.LastRun = time.Unix(*lastrun, 0)
 	}
 
-	return jobs, nil
-} // func (db *Database) JobGetFinished(max int64) ([]*job.Job, error)
+	return s, nil
+} // func (db *Database) scanSchedule(rows *sql.Rows) (*schedule.Schedule, error)
 
-// JobGetAll loads *all* Jobs from the database, regardless of age or status.
-// Beware that this might be a lot.
-func (db *Database) JobGetAll() ([]job.Job, error) {
-	const qid query.ID = query.JobGetAll
+// ScheduleGetByID looks up a Schedule by its ID. If no Schedule with the
+// given ID exists, it returns (nil, nil).
+func (db *Database) ScheduleGetByID(id int64) (*schedule.Schedule, error) {
+	const qid query.ID = query.ScheduleGetByID
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -824,73 +2239,79 @@ func (db *Database) JobGetAll() ([]job.Job, error) {
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
+	if rows, err = stmt.Query(id); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to query database for running Jobs: %s\n",
+		db.log.Printf("[ERROR] Failed to query database for Schedule %d: %s\n",
+			id,
 			err.Error())
 		return nil, err
 	}
 
 	defer rows.Close() // nolint: errcheck
-	var jobs = make([]job.Job, 0)
 
-	for rows.Next() {
-		var (
-			submit, start, end, exitcode int64
-			pid                          *int64
-			cmd                          string
-			jout, jerr                   *string
-			j                            job.Job
-		)
+	if rows.Next() {
+		return db.scanSchedule(rows)
+	}
 
-		if err = rows.Scan(
-			&j.ID,
-			&submit,
-			&start,
-			&end,
-			&exitcode,
-			&cmd,
-			&jout,
-			&jerr,
-			&pid); err != nil {
-			db.log.Printf("[ERROR] Cannot extract values from cursor: %s\n",
-				err.Error())
-			return nil, err
-		}
+	return nil, nil
+} // func (db *Database) ScheduleGetByID(id int64) (*schedule.Schedule, error)
 
-		j.ExitCode = int(exitcode)
-		j.TimeSubmitted = time.Unix(submit, 0)
-		j.TimeStarted = time.Unix(start, 0)
-		if jout != nil {
-			j.SpoolOut = *jout
-		}
-		if jerr != nil {
-			j.SpoolErr = *jerr
-		}
-		if pid != nil {
-			j.PID = *pid
+// ScheduleGetAll returns all Schedules, including paused ones.
+func (db *Database) ScheduleGetAll() ([]schedule.Schedule, error) {
+	const qid query.ID = query.ScheduleGetAll
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
 		}
 
-		if err = json.Unmarshal([]byte(cmd), &j.Cmd); err != nil {
-			db.log.Printf("[ERROR] Cannot parse JSON into Cmd: %s\nRaw: %s\n",
-				err.Error(),
-				cmd)
+		db.log.Printf("[ERROR] Failed to query database for Schedules: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck
+	var list = make([]schedule.Schedule, 0)
+
+	for rows.Next() {
+		var s *schedule.Schedule
+
+		if s, err = db.scanSchedule(rows); err != nil {
 			return nil, err
 		}
 
-		jobs = append(jobs, j)
+		list = append(list, *s)
 	}
 
-	return jobs, nil
-} // func (db *Database) JobGetAll() ([]job.Job, error)
+	return list, nil
+} // func (db *Database) ScheduleGetAll() ([]schedule.Schedule, error)
 
-// JobDelete removes a Job from the database.
-func (db *Database) JobDelete(j *job.Job) error {
-	const qid query.ID = query.JobDelete
+// ScheduleSetPaused pauses or resumes a Schedule. When a Schedule is
+// resumed, the caller should pass the next time it fires, so the time it was
+// paused does not count as missed.
+func (db *Database) ScheduleSetPaused(s *schedule.Schedule, paused bool, next time.Time) error {
+	const qid query.ID = query.ScheduleSetPaused
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -906,24 +2327,27 @@ func (db *Database) JobDelete(j *job.Job) error {
 	}
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(j.ID); err != nil {
+	if _, err = stmt.Exec(paused, next.Unix(), s.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to delete Job %d from database: %s\n",
-			j.ID,
+		db.log.Printf("[ERROR] Failed to set paused flag of Schedule %d: %s\n",
+			s.ID,
 			err.Error())
 		return err
 	}
 
+	s.Paused = paused
+	s.NextRun = next
 	return nil
-} // func (db *Database) JobDelete(j *job.Job) error
+} // func (db *Database) ScheduleSetPaused(s *schedule.Schedule, paused bool, next time.Time) error
 
-// JobCleanFinished removes all finished Jobs from the database.
-func (db *Database) JobCleanFinished() (int64, error) {
-	const qid query.ID = query.JobCleanFinished
+// ScheduleFired records that a Schedule has fired and when it is going to
+// fire next.
+func (db *Database) ScheduleFired(s *schedule.Schedule, last, next time.Time) error {
+	const qid query.ID = query.ScheduleFired
 	var (
 		err  error
 		stmt *sql.Stmt
@@ -933,32 +2357,68 @@ func (db *Database) JobCleanFinished() (int64, error) {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return 0, err
+		return err
 	} else if db.tx != nil {
 		stmt = db.tx.Stmt(stmt)
 	}
 
-	var res sql.Result
+	// If the Schedule skipped its missed firings, it may not have fired
+	// at all, yet.
+	var lastrun *int64
+
+	if !last.IsZero() {
+		lastrun = new(int64)
+		*lastrun = last.Unix()
+	}
 
 EXEC_QUERY:
-	if res, err = stmt.Exec(); err != nil {
+	if _, err = stmt.Exec(lastrun, next.Unix(), s.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		}
 
-		db.log.Printf("[ERROR] Failed to delete finished Jobs from database: %s\n",
+		db.log.Printf("[ERROR] Failed to update run times of Schedule %d: %s\n",
+			s.ID,
 			err.Error())
-		return 0, err
+		return err
 	}
 
-	var cnt int64
+	s.LastRun = last
+	s.NextRun = next
+	return nil
+} // func (db *Database) ScheduleFired(s *schedule.Schedule, last, next time.Time) error
 
-	if cnt, err = res.RowsAffected(); err != nil {
-		db.log.Printf("[ERROR] Cannot query number of rows deleted: %s\n",
+// ScheduleDelete removes a Schedule from the database. Jobs it has created
+// are not affected.
+func (db *Database) ScheduleDelete(s *schedule.Schedule) error {
+	const qid query.ID = query.ScheduleDelete
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
 			err.Error())
-		return 0, err
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
 	}
 
-	return cnt, nil
-} // func (db *Database) JobCleanFinished() (int64, error)
+EXEC_QUERY:
+	if _, err = stmt.Exec(s.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Failed to delete Schedule %d from database: %s\n",
+			s.ID,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) ScheduleDelete(s *schedule.Schedule) error
@@ -10,90 +10,330 @@ import "github.com/blicero/jobq/database/query"
 
 var qDB = map[query.ID]string{
 	query.JobSubmit: `
-INSERT INTO job (submitted, cmd) VALUES (?, ?) RETURNING id
+INSERT INTO job (
+	submitted,
+	cmd,
+	directory,
+	maxdur,
+	compress,
+	output,
+	hook,
+	nice,
+	ioclass,
+	ioprio,
+	rlimit_as,
+	rlimit_cpu,
+	rlimit_nofile,
+	rlimit_nproc,
+	env,
+	envset,
+	priority,
+	notbefore,
+	restart,
+	retry_max,
+	retry_backoff,
+	retry_maxbackoff,
+	retry_codes)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id
+`,
+	query.JobClaim: "UPDATE job SET started = ?, attempts = attempts + 1 WHERE id = ? AND started IS NULL AND cancelled IS NULL",
+	query.JobStart: `
+INSERT INTO run (job, seq, started, pid, spoolout, spoolerr, compress)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`,
+	query.JobFinish: "UPDATE job SET ended = ?, exitcode = ?, timedout = ? WHERE id = ?",
+	query.JobCancel: "UPDATE job SET cancelled = ? WHERE id = ? AND ended IS NULL AND cancelled IS NULL",
+	query.JobCancelUnsatisfiable: `
+UPDATE job SET cancelled = ?
+WHERE started IS NULL
+  AND cancelled IS NULL
+  AND EXISTS (SELECT 1 FROM dependency_state s WHERE s.job = job.id AND s.state = 2)
+RETURNING id
+`,
+	query.JobSetPriority: "UPDATE job SET priority = ? WHERE id = ? AND started IS NULL AND cancelled IS NULL",
+	query.JobRequeue: `
+UPDATE job SET started = NULL
+WHERE id = ? AND ended IS NULL AND cancelled IS NULL
+`,
+	query.JobLost: "UPDATE job SET ended = ?, exitcode = -1, lost = 1 WHERE id = ? AND ended IS NULL",
+	query.JobRetry: `
+UPDATE job SET
+	started = NULL,
+	exitcode = NULL,
+	timedout = 0,
+	notbefore = ?
+WHERE id = ? AND ended IS NULL AND cancelled IS NULL
 `,
-	query.JobStart:  "UPDATE job SET started = ?, pid = ?, spoolout = ?, spoolerr = ? WHERE id = ?",
-	query.JobFinish: "UPDATE job SET ended = ?, exitcode = ? WHERE id = ?",
 	query.JobGetByID: `
 SELECT
-	submitted,
-	started,
-	ended,
-	exitcode,
-	cmd,
-	spoolout,
-	spoolerr
-FROM job
-WHERE id = ?
+	j.submitted,
+	j.started,
+	j.ended,
+	j.exitcode,
+	j.cmd,
+	r.spoolout,
+	r.spoolerr,
+	j.cancelled,
+	j.directory,
+	j.maxdur,
+	j.compress,
+	j.output,
+	j.hook,
+	j.nice,
+	j.timedout,
+	j.ioclass,
+	j.ioprio,
+	j.rlimit_as,
+	j.rlimit_cpu,
+	j.rlimit_nofile,
+	j.rlimit_nproc,
+	j.env,
+	j.envset,
+	j.priority,
+	j.notbefore,
+	r.pid,
+	j.restart,
+	j.lost,
+	j.retry_max,
+	j.retry_backoff,
+	j.retry_maxbackoff,
+	j.retry_codes,
+	j.attempts
+FROM job j
+LEFT OUTER JOIN run r ON r.job = j.id AND r.seq = j.attempts
+WHERE j.id = ?
 `,
 	query.JobGetPending: `
 SELECT
 	id,
 	submitted,
 	cmd,
-	spoolout,
-	spoolerr
+	directory,
+	maxdur,
+	compress,
+	output,
+	hook,
+	nice,
+	ioclass,
+	ioprio,
+	rlimit_as,
+	rlimit_cpu,
+	rlimit_nofile,
+	rlimit_nproc,
+	env,
+	envset,
+	priority,
+	notbefore,
+	restart,
+	retry_max,
+	retry_backoff,
+	retry_maxbackoff,
+	retry_codes,
+	attempts
 FROM job
 WHERE started IS NULL
-ORDER BY submitted
-LIMIT ?
+  AND cancelled IS NULL
+  AND notbefore <= ?1
+  AND NOT EXISTS (SELECT 1 FROM dependency_state s WHERE s.job = job.id AND s.state <> 1)
+-- ?1 is the current time, ?2 the aging interval in seconds, if it is
+-- positive, a Job's priority rises by one for each interval it has been
+-- waiting.
+ORDER BY priority + IIF(?2 > 0, (?1 - submitted) / ?2, 0) DESC, submitted, id
+LIMIT ?3
+`,
+	query.JobGetNextDeferred: `
+SELECT MIN(notbefore)
+FROM job
+WHERE started IS NULL
+  AND cancelled IS NULL
+  AND notbefore > ?
 `,
 	query.JobGetRunning: `
 SELECT
-	id,
-	submitted,
-        started,
-	cmd,
-        pid,
-	spoolout,
-	spoolerr
-FROM job
-WHERE started IS NOT NULL AND ended IS NULL
-ORDER BY submitted
+	j.id,
+	j.submitted,
+	j.started,
+	j.cmd,
+	r.pid,
+	r.spoolout,
+	r.spoolerr
+FROM job j
+LEFT OUTER JOIN run r ON r.job = j.id AND r.seq = j.attempts
+WHERE j.started IS NOT NULL AND j.ended IS NULL
+ORDER BY j.submitted
 `,
 	query.JobGetUnfinished: `
 SELECT
-	id,
-	submitted,
-	started,
-	cmd,
-        pid,
-	spoolout,
-        spoolerr,
-        pid
-FROM job
-WHERE ended IS NULL
-ORDER BY submitted
+	j.id,
+	j.submitted,
+	j.started,
+	j.cmd,
+	r.pid,
+	r.spoolout,
+	r.spoolerr
+FROM job j
+LEFT OUTER JOIN run r ON r.job = j.id AND r.seq = j.attempts
+WHERE j.ended IS NULL AND (j.started IS NOT NULL OR j.cancelled IS NULL)
+ORDER BY j.submitted
 `,
 	query.JobGetFinished: `
 SELECT
-	id,
-	submitted,
-        started,
-        ended,
-        exitcode,
-	cmd,
-	spoolout,
-	spoolerr
-FROM job
-WHERE ended IS NOT NULL
-ORDER BY ended DESC
+	j.id,
+	j.submitted,
+	j.started,
+	j.ended,
+	j.exitcode,
+	j.cmd,
+	r.spoolout,
+	r.spoolerr,
+	j.cancelled,
+	j.directory,
+	j.maxdur,
+	j.compress,
+	j.output,
+	j.hook,
+	j.nice,
+	j.timedout,
+	j.ioclass,
+	j.ioprio,
+	j.rlimit_as,
+	j.rlimit_cpu,
+	j.rlimit_nofile,
+	j.rlimit_nproc,
+	j.priority,
+	j.notbefore,
+	j.lost,
+	j.attempts
+FROM job j
+LEFT OUTER JOIN run r ON r.job = j.id AND r.seq = j.attempts
+WHERE j.ended IS NOT NULL OR (j.started IS NULL AND j.cancelled IS NOT NULL)
+ORDER BY COALESCE(j.ended, j.cancelled) DESC, j.id DESC
 LIMIT ?
 `,
 	query.JobGetAll: `
 SELECT
-	id,
-	submitted,
+	j.id,
+	j.submitted,
+	j.started,
+	j.ended,
+	j.exitcode,
+	j.cmd,
+	r.spoolout,
+	r.spoolerr,
+	r.pid,
+	j.cancelled,
+	j.directory,
+	j.maxdur,
+	j.compress,
+	j.output,
+	j.hook,
+	j.nice,
+	j.timedout,
+	j.ioclass,
+	j.ioprio,
+	j.rlimit_as,
+	j.rlimit_cpu,
+	j.rlimit_nofile,
+	j.rlimit_nproc,
+	j.priority,
+	j.notbefore,
+	j.lost,
+	j.attempts
+FROM job j
+LEFT OUTER JOIN run r ON r.job = j.id AND r.seq = j.attempts
+ORDER BY j.submitted
+`,
+	query.JobDelete:        "DELETE FROM job WHERE id = ?",
+	query.JobCleanFinished: "DELETE FROM job WHERE ended IS NOT NULL OR (started IS NULL AND cancelled IS NOT NULL)",
+	// We insert the dependency only if the Job we depend on exists, so
+	// we can tell the caller about unknown Job IDs.
+	query.DependencyAdd: "INSERT INTO dependency (job, depends, cond) SELECT ?, id, ? FROM job WHERE id = ?",
+	query.DependencyGetByJob: "SELECT depends, cond FROM dependency WHERE job = ? ORDER BY depends",
+	query.RunFinish: `
+UPDATE run SET ended = ?, exitcode = ?, timedout = ?
+WHERE job = ? AND seq = ? AND ended IS NULL
+`,
+	query.RunGetByJob: `
+SELECT
+	seq,
 	started,
 	ended,
 	exitcode,
-	cmd,
+	timedout,
+	pid,
 	spoolout,
 	spoolerr,
-	pid
-FROM job
-ORDER BY submitted
+	compress
+FROM run
+WHERE job = ?
+ORDER BY seq
 `,
-	query.JobDelete:        "DELETE FROM job WHERE id = ?",
-	query.JobCleanFinished: "DELETE FROM job WHERE ended IS NOT NULL",
+	query.RunGetAll: `
+SELECT
+	job,
+	seq,
+	started,
+	ended,
+	exitcode,
+	timedout,
+	pid,
+	spoolout,
+	spoolerr,
+	compress
+FROM run
+ORDER BY job, seq
+`,
+	query.ScheduleAdd: `
+INSERT INTO schedule (
+	created,
+	spec,
+	cmd,
+	options,
+	env,
+	envset,
+	priority,
+	missed,
+	paused,
+	nextrun)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id
+`,
+	query.ScheduleGetByID: `
+SELECT
+	id,
+	created,
+	spec,
+	cmd,
+	options,
+	env,
+	envset,
+	priority,
+	missed,
+	paused,
+	lastrun,
+	nextrun
+FROM schedule
+WHERE id = ?
+`,
+	query.ScheduleGetAll: `
+SELECT
+	id,
+	created,
+	spec,
+	cmd,
+	options,
+	env,
+	envset,
+	priority,
+	missed,
+	paused,
+	lastrun,
+	nextrun
+FROM schedule
+ORDER BY id
+`,
+	query.ScheduleSetPaused: "UPDATE schedule SET paused = ?, nextrun = ? WHERE id = ?",
+	query.ScheduleFired:     "UPDATE schedule SET lastrun = ?, nextrun = ? WHERE id = ?",
+	query.ScheduleDelete:    "DELETE FROM schedule WHERE id = ?",
 }
@@ -15,13 +15,326 @@ CREATE TABLE job (
     ended	INTEGER,
     exitcode    INTEGER,
     cmd         TEXT NOT NULL,
-    spoolout    TEXT UNIQUE,
-    spoolerr    TEXT UNIQUE,
-    pid         INTEGER,
+    cancelled   INTEGER,
+    directory   TEXT NOT NULL DEFAULT '',
+    maxdur      INTEGER NOT NULL DEFAULT 0, -- nanoseconds
+    compress    TEXT NOT NULL DEFAULT '',
+    output      INTEGER NOT NULL DEFAULT 0,
+    hook        TEXT NOT NULL DEFAULT '',
+    nice        INTEGER NOT NULL DEFAULT 0,
+    timedout    INTEGER NOT NULL DEFAULT 0,
+    ioclass     INTEGER NOT NULL DEFAULT 0,
+    ioprio      INTEGER NOT NULL DEFAULT 0,
+    rlimit_as   INTEGER NOT NULL DEFAULT 0,
+    rlimit_cpu  INTEGER NOT NULL DEFAULT 0,
+    rlimit_nofile INTEGER NOT NULL DEFAULT 0,
+    rlimit_nproc INTEGER NOT NULL DEFAULT 0,
+    env         TEXT,
+    envset      TEXT, -- JSON
+    priority    INTEGER NOT NULL DEFAULT 0,
+    notbefore   INTEGER NOT NULL DEFAULT 0,
+    restart     INTEGER NOT NULL DEFAULT 0,
+    lost        INTEGER NOT NULL DEFAULT 0,
+    retry_max   INTEGER NOT NULL DEFAULT 0,
+    retry_backoff INTEGER NOT NULL DEFAULT 0, -- nanoseconds
+    retry_maxbackoff INTEGER NOT NULL DEFAULT 0, -- nanoseconds
+    retry_codes TEXT, -- JSON
+    attempts    INTEGER NOT NULL DEFAULT 0,
     CHECK (ended IS NULL OR (started IS NOT NULL AND started <= ended)),
     CHECK (ended IS NULL OR exitcode IS NOT NULL)
 ) STRICT
 `,
 	"CREATE INDEX job_submit_idx ON job (submitted)",
 	"CREATE INDEX job_end_null_idx ON job (ended IS NOT NULL)",
+	"CREATE INDEX job_prio_idx ON job (priority DESC, submitted)",
+	`
+CREATE TABLE dependency (
+    job         INTEGER NOT NULL,
+    depends     INTEGER NOT NULL,
+    cond        INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (job, depends),
+    FOREIGN KEY (job) REFERENCES job (id) ON DELETE CASCADE,
+    FOREIGN KEY (depends) REFERENCES job (id) ON DELETE CASCADE,
+    CHECK (cond IN (0, 1, 2))
+) STRICT
+`,
+	"CREATE INDEX dependency_depends_idx ON dependency (depends)",
+	`
+-- state is 0 while the Job we depend on has not finished, 1 if the
+-- dependency is satisfied, 2 if it never will be.
+-- cond is 0 for afterok, 1 for afternotok, 2 for afterany.
+CREATE VIEW dependency_state AS
+SELECT
+    d.job,
+    d.depends,
+    d.cond,
+    CASE
+        WHEN p.ended IS NULL AND (p.started IS NOT NULL OR p.cancelled IS NULL) THEN 0
+        WHEN d.cond = 2 THEN 1
+        WHEN (p.ended IS NOT NULL
+              AND p.exitcode = 0
+              AND p.timedout = 0
+              AND p.cancelled IS NULL) = (d.cond = 0) THEN 1
+        ELSE 2
+    END AS state
+FROM dependency d
+INNER JOIN job p ON d.depends = p.id
+`,
+	`
+CREATE TABLE schedule (
+    id          INTEGER PRIMARY KEY,
+    created     INTEGER NOT NULL,
+    spec        TEXT NOT NULL,
+    cmd         TEXT NOT NULL,
+    options     TEXT NOT NULL, -- JSON
+    env         TEXT,
+    envset      TEXT, -- JSON
+    priority    INTEGER NOT NULL DEFAULT 0,
+    missed      INTEGER NOT NULL DEFAULT 0,
+    paused      INTEGER NOT NULL DEFAULT 0,
+    lastrun     INTEGER,
+    nextrun     INTEGER NOT NULL,
+    CHECK (missed IN (0, 1, 2))
+) STRICT
+`,
+	`
+-- One row for each time a Job has been started. seq is the number of the
+-- attempt, the most recent run of a Job is the one where seq equals
+-- job.attempts.
+CREATE TABLE run (
+    job         INTEGER NOT NULL,
+    seq         INTEGER NOT NULL,
+    started     INTEGER NOT NULL,
+    ended       INTEGER,
+    exitcode    INTEGER,
+    timedout    INTEGER NOT NULL DEFAULT 0,
+    pid         INTEGER,
+    spoolout    TEXT UNIQUE,
+    spoolerr    TEXT UNIQUE,
+    compress    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (job, seq),
+    FOREIGN KEY (job) REFERENCES job (id) ON DELETE CASCADE,
+    CHECK (seq > 0),
+    CHECK (ended IS NULL OR (started <= ended AND exitcode IS NOT NULL))
+) STRICT
+`,
 }
+
+// qUpgrade contains the queries to migrate a database created by an older
+// version of the application. The queries at index i upgrade the schema
+// from version i to version i+1.
+// qInit always creates the most recent version of the schema, so when
+// changing the schema, both need to be updated.
+// Once a migration has been released, it must never change, or databases
+// that were upgraded before would end up with a different schema than those
+// upgraded afterwards. That is why the migrations spell out their DDL instead
+// of taking it from qInit.
+var qUpgrade = [][]string{
+	// 0 -> 1
+	{
+		"ALTER TABLE job ADD COLUMN cancelled INTEGER",
+	},
+	// 1 -> 2
+	{
+		"ALTER TABLE job ADD COLUMN directory TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE job ADD COLUMN maxdur INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN compress TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE job ADD COLUMN nice INTEGER NOT NULL DEFAULT 0",
+	},
+	// 2 -> 3
+	{
+		"ALTER TABLE job ADD COLUMN timedout INTEGER NOT NULL DEFAULT 0",
+	},
+	// 3 -> 4
+	{
+		"ALTER TABLE job ADD COLUMN ioclass INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN ioprio INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN rlimit_as INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN rlimit_cpu INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN rlimit_nofile INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN rlimit_nproc INTEGER NOT NULL DEFAULT 0",
+	},
+	// 4 -> 5
+	{
+		"ALTER TABLE job ADD COLUMN env TEXT",
+	},
+	// 5 -> 6
+	// The dependency table, its index and the dependency_state view.
+	{
+		`
+CREATE TABLE dependency (
+    job         INTEGER NOT NULL,
+    depends     INTEGER NOT NULL,
+    cond        INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (job, depends),
+    FOREIGN KEY (job) REFERENCES job (id) ON DELETE CASCADE,
+    FOREIGN KEY (depends) REFERENCES job (id) ON DELETE CASCADE,
+    CHECK (cond IN (0, 1, 2))
+) STRICT
+`,
+		"CREATE INDEX dependency_depends_idx ON dependency (depends)",
+		`
+-- state is 0 while the Job we depend on has not finished, 1 if the
+-- dependency is satisfied, 2 if it never will be.
+-- cond is 0 for afterok, 1 for afternotok, 2 for afterany.
+CREATE VIEW dependency_state AS
+SELECT
+    d.job,
+    d.depends,
+    d.cond,
+    CASE
+        WHEN p.ended IS NULL AND (p.started IS NOT NULL OR p.cancelled IS NULL) THEN 0
+        WHEN d.cond = 2 THEN 1
+        WHEN (p.ended IS NOT NULL
+              AND p.exitcode = 0
+              AND p.timedout = 0
+              AND p.cancelled IS NULL) = (d.cond = 0) THEN 1
+        ELSE 2
+    END AS state
+FROM dependency d
+INNER JOIN job p ON d.depends = p.id
+`,
+	},
+	// 6 -> 7
+	{
+		"ALTER TABLE job ADD COLUMN priority INTEGER NOT NULL DEFAULT 0",
+		"CREATE INDEX job_prio_idx ON job (priority DESC, submitted)",
+	},
+	// 7 -> 8
+	{
+		"ALTER TABLE job ADD COLUMN notbefore INTEGER NOT NULL DEFAULT 0",
+	},
+	// 8 -> 9
+	// The schedule table.
+	{
+		`
+CREATE TABLE schedule (
+    id          INTEGER PRIMARY KEY,
+    created     INTEGER NOT NULL,
+    spec        TEXT NOT NULL,
+    cmd         TEXT NOT NULL,
+    options     TEXT NOT NULL, -- JSON
+    env         TEXT,
+    priority    INTEGER NOT NULL DEFAULT 0,
+    missed      INTEGER NOT NULL DEFAULT 0,
+    paused      INTEGER NOT NULL DEFAULT 0,
+    lastrun     INTEGER,
+    nextrun     INTEGER NOT NULL,
+    CHECK (missed IN (0, 1, 2))
+) STRICT
+`,
+	},
+	// 9 -> 10
+	{
+		"ALTER TABLE job ADD COLUMN restart INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN lost INTEGER NOT NULL DEFAULT 0",
+	},
+	// 10 -> 11
+	// Retries, and the run table.
+	{
+		"ALTER TABLE job ADD COLUMN retry_max INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN retry_backoff INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN retry_maxbackoff INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE job ADD COLUMN retry_codes TEXT",
+		"ALTER TABLE job ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0",
+		// Jobs that have been started before had one attempt.
+		"UPDATE job SET attempts = 1 WHERE started IS NOT NULL",
+		`
+-- One row for each time a Job has been started. seq is the number of the
+-- attempt, the most recent run of a Job is the one where seq equals
+-- job.attempts.
+CREATE TABLE run (
+    job         INTEGER NOT NULL,
+    seq         INTEGER NOT NULL,
+    started     INTEGER NOT NULL,
+    ended       INTEGER,
+    exitcode    INTEGER,
+    timedout    INTEGER NOT NULL DEFAULT 0,
+    pid         INTEGER,
+    spoolout    TEXT UNIQUE,
+    spoolerr    TEXT UNIQUE,
+    compress    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (job, seq),
+    FOREIGN KEY (job) REFERENCES job (id) ON DELETE CASCADE,
+    CHECK (seq > 0),
+    CHECK (ended IS NULL OR (started <= ended AND exitcode IS NOT NULL))
+) STRICT
+`,
+	},
+	// 11 -> 12
+	// The run table now holds the data of all runs of a Job, including the
+	// current one. We cannot drop job.pid, job.spoolout and job.spoolerr,
+	// because of their UNIQUE constraints, so they remain in old databases,
+	// but they are no longer used.
+	append(qRebuildRun[:len(qRebuildRun):len(qRebuildRun)],
+		`
+INSERT INTO run (job, seq, started, ended, exitcode, timedout, pid, spoolout, spoolerr)
+SELECT id, MAX(attempts, 1), started, ended, exitcode, timedout, pid, spoolout, spoolerr
+FROM job
+WHERE started IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM run r WHERE r.job = job.id AND r.seq = MAX(job.attempts, 1))
+`,
+		"UPDATE job SET attempts = 1 WHERE started IS NOT NULL AND attempts = 0",
+		"UPDATE job SET pid = NULL, spoolout = NULL, spoolerr = NULL",
+	),
+	// 12 -> 13
+	{
+		"ALTER TABLE job ADD COLUMN output INTEGER NOT NULL DEFAULT 0",
+	},
+	// 13 -> 14
+	// run.compress, see qInit. Until now, only gzip was supported.
+	append(qRebuildRun[:len(qRebuildRun):len(qRebuildRun)],
+		`
+UPDATE run SET compress = 'gzip'
+WHERE job IN (SELECT id FROM job WHERE lower(compress) IN ('gzip', 'yes', 'true'))
+`,
+	),
+	// 14 -> 15
+	{
+		"ALTER TABLE job ADD COLUMN hook TEXT NOT NULL DEFAULT ''",
+	},
+	// 15 -> 16
+	// Variables to set in the Monitor's environment for Jobs that inherit
+	// it.
+	{
+		"ALTER TABLE job ADD COLUMN envset TEXT",
+		"ALTER TABLE schedule ADD COLUMN envset TEXT",
+	},
+}
+
+// qRebuildRun replaces the run table with the one of schema version 14,
+// keeping its content. Unlike adding columns, this works no matter which
+// version of the table we start from.
+// Migrations that change the run table later on need a copy of their own.
+var qRebuildRun = []string{
+	`
+-- One row for each time a Job has been started. seq is the number of the
+-- attempt, the most recent run of a Job is the one where seq equals
+-- job.attempts.
+CREATE TABLE run_new (
+    job         INTEGER NOT NULL,
+    seq         INTEGER NOT NULL,
+    started     INTEGER NOT NULL,
+    ended       INTEGER,
+    exitcode    INTEGER,
+    timedout    INTEGER NOT NULL DEFAULT 0,
+    pid         INTEGER,
+    spoolout    TEXT UNIQUE,
+    spoolerr    TEXT UNIQUE,
+    compress    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (job, seq),
+    FOREIGN KEY (job) REFERENCES job (id) ON DELETE CASCADE,
+    CHECK (seq > 0),
+    CHECK (ended IS NULL OR (started <= ended AND exitcode IS NOT NULL))
+) STRICT
+`,
+	`
+INSERT INTO run_new (job, seq, started, ended, exitcode, timedout, pid, spoolout, spoolerr)
+SELECT job, seq, started, ended, exitcode, timedout, pid, spoolout, spoolerr FROM run
+`,
+	"DROP TABLE run",
+	"ALTER TABLE run_new RENAME TO run",
+}
+
+// schemaVersion is the version of the database schema created by qInit.
+var schemaVersion = len(qUpgrade)
@@ -15,14 +15,33 @@ type ID uint8
 
 const (
 	JobSubmit ID = iota
+	JobClaim
 	JobStart
 	JobFinish
+	JobCancel
+	JobCancelUnsatisfiable
+	JobSetPriority
+	JobRequeue
+	JobLost
+	JobRetry
 	JobGetByID
 	JobGetPending
+	JobGetNextDeferred
 	JobGetRunning
 	JobGetUnfinished
 	JobGetFinished
 	JobGetAll
 	JobDelete
 	JobCleanFinished
+	DependencyAdd
+	DependencyGetByJob
+	RunFinish
+	RunGetByJob
+	RunGetAll
+	ScheduleAdd
+	ScheduleGetByID
+	ScheduleGetAll
+	ScheduleSetPaused
+	ScheduleFired
+	ScheduleDelete
 )
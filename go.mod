@@ -10,6 +10,7 @@ require (
 require (
 	github.com/blicero/krylib v0.0.0-20230308180103-2ef208d8985d
 	github.com/davecgh/go-spew v1.1.1
-	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/mattn/go-sqlite3 v1.14.17
 )
+
+require github.com/klauspost/compress v1.16.7
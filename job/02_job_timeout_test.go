@@ -0,0 +1,86 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/02_job_timeout_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 16:20:09 krylon>
+
+package job
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job/status"
+)
+
+func TestJobTimeout(t *testing.T) {
+	type testCase struct {
+		cmd      []string
+		maxdur   time.Duration
+		timedOut bool
+	}
+
+	var testCases = []testCase{
+		{
+			cmd:    []string{"/bin/sleep", "1"},
+			maxdur: time.Second * 5,
+		},
+		{
+			cmd:      []string{"/bin/sleep", "60"},
+			maxdur:   time.Second,
+			timedOut: true,
+		},
+		{
+			// sh ignores SIGTERM, so we have to wait for the
+			// grace period to pass before it gets killed.
+			cmd:      []string{"/bin/sh", "-c", "trap '' TERM; sleep 60"},
+			maxdur:   time.Second,
+			timedOut: true,
+		},
+	}
+
+	for idx, c := range testCases {
+		var (
+			err     error
+			j       *Job
+			outpath = filepath.Join(common.BaseDir, "timeout.out")
+			errpath = filepath.Join(common.BaseDir, "timeout.err")
+		)
+
+		if j, err = New(Options{MaxDuration: c.maxdur}, c.cmd...); err != nil {
+			t.Fatalf("Error creating Job %d: %s",
+				idx,
+				err.Error())
+		}
+
+		j.ID = int64(idx + 1)
+		j.TimeSubmitted = time.Now()
+
+		if err = j.Start(outpath, errpath); err != nil {
+			t.Fatalf("Failed to start Job %d: %s",
+				idx,
+				err.Error())
+		}
+
+		_ = j.Wait()
+
+		if j.TimedOut != c.timedOut {
+			t.Errorf("Job %d: TimedOut is %t, expected %t",
+				idx,
+				j.TimedOut,
+				c.timedOut)
+		} else if c.timedOut && j.Status() != status.TimedOut {
+			t.Errorf("Job %d: unexpected status %s (expected %s)",
+				idx,
+				j.Status(),
+				status.TimedOut)
+		} else if limit := c.maxdur + common.KillGrace + time.Second; j.Runtime() > limit {
+			t.Errorf("Job %d ran for %s, it should have been killed after %s",
+				idx,
+				j.Runtime(),
+				limit)
+		}
+	}
+} // func TestJobTimeout(t *testing.T)
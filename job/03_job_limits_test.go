@@ -0,0 +1,93 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/03_job_limits_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 18:02:55 krylon>
+
+package job
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/blicero/jobq/common"
+)
+
+func TestJobLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Resource limits are only supported on Linux")
+	}
+
+	const expect = "64\n30\n1048576\n5\n"
+
+	var (
+		err     error
+		j       *Job
+		buf     []byte
+		outpath = filepath.Join(common.BaseDir, "limits.out")
+		errpath = filepath.Join(common.BaseDir, "limits.err")
+		opt     = Options{
+			Nice:       5,
+			IOClass:    IOClassIdle,
+			IOPriority: 0,
+			Limits: Limits{
+				AddressSpace: 1 << 30,
+				CPUSeconds:   30,
+				OpenFiles:    64,
+			},
+		}
+	)
+
+	if j, err = New(opt, "/bin/sh", "-c", "ulimit -n; ulimit -t; ulimit -v; nice"); err != nil {
+		t.Fatalf("Error creating Job: %s", err.Error())
+	} else if err = j.Start(outpath, errpath); err != nil {
+		t.Fatalf("Failed to start Job: %s", err.Error())
+	} else if err = j.Wait(); err != nil {
+		buf, _ = os.ReadFile(errpath)
+		t.Fatalf("Job failed: %s\n%s", err.Error(), buf)
+	} else if buf, err = os.ReadFile(outpath); err != nil {
+		t.Fatalf("Cannot read spool file %s: %s",
+			outpath,
+			err.Error())
+	} else if string(buf) != expect {
+		t.Errorf("Unexpected output from Job:\nExpected: %q\nActual:   %q",
+			expect,
+			buf)
+	}
+} // func TestJobLimits(t *testing.T)
+
+func TestJobInvalidOptions(t *testing.T) {
+	var invalid = []Options{
+		{Nice: 20},
+		{IOClass: IOClassIdle + 1},
+		{IOClass: IOClassBestEffort, IOPriority: 8},
+		{Limits: Limits{OpenFiles: -1}},
+	}
+
+	for idx, opt := range invalid {
+		var (
+			err error
+			j   *Job
+		)
+
+		if j, err = New(opt, "/bin/true"); err != nil {
+			t.Fatalf("Error creating Job %d: %s",
+				idx,
+				err.Error())
+		} else if err = j.Start(
+			filepath.Join(common.BaseDir, "invalid.out"),
+			filepath.Join(common.BaseDir, "invalid.err")); err == nil {
+			_ = j.Wait()
+			t.Errorf("Starting Job %d with invalid Options %#v should have failed",
+				idx,
+				opt)
+		} else if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Unexpected error starting Job %d: %s",
+				idx,
+				err.Error())
+		}
+	}
+} // func TestJobInvalidOptions(t *testing.T)
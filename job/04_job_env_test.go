@@ -0,0 +1,168 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/04_job_env_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 19:48:20 krylon>
+
+package job
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/blicero/jobq/common"
+)
+
+func TestEnvFilter(t *testing.T) {
+	type testCase struct {
+		filter EnvFilter
+		expect []string
+		err    error
+	}
+
+	var (
+		environ = []string{
+			"PATH=/usr/bin:/bin",
+			"HOME=/home/jobq",
+			"LC_ALL=C",
+			"LC_TIME=de_DE.UTF-8",
+			"SECRET=hunter2",
+		}
+		testCases = []testCase{
+			{
+				filter: EnvFilter{Mode: EnvInherit},
+				expect: nil,
+			},
+			{
+				// The variables to set are added by the Monitor,
+				// see Inherited.
+				filter: EnvFilter{Mode: EnvInherit, Set: []string{"FOO=bar"}},
+				expect: nil,
+			},
+			{
+				filter: EnvFilter{Mode: EnvFull},
+				expect: environ,
+			},
+			{
+				filter: EnvFilter{
+					Mode: EnvFull,
+					Deny: []string{"SECRET", "LC_*"},
+				},
+				expect: []string{"PATH=/usr/bin:/bin", "HOME=/home/jobq"},
+			},
+			{
+				filter: EnvFilter{
+					Mode:  EnvSelect,
+					Allow: []string{"PATH", "LC_*"},
+				},
+				expect: []string{"PATH=/usr/bin:/bin", "LC_ALL=C", "LC_TIME=de_DE.UTF-8"},
+			},
+			{
+				filter: EnvFilter{
+					Mode: EnvClean,
+					Set:  []string{"FOO=bar", "EMPTY="},
+				},
+				expect: []string{"FOO=bar", "EMPTY="},
+			},
+			{
+				filter: EnvFilter{
+					Mode: EnvFull,
+					Deny: []string{"*"},
+					Set:  []string{"HOME=/tmp"},
+				},
+				expect: []string{"HOME=/tmp"},
+			},
+			{
+				filter: EnvFilter{
+					Mode:  EnvSelect,
+					Allow: []string{"HOME"},
+					Set:   []string{"HOME=/tmp"},
+				},
+				expect: []string{"HOME=/tmp"},
+			},
+			{
+				filter: EnvFilter{
+					Mode: EnvClean,
+					Set:  []string{"NOVALUE"},
+				},
+				err: ErrInvalidEnv,
+			},
+		}
+	)
+
+	for idx, c := range testCases {
+		var (
+			err error
+			env []string
+		)
+
+		if env, err = c.filter.Apply(environ); err != nil {
+			if c.err == nil || !errors.Is(err, c.err) {
+				t.Errorf("Test case %d: unexpected error: %s",
+					idx,
+					err.Error())
+			}
+		} else if c.err != nil {
+			t.Errorf("Test case %d: expected error %q",
+				idx,
+				c.err.Error())
+		} else if !reflect.DeepEqual(env, c.expect) {
+			t.Errorf("Test case %d: unexpected environment:\nExpected: %#v\nActual:   %#v",
+				idx,
+				c.expect,
+				env)
+		}
+	}
+
+	var (
+		set     = []string{"FOO=bar"}
+		inherit = EnvFilter{Mode: EnvInherit, Set: set}
+		full    = EnvFilter{Mode: EnvFull, Set: set}
+	)
+
+	if env := inherit.Inherited(); !reflect.DeepEqual(env, set) {
+		t.Errorf("EnvInherit should pass on the variables to set, not %#v", env)
+	} else if env = full.Inherited(); env != nil {
+		t.Errorf("EnvFull should not pass on variables separately: %#v", env)
+	}
+} // func TestEnvFilter(t *testing.T)
+
+func TestJobEnv(t *testing.T) {
+	const expect = "bar /bin\n"
+
+	var (
+		err     error
+		j       *Job
+		buf     []byte
+		outpath = filepath.Join(common.BaseDir, "env.out")
+		errpath = filepath.Join(common.BaseDir, "env.err")
+		filter  = EnvFilter{
+			Mode: EnvClean,
+			Set:  []string{"FOO=bar", "PATH=/bin"},
+		}
+	)
+
+	// Without the PATH from the Job's environment, sh would not be
+	// found, since it is not an absolute path.
+	if j, err = New(Options{}, "sh", "-c", "echo $FOO $PATH"); err != nil {
+		t.Fatalf("Error creating Job: %s", err.Error())
+	} else if j.Env, err = filter.Apply(os.Environ()); err != nil {
+		t.Fatalf("Cannot prepare environment: %s", err.Error())
+	} else if err = j.Start(outpath, errpath); err != nil {
+		t.Fatalf("Failed to start Job: %s", err.Error())
+	} else if err = j.Wait(); err != nil {
+		buf, _ = os.ReadFile(errpath)
+		t.Fatalf("Job failed: %s\n%s", err.Error(), buf)
+	} else if buf, err = os.ReadFile(outpath); err != nil {
+		t.Fatalf("Cannot read spool file %s: %s",
+			outpath,
+			err.Error())
+	} else if string(buf) != expect {
+		t.Errorf("Unexpected output from Job:\nExpected: %q\nActual:   %q",
+			expect,
+			buf)
+	}
+} // func TestJobEnv(t *testing.T)
@@ -0,0 +1,99 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/05_job_depend_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 20:47:52 krylon>
+
+package job
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDependency(t *testing.T) {
+	type testCase struct {
+		str    string
+		expect []Dependency
+		err    bool
+	}
+
+	var testCases = []testCase{
+		{
+			str:    "afterok:12",
+			expect: []Dependency{{ID: 12, Condition: AfterOK}},
+		},
+		{
+			str: "AfterNotOK:12:13",
+			expect: []Dependency{
+				{ID: 12, Condition: AfterNotOK},
+				{ID: 13, Condition: AfterNotOK},
+			},
+		},
+		{
+			str:    "afterany:1",
+			expect: []Dependency{{ID: 1, Condition: AfterAny}},
+		},
+		{
+			str: "afterok",
+			err: true,
+		},
+		{
+			str: "whenever:12",
+			err: true,
+		},
+		{
+			str: "afterok:twelve",
+			err: true,
+		},
+	}
+
+	for _, c := range testCases {
+		var (
+			err  error
+			deps []Dependency
+		)
+
+		if deps, err = ParseDependency(c.str); err != nil {
+			if !c.err {
+				t.Errorf("Error parsing %q: %s",
+					c.str,
+					err.Error())
+			} else if !errors.Is(err, ErrInvalidDependency) {
+				t.Errorf("Error parsing %q should wrap ErrInvalidDependency: %s",
+					c.str,
+					err.Error())
+			}
+		} else if c.err {
+			t.Errorf("Parsing %q should have failed", c.str)
+		} else if !reflect.DeepEqual(deps, c.expect) {
+			t.Errorf("Unexpected result parsing %q:\nExpected: %#v\nActual:   %#v",
+				c.str,
+				c.expect,
+				deps)
+		}
+	}
+} // func TestParseDependency(t *testing.T)
+
+func TestValidateDepends(t *testing.T) {
+	var invalid = [][]Dependency{
+		{{ID: 0}},
+		{{ID: 1, Condition: AfterAny + 1}},
+		{{ID: 1}, {ID: 2}, {ID: 1, Condition: AfterAny}},
+	}
+
+	for _, deps := range invalid {
+		var j = &Job{Depends: deps}
+
+		if err := j.ValidateDepends(); err == nil {
+			t.Errorf("Dependencies should be invalid: %#v", deps)
+		}
+	}
+
+	var j = &Job{Depends: []Dependency{{ID: 1}, {ID: 2, Condition: AfterAny}}}
+
+	if err := j.ValidateDepends(); err != nil {
+		t.Errorf("Dependencies should be valid: %s", err.Error())
+	}
+} // func TestValidateDepends(t *testing.T)
@@ -0,0 +1,87 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/06_job_notbefore_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 21:58:02 krylon>
+
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job/status"
+)
+
+func TestParseNotBefore(t *testing.T) {
+	type testCase struct {
+		str    string
+		expect time.Time
+		err    bool
+	}
+
+	var (
+		loc       = time.FixedZone("CEST", 2*3600)
+		now       = time.Date(2026, 10, 18, 21, 30, 0, 0, loc)
+		testCases = []testCase{
+			{str: "+2h", expect: now.Add(2 * time.Hour)},
+			{str: "+1h30m", expect: now.Add(90 * time.Minute)},
+			{str: "2026-10-19 02:00", expect: time.Date(2026, 10, 19, 2, 0, 0, 0, loc)},
+			{str: "2026-10-19 02:00:30", expect: time.Date(2026, 10, 19, 2, 0, 30, 0, loc)},
+			{str: "2026-10-20", expect: time.Date(2026, 10, 20, 0, 0, 0, 0, loc)},
+			{str: "2026-10-19T02:00:00Z", expect: time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+			{str: "23:15", expect: time.Date(2026, 10, 18, 23, 15, 0, 0, loc)},
+			{str: "02:00", expect: time.Date(2026, 10, 19, 2, 0, 0, 0, loc)},
+			{str: "21:30", expect: time.Date(2026, 10, 19, 21, 30, 0, 0, loc)},
+			{str: "+-2h", err: true},
+			{str: "+2 hours", err: true},
+			{str: "tomorrow", err: true},
+			{str: "2026-13-01 02:00", err: true},
+		}
+	)
+
+	for _, c := range testCases {
+		var (
+			err error
+			t2  time.Time
+		)
+
+		if t2, err = ParseNotBefore(c.str, now); err != nil {
+			if !c.err {
+				t.Errorf("Error parsing %q: %s",
+					c.str,
+					err.Error())
+			}
+		} else if c.err {
+			t.Errorf("Parsing %q should have failed, got %s",
+				c.str,
+				t2)
+		} else if !t2.Equal(c.expect) {
+			t.Errorf("Unexpected result parsing %q: %s (expected %s)",
+				c.str,
+				t2,
+				c.expect)
+		}
+	}
+} // func TestParseNotBefore(t *testing.T)
+
+func TestJobDeferred(t *testing.T) {
+	var j = &Job{
+		TimeSubmitted: time.Now(),
+		NotBefore:     time.Now().Add(time.Hour),
+	}
+
+	if s := j.Status(); s != status.Deferred {
+		t.Errorf("Unexpected status of deferred Job: %s (expected %s)",
+			s,
+			status.Deferred)
+	}
+
+	j.NotBefore = time.Now().Add(-time.Minute)
+
+	if s := j.Status(); s != status.Enqueued {
+		t.Errorf("Unexpected status of Job that is due: %s (expected %s)",
+			s,
+			status.Enqueued)
+	}
+} // func TestJobDeferred(t *testing.T)
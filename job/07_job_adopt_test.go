@@ -0,0 +1,172 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/07_job_adopt_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 11:24:50 krylon>
+
+//go:build linux
+
+package job
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job/status"
+)
+
+// spawn starts a process the way the Monitor would, to pretend it was started
+// by a previous instance of the Monitor.
+func spawn(t *testing.T, cmd ...string) *exec.Cmd {
+	var proc = exec.Command(cmd[0], cmd[1:]...)
+	proc.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := proc.Start(); err != nil {
+		t.Fatalf("Cannot start %v: %s", cmd, err.Error())
+	}
+
+	return proc
+} // func spawn(t *testing.T, cmd ...string) *exec.Cmd
+
+func TestJobAdopt(t *testing.T) {
+	var (
+		err  error
+		j    *Job
+		cmd  = []string{"/bin/sleep", "1"}
+		proc = spawn(t, cmd...)
+	)
+
+	// We are still the process' parent, so we have to reap it, or it
+	// lingers as a zombie.
+	go proc.Wait() // nolint: errcheck
+
+	if j, err = New(Options{}, cmd...); err != nil {
+		t.Fatalf("Cannot create Job: %s", err.Error())
+	}
+
+	j.ID = 42
+	j.PID = int64(proc.Process.Pid)
+	j.TimeSubmitted = time.Now()
+	j.TimeStarted = time.Now()
+
+	if err = j.Adopt(); err != nil {
+		t.Fatalf("Cannot adopt Job: %s", err.Error())
+	} else if err = j.Adopt(); !errors.Is(err, ErrJobStarted) {
+		t.Errorf("Adopting a Job twice should fail with ErrJobStarted: %v", err)
+	} else if err = j.Wait(); err != nil {
+		t.Fatalf("Error waiting for adopted Job: %s", err.Error())
+	} else if j.Status() != status.Finished {
+		t.Errorf("Unexpected status of adopted Job: %s (expected %s)",
+			j.Status(),
+			status.Finished)
+	} else if j.ExitCode != -1 {
+		t.Errorf("Exit code of adopted Job should be unknown (-1), not %d",
+			j.ExitCode)
+	}
+} // func TestJobAdopt(t *testing.T)
+
+func TestJobAdoptCancel(t *testing.T) {
+	var (
+		err  error
+		j    *Job
+		cmd  = []string{"/bin/sleep", "60"}
+		proc = spawn(t, cmd...)
+	)
+
+	go proc.Wait() // nolint: errcheck
+
+	if j, err = New(Options{MaxDuration: time.Second}, cmd...); err != nil {
+		t.Fatalf("Cannot create Job: %s", err.Error())
+	}
+
+	j.PID = int64(proc.Process.Pid)
+	j.TimeSubmitted = time.Now()
+	j.TimeStarted = time.Now()
+
+	if err = j.Adopt(); err != nil {
+		t.Fatalf("Cannot adopt Job: %s", err.Error())
+	} else if err = j.Wait(); err != nil {
+		t.Fatalf("Error waiting for adopted Job: %s", err.Error())
+	} else if !j.TimedOut {
+		t.Errorf("Adopted Job should have been terminated after %s",
+			j.MaxDuration)
+	} else if j.Runtime() > time.Second*5 {
+		t.Errorf("Adopted Job ran for %s, it should have been terminated after %s",
+			j.Runtime(),
+			j.MaxDuration)
+	}
+} // func TestJobAdoptCancel(t *testing.T)
+
+func TestJobAdoptGone(t *testing.T) {
+	var (
+		err   error
+		j     *Job
+		proc  = spawn(t, "/bin/sleep", "5")
+		other = exec.Command("/bin/sleep", "5")
+		start = time.Now()
+	)
+
+	defer func() {
+		proc.Process.Kill() // nolint: errcheck
+		proc.Wait()         // nolint: errcheck
+	}()
+
+	// other does not lead its own process group, we would not have
+	// started it that way.
+	if err = other.Start(); err != nil {
+		t.Fatalf("Cannot start %v: %s", other.Args, err.Error())
+	}
+
+	defer func() {
+		other.Process.Kill() // nolint: errcheck
+		other.Wait()         // nolint: errcheck
+	}()
+
+	type testCase struct {
+		cmd   []string
+		pid   int
+		start time.Time
+	}
+
+	var testCases = []testCase{
+		// The process was started long before the Job
+		{[]string{"/bin/sleep", "5"}, proc.Process.Pid, start.Add(time.Hour)},
+		// The process was started long after the Job
+		{[]string{"/bin/sleep", "5"}, proc.Process.Pid, start.Add(-time.Hour)},
+		// Not a process group leader
+		{[]string{"/bin/sleep", "5"}, other.Process.Pid, start},
+	}
+
+	for idx, c := range testCases {
+		if j, err = New(Options{}, c.cmd...); err != nil {
+			t.Fatalf("Cannot create Job: %s", err.Error())
+		}
+
+		j.PID = int64(c.pid)
+		j.TimeStarted = c.start
+
+		if err = j.Adopt(); !errors.Is(err, ErrProcessGone) {
+			t.Errorf("Test case %d: Adopt should have failed with ErrProcessGone: %v",
+				idx,
+				err)
+		}
+	}
+
+	// And finally, a process that is actually gone.
+	proc.Process.Kill() // nolint: errcheck
+	proc.Wait()         // nolint: errcheck
+
+	if j, err = New(Options{}, "/bin/sleep", "5"); err != nil {
+		t.Fatalf("Cannot create Job: %s", err.Error())
+	}
+
+	j.PID = int64(proc.Process.Pid)
+	j.TimeStarted = start
+
+	if err = j.Adopt(); !errors.Is(err, ErrProcessGone) {
+		t.Errorf("Adopt should have failed with ErrProcessGone: %v", err)
+	}
+} // func TestJobAdoptGone(t *testing.T)
@@ -0,0 +1,111 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/08_job_retry_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 13:52:08 krylon>
+
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	type testCase struct {
+		policy   RetryPolicy
+		attempts int
+		expect   time.Duration
+	}
+
+	var testCases = []testCase{
+		{RetryPolicy{}, 1, 0},
+		{RetryPolicy{Backoff: time.Second}, 1, time.Second},
+		{RetryPolicy{Backoff: time.Second}, 2, time.Second * 2},
+		{RetryPolicy{Backoff: time.Second}, 4, time.Second * 8},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Second * 5}, 4, time.Second * 5},
+		{RetryPolicy{Backoff: time.Hour, MaxBackoff: time.Minute}, 1, time.Minute},
+		{RetryPolicy{Backoff: time.Hour}, 1000, time.Hour << 21},
+	}
+
+	for idx, c := range testCases {
+		if d := c.policy.Delay(c.attempts); d != c.expect {
+			t.Errorf("Test case %d: unexpected delay after %d attempts: %s (expected %s)",
+				idx,
+				c.attempts,
+				d,
+				c.expect)
+		}
+	}
+} // func TestRetryDelay(t *testing.T)
+
+func TestRetryable(t *testing.T) {
+	type testCase struct {
+		policy    RetryPolicy
+		attempts  int
+		exit      int
+		timedOut  bool
+		cancelled bool
+		expect    bool
+	}
+
+	var testCases = []testCase{
+		{RetryPolicy{}, 1, 1, false, false, false},
+		{RetryPolicy{MaxAttempts: 3}, 1, 1, false, false, true},
+		{RetryPolicy{MaxAttempts: 3}, 2, 1, false, false, true},
+		{RetryPolicy{MaxAttempts: 3}, 3, 1, false, false, false},
+		{RetryPolicy{MaxAttempts: 3}, 1, 0, false, false, false},
+		{RetryPolicy{MaxAttempts: 3}, 1, -1, true, false, true},
+		{RetryPolicy{MaxAttempts: 3}, 1, -1, false, true, false},
+		{RetryPolicy{MaxAttempts: 3, ExitCodes: []int{75}}, 1, 75, false, false, true},
+		{RetryPolicy{MaxAttempts: 3, ExitCodes: []int{75}}, 1, 1, false, false, false},
+		{RetryPolicy{MaxAttempts: 3, ExitCodes: []int{75}}, 1, -1, true, false, false},
+	}
+
+	for idx, c := range testCases {
+		var j = &Job{
+			Options:  Options{Retry: c.policy},
+			Attempts: c.attempts,
+			ExitCode: c.exit,
+			TimedOut: c.timedOut,
+		}
+
+		if c.cancelled {
+			j.TimeCancelled = time.Now()
+		}
+
+		if r := j.Retryable(); r != c.expect {
+			t.Errorf("Test case %d: Retryable returned %t, expected %t",
+				idx,
+				r,
+				c.expect)
+		}
+	}
+} // func TestRetryable(t *testing.T)
+
+func TestRetryValidate(t *testing.T) {
+	var invalid = []RetryPolicy{
+		{MaxAttempts: -1},
+		{Backoff: -time.Second},
+		{MaxBackoff: -time.Second},
+		{ExitCodes: []int{0}},
+		{ExitCodes: []int{256}},
+	}
+
+	for idx, r := range invalid {
+		var o = Options{Retry: r}
+		if err := o.Validate(); err == nil {
+			t.Errorf("RetryPolicy %d should be invalid: %#v", idx, r)
+		}
+	}
+
+	var codes, err = ParseExitCodes("1, 75,")
+
+	if err != nil {
+		t.Errorf("Cannot parse exit codes: %s", err.Error())
+	} else if len(codes) != 2 || codes[0] != 1 || codes[1] != 75 {
+		t.Errorf("Unexpected exit codes: %v", codes)
+	} else if _, err = ParseExitCodes("1,x"); err == nil {
+		t.Errorf("Parsing invalid exit codes should fail")
+	}
+} // func TestRetryValidate(t *testing.T)
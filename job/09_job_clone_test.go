@@ -0,0 +1,83 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/09_job_clone_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 10:02:35 krylon>
+
+package job
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobClone(t *testing.T) {
+	var (
+		err  error
+		orig *Job
+		c    *Job
+		opt  = Options{
+			Directory:   "/tmp",
+			MaxDuration: time.Minute,
+			Nice:        5,
+			Retry:       RetryPolicy{MaxAttempts: 3, Backoff: time.Second, ExitCodes: []int{75}},
+		}
+	)
+
+	if orig, err = New(opt, "/bin/sh", "-c", "exit 3"); err != nil {
+		t.Fatalf("Cannot create Job: %s", err.Error())
+	}
+
+	orig.ID = 42
+	orig.Env = []string{"FOO=bar"}
+	orig.Priority = 7
+	orig.Attempts = 3
+	orig.ExitCode = 3
+	orig.TimeStarted = time.Now().Add(-time.Minute)
+	orig.TimeEnded = time.Now()
+	orig.SpoolOut = "/nonexistent/jobq.42.out"
+	orig.Depends = []Dependency{{ID: 41}}
+
+	c = orig.Clone()
+
+	if c.ID != 0 || c.Attempts != 0 || c.ExitCode != -1 || !c.TimeStarted.IsZero() || c.SpoolOut != "" || c.Depends != nil {
+		t.Errorf("Clone should not carry over the execution of the original: %#v", c)
+	} else if !reflect.DeepEqual(c.Options, orig.Options) ||
+		!reflect.DeepEqual(c.Cmd, orig.Cmd) ||
+		!reflect.DeepEqual(c.Env, orig.Env) ||
+		c.Priority != orig.Priority {
+		t.Errorf("Clone does not match original:\nExpected: %#v\nActual:   %#v",
+			orig,
+			c)
+	}
+
+	// Changing the clone must not change the original.
+	c.Cmd[0] = "/bin/bash"
+	c.Env[0] = "FOO=baz"
+	c.Retry.ExitCodes[0] = 1
+
+	if orig.Cmd[0] != "/bin/sh" || orig.Env[0] != "FOO=bar" || orig.Retry.ExitCodes[0] != 75 {
+		t.Errorf("Changing the clone changed the original: %#v", orig)
+	}
+
+	var src = &Job{
+		Options: Options{
+			Nice:  10,
+			Retry: RetryPolicy{MaxAttempts: 5, Backoff: time.Hour},
+		},
+		Cmd:      []string{"/bin/true"},
+		Priority: 1,
+	}
+
+	if err = c.Override(src, "Cmd", "Nice", "Retry.MaxAttempts"); err != nil {
+		t.Fatalf("Cannot override fields: %s", err.Error())
+	} else if c.Cmd[0] != "/bin/true" || c.Nice != 10 || c.Retry.MaxAttempts != 5 {
+		t.Errorf("Fields were not overridden: %#v", c)
+	} else if c.Priority != orig.Priority || c.Retry.Backoff != orig.Retry.Backoff || c.Directory != orig.Directory {
+		t.Errorf("Fields were overridden that should not have been: %#v", c)
+	} else if err = c.Override(src, "Bogus"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Overriding an unknown field should fail with ErrInvalidOption: %v", err)
+	}
+} // func TestJobClone(t *testing.T)
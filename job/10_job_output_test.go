@@ -0,0 +1,127 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/10_job_output_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 17:25:41 krylon>
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/blicero/jobq/common"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	type testCase struct {
+		s      string
+		expect OutputMode
+		err    bool
+	}
+
+	var testCases = []testCase{
+		{"", 0, false},
+		{"none", 0, false},
+		{"merge", OutputMerge, false},
+		{"merge,tags", OutputMerge | OutputTags, false},
+		{"Timestamps, Merge", OutputMerge | OutputTimestamps, false},
+		{"merge,timestamps,tags", OutputMerge | OutputTimestamps | OutputTags, false},
+		{"interleave", 0, true},
+	}
+
+	for idx, c := range testCases {
+		var mode, err = ParseOutputMode(c.s)
+
+		if c.err {
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("Test case %d (%q): expected ErrInvalidOption, got %v",
+					idx,
+					c.s,
+					err)
+			}
+		} else if err != nil {
+			t.Errorf("Test case %d (%q): unexpected error: %s",
+				idx,
+				c.s,
+				err.Error())
+		} else if mode != c.expect {
+			t.Errorf("Test case %d (%q): expected %s, got %s",
+				idx,
+				c.s,
+				c.expect,
+				mode)
+		} else if mode != 0 {
+			// The String() form must parse to the same value.
+			if again, _ := ParseOutputMode(mode.String()); again != mode {
+				t.Errorf("Test case %d: %s does not survive a round trip: %s",
+					idx,
+					mode,
+					again)
+			}
+		}
+	}
+} // func TestParseOutputMode(t *testing.T)
+
+func TestJobOutputMerge(t *testing.T) {
+	const script = "echo one; sleep 0.2; echo two >&2; sleep 0.2; echo three"
+
+	type testCase struct {
+		mode   OutputMode
+		expect *regexp.Regexp
+	}
+
+	var testCases = []testCase{
+		{
+			mode:   OutputMerge,
+			expect: regexp.MustCompile(`^one\ntwo\nthree\n$`),
+		},
+		{
+			mode:   OutputMerge | OutputTags,
+			expect: regexp.MustCompile(`^out one\nerr two\nout three\n$`),
+		},
+		{
+			mode:   OutputMerge | OutputTimestamps | OutputTags,
+			expect: regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{3} (out|err) (one|two|three)\n){3}$`),
+		},
+	}
+
+	for idx, c := range testCases {
+		var (
+			err     error
+			j       *Job
+			buf     []byte
+			outpath = filepath.Join(common.BaseDir, fmt.Sprintf("merge.%d.log", idx))
+			errpath = filepath.Join(common.BaseDir, fmt.Sprintf("merge.%d.err", idx))
+		)
+
+		if j, err = New(Options{Output: c.mode}, "/bin/sh", "-c", script); err != nil {
+			t.Fatalf("Error creating Job: %s", err.Error())
+		} else if err = j.Start(outpath, errpath); err != nil {
+			t.Fatalf("Failed to start Job: %s", err.Error())
+		} else if err = j.Wait(); err != nil {
+			t.Fatalf("Job failed: %s", err.Error())
+		} else if j.SpoolErr != "" {
+			t.Errorf("Test case %d: Job with merged output should have no stderr spool file: %q",
+				idx,
+				j.SpoolErr)
+		} else if _, err = os.Stat(errpath); !os.IsNotExist(err) {
+			t.Errorf("Test case %d: stderr spool file should not have been created: %v",
+				idx,
+				err)
+		} else if buf, err = os.ReadFile(outpath); err != nil {
+			t.Fatalf("Cannot read spool file %s: %s",
+				outpath,
+				err.Error())
+		} else if !c.expect.Match(buf) {
+			t.Errorf("Test case %d: unexpected output from Job %s:\n%s",
+				idx,
+				c.mode,
+				buf)
+		}
+	}
+} // func TestJobOutputMerge(t *testing.T)
@@ -0,0 +1,174 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/11_job_spool_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 10:02:17 krylon>
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blicero/jobq/common"
+)
+
+// readSpool reads and decompresses a spool file.
+func readSpool(path, compression string) (string, error) {
+	var (
+		err error
+		fh  *os.File
+		rd  io.ReadCloser
+		buf []byte
+	)
+
+	if fh, err = os.Open(path); err != nil {
+		return "", err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	if rd, err = NewSpoolReader(fh, compression); err != nil {
+		return "", err
+	}
+
+	defer rd.Close() // nolint: errcheck
+
+	if buf, err = io.ReadAll(rd); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+} // func readSpool(path, compression string) (string, error)
+
+func TestJobSpoolCompress(t *testing.T) {
+	// Enough output that the compressors have to flush more than once.
+	const script = "seq 1 20000; echo oops >&2"
+
+	type testCase struct {
+		compress    string
+		compression string
+		suffix      string
+		mode        OutputMode
+	}
+
+	var testCases = []testCase{
+		{"", CompressNone, "", 0},
+		{"gzip", CompressGzip, ".gz", 0},
+		{"yes", CompressGzip, ".gz", 0},
+		{"zstd", CompressZstd, ".zst", 0},
+		{"zstd", CompressZstd, ".zst", OutputMerge | OutputTags},
+	}
+
+	var expectOut strings.Builder
+
+	for i := 1; i <= 20000; i++ {
+		fmt.Fprintf(&expectOut, "%d\n", i)
+	}
+
+	for idx, c := range testCases {
+		var (
+			err          error
+			j            *Job
+			stdout       string
+			stderr       string
+			opt          = Options{Compress: c.compress, Output: c.mode}
+			outpath      = filepath.Join(common.BaseDir, fmt.Sprintf("spool.%d.out", idx))
+			errpath      = filepath.Join(common.BaseDir, fmt.Sprintf("spool.%d.err", idx))
+			expectStdout = expectOut.String()
+		)
+
+		if opt.Compression() != c.compression {
+			t.Errorf("Test case %d: Compression of %q should be %q, not %q",
+				idx,
+				c.compress,
+				c.compression,
+				opt.Compression())
+			continue
+		} else if opt.SpoolSuffix() != c.suffix {
+			t.Errorf("Test case %d: SpoolSuffix of %q should be %q, not %q",
+				idx,
+				c.compress,
+				c.suffix,
+				opt.SpoolSuffix())
+			continue
+		}
+
+		if j, err = New(opt, "/bin/sh", "-c", script); err != nil {
+			t.Fatalf("Error creating Job: %s", err.Error())
+		} else if err = j.Start(outpath, errpath); err != nil {
+			t.Fatalf("Failed to start Job: %s", err.Error())
+		} else if err = j.Wait(); err != nil {
+			t.Fatalf("Job failed: %s", err.Error())
+		} else if stdout, err = readSpool(outpath, c.compression); err != nil {
+			t.Errorf("Test case %d: Cannot read spool file %s: %s",
+				idx,
+				outpath,
+				err.Error())
+			continue
+		}
+
+		if c.mode&OutputMerge != 0 {
+			// stdout and stderr are copied concurrently, so we cannot
+			// tell where the line from stderr ends up.
+			if strings.Count(stdout, "err oops\n") != 1 {
+				t.Errorf("Test case %d: Merged output lacks stderr", idx)
+			}
+
+			stdout = strings.Replace(stdout, "err oops\n", "", 1)
+			expectStdout = "out " + strings.ReplaceAll(
+				strings.TrimSuffix(expectStdout, "\n"),
+				"\n",
+				"\nout ") + "\n"
+		} else if stderr, err = readSpool(errpath, c.compression); err != nil {
+			t.Errorf("Test case %d: Cannot read spool file %s: %s",
+				idx,
+				errpath,
+				err.Error())
+			continue
+		} else if stderr != "oops\n" {
+			t.Errorf("Test case %d: Unexpected stderr: %q",
+				idx,
+				stderr)
+		}
+
+		if stdout != expectStdout {
+			t.Errorf("Test case %d: stdout is not complete: expected %d bytes, got %d",
+				idx,
+				len(expectStdout),
+				len(stdout))
+		}
+	}
+} // func TestJobSpoolCompress(t *testing.T)
+
+func TestNewSpoolReaderEmpty(t *testing.T) {
+	for _, c := range []string{CompressNone, CompressGzip, CompressZstd} {
+		var (
+			err error
+			rd  io.ReadCloser
+			buf []byte
+		)
+
+		if rd, err = NewSpoolReader(strings.NewReader(""), c); err != nil {
+			t.Errorf("Cannot read empty spool file (%q): %s",
+				c,
+				err.Error())
+		} else if buf, err = io.ReadAll(rd); err != nil {
+			t.Errorf("Error reading empty spool file (%q): %s",
+				c,
+				err.Error())
+		} else if len(buf) != 0 {
+			t.Errorf("Empty spool file (%q) yielded %d bytes",
+				c,
+				len(buf))
+		}
+	}
+
+	if _, err := NewSpoolReader(strings.NewReader(""), "lzma"); err == nil {
+		t.Error("NewSpoolReader should reject unknown compression types")
+	}
+} // func TestNewSpoolReaderEmpty(t *testing.T)
@@ -0,0 +1,103 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/clone.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 09:41:12 krylon>
+
+package job
+
+import "fmt"
+
+// Clone returns a new Job that runs the same command as j, with the same
+// Options, environment and Priority, ready to be submitted.
+// Everything that refers to the execution of j - times, exit code, spool
+// files, attempts - is left out, and so are its dependencies and NotBefore,
+// which were only meaningful when j was submitted.
+func (j *Job) Clone() *Job {
+	var c = &Job{
+		Options:  j.Options,
+		Priority: j.Priority,
+		ExitCode: -1,
+		Cmd:      make([]string, len(j.Cmd)),
+	}
+
+	copy(c.Cmd, j.Cmd)
+
+	if j.Env != nil {
+		c.Env = make([]string, len(j.Env))
+		copy(c.Env, j.Env)
+	}
+
+	if j.EnvSet != nil {
+		c.EnvSet = make([]string, len(j.EnvSet))
+		copy(c.EnvSet, j.EnvSet)
+	}
+
+	if j.Retry.ExitCodes != nil {
+		c.Retry.ExitCodes = make([]int, len(j.Retry.ExitCodes))
+		copy(c.Retry.ExitCodes, j.Retry.ExitCodes)
+	}
+
+	return c
+} // func (j *Job) Clone() *Job
+
+// Override copies the named fields from src to j. Fields are named like the
+// fields of Job and Options, fields of nested structs are named with a
+// prefix, e.g. "Retry.Backoff" or "Limits.OpenFiles".
+func (j *Job) Override(src *Job, fields ...string) error {
+	for _, f := range fields {
+		switch f {
+		case "Cmd":
+			j.Cmd = src.Cmd
+		case "Env":
+			j.Env = src.Env
+			j.EnvSet = src.EnvSet
+		case "Priority":
+			j.Priority = src.Priority
+		case "Depends":
+			j.Depends = src.Depends
+		case "NotBefore":
+			j.NotBefore = src.NotBefore
+		case "MaxDuration":
+			j.MaxDuration = src.MaxDuration
+		case "Directory":
+			j.Directory = src.Directory
+		case "Compress":
+			j.Compress = src.Compress
+		case "Nice":
+			j.Nice = src.Nice
+		case "IOClass":
+			j.IOClass = src.IOClass
+		case "IOPriority":
+			j.IOPriority = src.IOPriority
+		case "Limits.AddressSpace":
+			j.Limits.AddressSpace = src.Limits.AddressSpace
+		case "Limits.CPUSeconds":
+			j.Limits.CPUSeconds = src.Limits.CPUSeconds
+		case "Limits.OpenFiles":
+			j.Limits.OpenFiles = src.Limits.OpenFiles
+		case "Limits.Processes":
+			j.Limits.Processes = src.Limits.Processes
+		case "Output":
+			j.Output = src.Output
+		case "Hook":
+			j.Hook = src.Hook
+		case "Restart":
+			j.Restart = src.Restart
+		case "Retry.MaxAttempts":
+			j.Retry.MaxAttempts = src.Retry.MaxAttempts
+		case "Retry.Backoff":
+			j.Retry.Backoff = src.Retry.Backoff
+		case "Retry.MaxBackoff":
+			j.Retry.MaxBackoff = src.Retry.MaxBackoff
+		case "Retry.ExitCodes":
+			j.Retry.ExitCodes = src.Retry.ExitCodes
+		default:
+			return makeJobError(
+				fmt.Sprintf("Cannot override unknown field %q", f),
+				ErrInvalidOption)
+		}
+	}
+
+	return nil
+} // func (j *Job) Override(src *Job, fields ...string) error
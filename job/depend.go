@@ -0,0 +1,131 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/depend.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 20:14:37 krylon>
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Condition determines under what circumstances a Job that depends on
+// another Job may run.
+type Condition uint8
+
+// AfterOK means the Job may run after the other Job has finished
+// successfully, i.e. with an exit code of 0, without timing out and without
+// being cancelled.
+// AfterNotOK means the Job may run after the other Job has failed, timed out
+// or been cancelled.
+// AfterAny means the Job may run after the other Job has finished, no matter
+// how.
+const (
+	AfterOK Condition = iota
+	AfterNotOK
+	AfterAny
+)
+
+var condNames = map[Condition]string{
+	AfterOK:    "afterok",
+	AfterNotOK: "afternotok",
+	AfterAny:   "afterany",
+}
+
+func (c Condition) String() string {
+	if name, ok := condNames[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Condition(%d)", c)
+} // func (c Condition) String() string
+
+// ParseCondition converts the name of a Condition to its value.
+func ParseCondition(s string) (Condition, error) {
+	for c, name := range condNames {
+		if strings.EqualFold(s, name) {
+			return c, nil
+		}
+	}
+
+	return AfterOK, makeJobError(
+		fmt.Sprintf("Invalid dependency condition %q", s),
+		ErrInvalidDependency)
+} // func ParseCondition(s string) (Condition, error)
+
+// ErrInvalidDependency indicates that a Job's list of dependencies is not
+// valid.
+var ErrInvalidDependency = errors.New("Invalid dependency")
+
+// Dependency means a Job must not be started before the Job identified by ID
+// has finished, and only if the Condition is met.
+// If the Condition can no longer be met, e.g. because the other Job failed
+// and the Condition is AfterOK, the dependent Job is cancelled.
+type Dependency struct {
+	ID        int64
+	Condition Condition
+}
+
+// ParseDependency parses a list of Dependencies in the form
+// "condition:id[:id...]", e.g. "afterok:12:13".
+func ParseDependency(s string) ([]Dependency, error) {
+	var (
+		err  error
+		cond Condition
+		deps []Dependency
+		args = strings.Split(s, ":")
+	)
+
+	if len(args) < 2 {
+		return nil, makeJobError(
+			fmt.Sprintf("Expected condition:id[:id...], not %q", s),
+			ErrInvalidDependency)
+	} else if cond, err = ParseCondition(args[0]); err != nil {
+		return nil, err
+	}
+
+	deps = make([]Dependency, len(args)-1)
+
+	for idx, arg := range args[1:] {
+		if deps[idx].ID, err = strconv.ParseInt(arg, 10, 64); err != nil {
+			return nil, makeJobError(
+				fmt.Sprintf("Cannot parse Job ID %q", arg),
+				ErrInvalidDependency)
+		}
+
+		deps[idx].Condition = cond
+	}
+
+	return deps, nil
+} // func ParseDependency(s string) ([]Dependency, error)
+
+// ValidateDepends checks the Job's list of dependencies for invalid
+// conditions and duplicate entries. Whether the Jobs it depends on exist and
+// whether the dependencies form a cycle can only be checked by the database.
+func (j *Job) ValidateDepends() error {
+	var seen = make(map[int64]bool, len(j.Depends))
+
+	for _, d := range j.Depends {
+		if _, ok := condNames[d.Condition]; !ok {
+			return makeJobError(
+				fmt.Sprintf("Invalid condition %d for Job %d", d.Condition, d.ID),
+				ErrInvalidDependency)
+		} else if d.ID <= 0 {
+			return makeJobError(
+				fmt.Sprintf("Invalid Job ID %d", d.ID),
+				ErrInvalidDependency)
+		} else if seen[d.ID] {
+			return makeJobError(
+				fmt.Sprintf("Job %d is listed more than once", d.ID),
+				ErrInvalidDependency)
+		}
+
+		seen[d.ID] = true
+	}
+
+	return nil
+} // func (j *Job) ValidateDepends() error
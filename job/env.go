@@ -0,0 +1,231 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/env.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 19:11:48 krylon>
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// EnvMode determines which of the submitter's environment variables are
+// passed on to a Job.
+type EnvMode uint8
+
+// EnvInherit means the Job runs with the Monitor's environment, plus the
+// entries of the EnvFilter's Set list, see Job.EnvSet.
+// EnvFull means the Job gets the submitter's environment, except for the
+// variables excluded by the EnvFilter's Deny list.
+// EnvSelect means the Job only gets the variables on the EnvFilter's Allow
+// list.
+// EnvClean means the Job starts with an empty environment.
+const (
+	EnvInherit EnvMode = iota
+	EnvFull
+	EnvSelect
+	EnvClean
+)
+
+// ParseEnvMode converts the name of an EnvMode to its value.
+func ParseEnvMode(s string) (EnvMode, error) {
+	switch strings.ToLower(s) {
+	case "inherit", "none":
+		return EnvInherit, nil
+	case "", "full":
+		return EnvFull, nil
+	case "select":
+		return EnvSelect, nil
+	case "clean":
+		return EnvClean, nil
+	default:
+		return EnvInherit, makeJobError(
+			fmt.Sprintf("Invalid environment mode %q", s),
+			ErrInvalidOption)
+	}
+} // func ParseEnvMode(s string) (EnvMode, error)
+
+// EnvFilter describes how to derive a Job's environment from the environment
+// of the process that submits it.
+//
+// Allow and Deny are lists of variable names, which may contain shell-style
+// wildcards, e.g. "LC_*".
+//
+// Set is a list of KEY=VALUE entries that are added after filtering,
+// replacing variables of the same name.
+type EnvFilter struct {
+	Mode  EnvMode
+	Allow []string
+	Deny  []string
+	Set   []string
+}
+
+// ErrInvalidEnv indicates that an environment entry is not of the form
+// KEY=VALUE.
+var ErrInvalidEnv = errors.New("Invalid environment entry")
+
+// Apply returns the environment for a Job, given the submitter's environment
+// in the format returned by os.Environ.
+// If the Mode is EnvInherit, it returns nil, meaning the Job gets the
+// Monitor's environment. Apply runs on the submitter's side, where the
+// Monitor's environment is not known, so the entries to Set must be passed
+// on separately, see Inherited and Job.EnvSet.
+func (f *EnvFilter) Apply(environ []string) ([]string, error) {
+	var (
+		err error
+		env []string
+	)
+
+	for _, kv := range f.Set {
+		if !strings.Contains(kv, "=") || strings.HasPrefix(kv, "=") {
+			return nil, makeJobError(
+				fmt.Sprintf("Cannot set %q", kv),
+				ErrInvalidEnv)
+		}
+	}
+
+	for _, patterns := range [][]string{f.Allow, f.Deny} {
+		for _, pat := range patterns {
+			if _, err = path.Match(pat, ""); err != nil {
+				return nil, makeJobError(
+					fmt.Sprintf("Invalid pattern %q", pat),
+					err)
+			}
+		}
+	}
+
+	switch f.Mode {
+	case EnvInherit:
+		return nil, nil
+	case EnvFull:
+		env = make([]string, 0, len(environ))
+		for _, kv := range environ {
+			if !matchEnv(kv, f.Deny) {
+				env = append(env, kv)
+			}
+		}
+	case EnvSelect:
+		env = make([]string, 0, len(f.Allow))
+		for _, kv := range environ {
+			if matchEnv(kv, f.Allow) {
+				env = append(env, kv)
+			}
+		}
+	case EnvClean:
+		env = make([]string, 0, len(f.Set))
+	default:
+		return nil, makeJobError(
+			fmt.Sprintf("Invalid environment mode %d", f.Mode),
+			ErrInvalidOption)
+	}
+
+	return setEnv(env, f.Set), nil
+} // func (f *EnvFilter) Apply(environ []string) ([]string, error)
+
+// Inherited returns the entries to add to the Monitor's environment for a
+// Job whose Env is nil, i.e. the Set list, if the Mode is EnvInherit, see
+// Job.EnvSet. Call Apply first to validate the entries.
+func (f *EnvFilter) Inherited() []string {
+	if f.Mode != EnvInherit || len(f.Set) == 0 {
+		return nil
+	}
+
+	var set = make([]string, len(f.Set))
+	copy(set, f.Set)
+	return set
+} // func (f *EnvFilter) Inherited() []string
+
+// Environ returns the environment the Job runs with: its Env, or, if that is
+// nil, the environment of the current process, i.e. the Monitor, with the
+// entries of EnvSet added.
+func (j *Job) Environ() []string {
+	if j.Env != nil {
+		return j.Env
+	}
+
+	return setEnv(os.Environ(), j.EnvSet)
+} // func (j *Job) Environ() []string
+
+// setEnv adds the KEY=VALUE entries in set to env, replacing variables of
+// the same name.
+func setEnv(env, set []string) []string {
+	for _, kv := range set {
+		var name = envName(kv)
+
+		for idx := 0; idx < len(env); idx++ {
+			if envName(env[idx]) == name {
+				env = append(env[:idx], env[idx+1:]...)
+				idx--
+			}
+		}
+
+		env = append(env, kv)
+	}
+
+	return env
+} // func setEnv(env, set []string) []string
+
+func envName(kv string) string {
+	var name, _, _ = strings.Cut(kv, "=")
+	return name
+} // func envName(kv string) string
+
+// matchEnv returns true if the name of the environment entry matches any of
+// the patterns. We checked the patterns before, so we can ignore errors
+// here.
+func matchEnv(kv string, patterns []string) bool {
+	var name = envName(kv)
+
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, name); ok {
+			return true
+		}
+	}
+
+	return false
+} // func matchEnv(kv string, patterns []string) bool
+
+// lookPath searches for an executable in the directories listed in the PATH
+// variable of the given environment, much like exec.LookPath does for the
+// current process.
+func lookPath(file string, env []string) (string, error) {
+	if strings.Contains(file, "/") {
+		return file, nil
+	}
+
+	var dirs string
+
+	for _, kv := range env {
+		if envName(kv) == "PATH" {
+			dirs = kv[len("PATH="):]
+		}
+	}
+
+	for _, dir := range filepath.SplitList(dirs) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			// Like exec.LookPath, we refuse to run commands from
+			// relative directories in PATH.
+			continue
+		}
+
+		var (
+			err  error
+			info os.FileInfo
+			p    = filepath.Join(dir, file)
+		)
+
+		if info, err = os.Stat(p); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return p, nil
+		}
+	}
+
+	return "", makeJobError(
+		fmt.Sprintf("Cannot find %q in the Job's PATH", file),
+		os.ErrNotExist)
+} // func lookPath(file string, env []string) (string, error)
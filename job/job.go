@@ -8,7 +8,6 @@
 package job
 
 import (
-	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,8 +15,12 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/blicero/jobq/common"
 	"github.com/blicero/jobq/job/status"
 )
 
@@ -64,13 +67,89 @@ var (
 )
 
 // Options for the Job
+//
+// MaxDuration is the time a Job may run before it is terminated. Zero means
+// there is no limit.
+//
+// Nice is the nice value the Job's process is run with.
+//
+// IOClass and IOPriority set the I/O scheduling class and the priority within
+// that class (0-7, lower values mean higher priority).
+//
+// Limits are the resource limits applied to the Job's process.
+//
+// Restart determines what happens if the Job's process is gone after the
+// Monitor was restarted while the Job was running, see RestartPolicy.
+//
+// Retry determines if a Job that failed is run again, see RetryPolicy.
+//
+// Output determines how the Job's output is spooled, see OutputMode.
+//
+// Hook is a shell command the Monitor runs after the Job has finished. It
+// runs in the Job's Directory, with the Job's environment, plus a few
+// variables that describe the Job, e.g. JOBQ_ID and JOBQ_EXIT.
 type Options struct {
 	MaxDuration time.Duration
 	Directory   string
 	Compress    string
 	Nice        int
+	IOClass     IOClass
+	IOPriority  int
+	Limits      Limits
+	Restart     RestartPolicy
+	Retry       RetryPolicy
+	Output      OutputMode
+	Hook        string
 }
 
+// Validate checks the Options for invalid values.
+func (o *Options) Validate() error {
+	if o.MaxDuration < 0 {
+		return makeJobError(
+			fmt.Sprintf("MaxDuration must not be negative: %s", o.MaxDuration),
+			ErrInvalidOption)
+	}
+
+	switch strings.ToLower(o.Compress) {
+	case "", "no", "false", "gzip", "yes", "true", "zstd":
+	default:
+		return makeJobError(
+			fmt.Sprintf("Invalid compression type %q", o.Compress),
+			ErrInvalidOption)
+	}
+
+	if o.Nice < -20 || o.Nice > 19 {
+		return makeJobError(
+			fmt.Sprintf("Nice must be between -20 and 19, not %d", o.Nice),
+			ErrInvalidOption)
+	} else if o.IOClass < IOClassNone || o.IOClass > IOClassIdle {
+		return makeJobError(
+			fmt.Sprintf("Invalid I/O class %d", o.IOClass),
+			ErrInvalidOption)
+	} else if o.IOPriority < 0 || o.IOPriority > 7 {
+		return makeJobError(
+			fmt.Sprintf("I/O priority must be between 0 and 7, not %d", o.IOPriority),
+			ErrInvalidOption)
+	} else if o.Limits.AddressSpace < 0 ||
+		o.Limits.CPUSeconds < 0 ||
+		o.Limits.OpenFiles < 0 ||
+		o.Limits.Processes < 0 {
+		return makeJobError(
+			fmt.Sprintf("Resource limits must not be negative: %#v", o.Limits),
+			ErrInvalidOption)
+	} else if o.Output&^outputAll != 0 {
+		return makeJobError(
+			fmt.Sprintf("Invalid output mode %d", o.Output),
+			ErrInvalidOption)
+	} else if _, ok := restartNames[o.Restart]; !ok {
+		return makeJobError(
+			fmt.Sprintf("Invalid restart policy %d", o.Restart),
+			ErrInvalidOption)
+	}
+
+	return o.Retry.Validate()
+} // func (o *Options) Validate() error
+
 // Job is a batch job, submitted for execution.
 // ID is an integer value that is used to uniquely identify Job instances
 //
@@ -82,27 +161,78 @@ type Options struct {
 // TimeStarted and TimeEnded are the times at which the Job was started and
 // ended, to be filled in by the scheduler or monitor.
 //
+// TimeCancelled is the time the Job was cancelled, if it was cancelled at all.
+//
+// NotBefore is the earliest time the Job may be started. If it is zero, the
+// Job may be started as soon as it is submitted.
+//
 // ExitCode is the exit code given by the operating system.
 //
+// TimedOut is true if the Job was terminated because it exceeded its
+// MaxDuration.
+//
+// Attempts is the number of times the Job has been started. If the Job is
+// retried, the times, ExitCode, PID and spool files refer to the latest
+// attempt.
+//
+// Runs is the history of all attempts to run the Job, including the current
+// one. It is only filled in when the Monitor reports the state of the queue.
+//
+// Lost is true if the Job's process disappeared while the Monitor was not
+// running, so we do not know how it ended.
+//
 // Cmd is the array of arguments, the first element is the command itself,
 // followed by parameters/arguments.
 //
+// Priority determines the order in which pending Jobs are started. Jobs with
+// a higher Priority are started first, Jobs with the same Priority are
+// started in the order they were submitted.
+//
+// Env is the environment the Job is run with, in the same format as returned
+// by os.Environ. If it is nil, the Job inherits the Monitor's environment,
+// with the KEY=VALUE entries in EnvSet added to it, see Environ.
+// See EnvFilter for a way to capture the submitter's environment.
+//
+// Depends is the list of Jobs that need to finish before this Job can be
+// started, see Dependency.
+//
 // SpoolOut and SpoolErr are the names of the files where the output of the
 // Job is stored, again to be filled in by the scheduler.
 //
 // proc (private) is a handle to process while it is running.
+//
+// done (private) is closed once the process has exited.
+//
+// adopted (private) is true if the process was started by a previous
+// instance of the Monitor, see Adopt.
+//
+// spool (private) holds the spool files and their compressors while the Job
+// is running, they are closed when the Job has finished.
 type Job struct {
 	Options
 	ID            int64
 	TimeSubmitted time.Time
 	TimeStarted   time.Time
 	TimeEnded     time.Time
+	TimeCancelled time.Time
+	NotBefore     time.Time
 	ExitCode      int
+	TimedOut      bool
+	Attempts      int
+	Lost          bool
+	Priority      int
 	Cmd           []string
+	Env           []string
+	EnvSet        []string
+	Depends       []Dependency
+	Runs          []Run
 	SpoolOut      string
 	SpoolErr      string
 	PID           int64
 	proc          *exec.Cmd
+	done          chan struct{}
+	adopted       bool
+	spool         []io.Closer
 }
 
 // New creates a new Job instance with the given options and command line.
@@ -141,45 +271,96 @@ func (j *Job) CmdString() string {
 func (j *Job) Start(outpath, errpath string) error {
 	var (
 		err        error
-		outh, errh *os.File
 		outc, errc io.Writer
 	)
 
 	if j.proc != nil {
 		return ErrJobStarted
+	} else if err = j.Options.Validate(); err != nil {
+		return err
+	} else if j.needShim() && shimPath == "" {
+		return makeJobError(
+			"Nice value, I/O priority and resource limits are not supported on this system",
+			ErrInvalidOption)
 	}
 
+	var merge = j.Output&OutputMerge != 0
+
 	j.SpoolOut = outpath
 	j.SpoolErr = errpath
 
-	if outh, err = os.Create(outpath); err != nil {
+	if merge {
+		// errpath is not used, stderr goes to the same file as stdout.
+		j.SpoolErr = ""
+	}
+
+	// If anything goes wrong from here on, we close the spool files we
+	// opened.
+	defer func() {
+		if err != nil {
+			j.closeSpool() // nolint: errcheck
+		}
+	}()
+
+	if outc, err = j.openSpool(outpath); err != nil {
 		return makeJobError(
 			fmt.Sprintf("Error opening spool file for stdout %q", outpath),
 			err)
-	} else if errh, err = os.Create(errpath); err != nil {
+	} else if merge {
+		errc = outc
+	} else if errc, err = j.openSpool(errpath); err != nil {
 		return makeJobError(
-			fmt.Sprintf("Error opening spool file for stderr %q", outpath),
+			fmt.Sprintf("Error opening spool file for stderr %q", errpath),
 			err)
 	}
 
-	switch strings.ToLower(j.Options.Compress) {
-	case "", "no", "false":
-		outc = outh
-		errc = errh
-	case "gzip", "yes", "true":
-		outc = gzip.NewWriter(outh)
-		errc = gzip.NewWriter(errh)
-	default:
-		return makeJobError(
-			fmt.Sprintf("Invalid compression type %q", j.Options.Compress),
-			ErrInvalidOption)
+	// If the output is neither timestamped nor tagged, and stdout and
+	// stderr go to the same file, the process writes to it directly, so
+	// the order is preserved exactly.
+	if j.Output&(OutputTimestamps|OutputTags) != 0 {
+		var lock = new(sync.Mutex)
+
+		outc = &lineWriter{w: outc, lock: lock, mode: j.Output, tag: "out"}
+		errc = &lineWriter{w: errc, lock: lock, mode: j.Output, tag: "err"}
 	}
 
-	j.proc = exec.Command(j.Cmd[0], j.Cmd[1:]...)
+	if j.needShim() {
+		var settings []byte
+
+		if settings, err = json.Marshal(j.shimSettings()); err != nil {
+			return makeJobError("Cannot serialize Job settings", err)
+		}
+
+		var env = j.Environ()
+
+		// The shim looks up the command in the Job's PATH by itself.
+		j.proc = exec.Command(shimPath, append([]string{shimArg}, j.Cmd...)...)
+		j.proc.Env = append(env[:len(env):len(env)], shimEnv+"="+string(settings))
+	} else if j.Env != nil || len(j.EnvSet) > 0 {
+		// exec.Command would look up the command in our own PATH,
+		// but the Job's PATH might be different.
+		var (
+			path string
+			env  = j.Environ()
+		)
+
+		if path, err = lookPath(j.Cmd[0], env); err != nil {
+			return err
+		}
+
+		j.proc = exec.Command(path, j.Cmd[1:]...)
+		j.proc.Args[0] = j.Cmd[0]
+		j.proc.Env = env
+	} else {
+		j.proc = exec.Command(j.Cmd[0], j.Cmd[1:]...)
+	}
 
 	j.proc.Stdout = outc
 	j.proc.Stderr = errc
 	j.proc.Dir = j.Directory
+	// We put the Job in its own process group, so we can signal
+	// any child processes it spawns, too.
+	j.proc.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	// more stuff
 
@@ -191,17 +372,73 @@ func (j *Job) Start(outpath, errpath string) error {
 
 	j.TimeStarted = time.Now()
 	j.PID = int64(j.proc.Process.Pid)
+	j.done = make(chan struct{})
 
 	return nil
 } // func (j *Job) Start() error
 
+// watchTimeout terminates the Job once it has been running for longer than
+// its MaxDuration, and sets expired when it does. It returns nil if the Job
+// has no MaxDuration.
+func (j *Job) watchTimeout(expired *atomic.Bool) *time.Timer {
+	if j.MaxDuration <= 0 {
+		return nil
+	}
+
+	return time.AfterFunc(j.MaxDuration-time.Since(j.TimeStarted), func() {
+		expired.Store(true)
+		if err := j.Cancel(common.KillGrace); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Error terminating Job %d after %s: %s\n",
+				j.ID,
+				j.MaxDuration,
+				err.Error())
+		}
+	})
+} // func (j *Job) watchTimeout(expired *atomic.Bool) *time.Timer
+
 // Wait waits for a started Job to finish and does the post-processing.
 func (j *Job) Wait() error {
 	var err error
 
-	if j.proc == nil || j.proc.Process == nil {
+	if j.adopted {
+		return j.waitAdopted()
+	} else if j.proc == nil || j.proc.Process == nil {
 		return ErrJobNotStarted
-	} else if err = j.proc.Wait(); err != nil {
+	}
+
+	defer close(j.done)
+
+	var (
+		timer   *time.Timer
+		expired atomic.Bool
+	)
+
+	timer = j.watchTimeout(&expired)
+
+	err = j.proc.Wait()
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	// Compressed output is only complete once the compressor has been
+	// closed.
+	if cerr := j.closeSpool(); cerr != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error closing spool files of Job %d: %s\n",
+			j.ID,
+			cerr.Error())
+		if err == nil {
+			err = cerr
+		}
+	}
+
+	j.TimedOut = expired.Load()
+
+	if err != nil {
 		// Deal with it! FIXME
 		fmt.Fprintf(
 			os.Stderr,
@@ -217,6 +454,49 @@ func (j *Job) Wait() error {
 	return err
 } // func (j *Job) Wait() error
 
+// Cancel asks a running Job to terminate by sending SIGTERM to its process
+// group. If the Job is still running after the grace period has passed, it
+// is killed with SIGKILL.
+// Cancel does not reap the process, someone still has to call Wait.
+func (j *Job) Cancel(grace time.Duration) error {
+	var err error
+
+	if j.done == nil {
+		return ErrJobNotStarted
+	} else if err = j.signal(syscall.SIGTERM); err != nil {
+		return err
+	}
+
+	var t = time.NewTimer(grace)
+	defer t.Stop()
+
+	select {
+	case <-j.done:
+		return nil
+	case <-t.C:
+	}
+
+	return j.signal(syscall.SIGKILL)
+} // func (j *Job) Cancel(grace time.Duration) error
+
+func (j *Job) signal(sig syscall.Signal) error {
+	var err error
+
+	// A negative PID means the signal is sent to the process group.
+	if err = syscall.Kill(-int(j.PID), sig); err != nil {
+		if err == syscall.ESRCH {
+			// Process has exited already
+			return nil
+		}
+
+		return makeJobError(
+			fmt.Sprintf("Error sending %s to Job %d", sig, j.ID),
+			err)
+	}
+
+	return nil
+} // func (j *Job) signal(sig syscall.Signal) error
+
 // Return the Jobs ProcessState
 func (j *Job) ProcState() *os.ProcessState {
 	if j.proc == nil {
@@ -229,6 +509,16 @@ func (j *Job) ProcState() *os.ProcessState {
 func (j *Job) Status() status.Status {
 	if j.TimeSubmitted.IsZero() {
 		return status.Created
+	} else if !j.TimeCancelled.IsZero() && (j.TimeStarted.IsZero() || !j.TimeEnded.IsZero()) {
+		// A Job that was cancelled while running counts as Started
+		// until its process has actually exited.
+		return status.Cancelled
+	} else if j.Lost && !j.TimeEnded.IsZero() {
+		return status.Lost
+	} else if j.TimedOut && !j.TimeEnded.IsZero() {
+		return status.TimedOut
+	} else if j.TimeStarted.IsZero() && j.NotBefore.After(time.Now()) {
+		return status.Deferred
 	} else if j.TimeStarted.IsZero() {
 		return status.Enqueued
 	} else if j.TimeEnded.IsZero() {
@@ -237,3 +527,15 @@ func (j *Job) Status() status.Status {
 
 	return status.Finished
 } // func (j *Job) Status() status.Status
+
+// Runtime returns the time the Job has been running, or ran if it has
+// finished. For Jobs that have not been started, yet, it returns 0.
+func (j *Job) Runtime() time.Duration {
+	if j.TimeStarted.IsZero() {
+		return 0
+	} else if j.TimeEnded.IsZero() {
+		return time.Since(j.TimeStarted)
+	}
+
+	return j.TimeEnded.Sub(j.TimeStarted)
+} // func (j *Job) Runtime() time.Duration
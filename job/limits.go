@@ -0,0 +1,155 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/limits.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 17:34:12 krylon>
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// IOClass is the I/O scheduling class of a Job, see ioprio_set(2).
+type IOClass int
+
+// These constants mirror the I/O scheduling classes of the Linux kernel.
+// IOClassNone means the Job inherits the Monitor's I/O priority.
+const (
+	IOClassNone IOClass = iota
+	IOClassRealtime
+	IOClassBestEffort
+	IOClassIdle
+)
+
+// ParseIOClass converts the name of an I/O scheduling class to an IOClass.
+func ParseIOClass(s string) (IOClass, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return IOClassNone, nil
+	case "realtime", "rt":
+		return IOClassRealtime, nil
+	case "best-effort", "be":
+		return IOClassBestEffort, nil
+	case "idle":
+		return IOClassIdle, nil
+	default:
+		return IOClassNone, makeJobError(
+			fmt.Sprintf("Invalid I/O class %q", s),
+			ErrInvalidOption)
+	}
+} // func ParseIOClass(s string) (IOClass, error)
+
+// Limits describes the resource limits that are applied to a Job's process.
+// A value of zero means the respective limit is not changed, i.e. the Job
+// inherits the Monitor's limit.
+//
+// AddressSpace is the maximum size of the process' virtual memory in bytes.
+//
+// CPUSeconds is the maximum amount of CPU time in seconds.
+//
+// OpenFiles is the maximum number of open file descriptors.
+//
+// Processes is the maximum number of processes the user may have.
+type Limits struct {
+	AddressSpace int64
+	CPUSeconds   int64
+	OpenFiles    int64
+	Processes    int64
+}
+
+// IsZero returns true if none of the limits is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+} // func (l Limits) IsZero() bool
+
+// shimArg is the first argument we pass to ourselves if a Job needs to have
+// its priority or resource limits adjusted before the actual command is
+// executed.
+// shimEnv is the environment variable we pass the settings in.
+const (
+	shimArg = "__jobq_exec_shim__"
+	shimEnv = "JOBQ_SHIM_SETTINGS"
+)
+
+// shimSettings is what gets passed to the shim.
+type shimSettings struct {
+	Nice       int
+	IOClass    IOClass
+	IOPriority int
+	Limits     Limits
+}
+
+// Since the os/exec package does not allow us to run code in the child
+// process before it calls exec, we re-execute ourselves with a special
+// argument, apply the settings and then exec the actual command.
+// Doing this in an init function means it works for every binary that
+// imports this package, including the test binaries.
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == shimArg {
+		runShim(os.Args[2:])
+	}
+} // func init()
+
+func runShim(cmd []string) {
+	var (
+		err      error
+		path     string
+		settings shimSettings
+		raw      = os.Getenv(shimEnv)
+	)
+
+	// The exit codes follow the convention of the shell: 126 means the
+	// command could not be executed, 127 means it was not found.
+	if err = json.Unmarshal([]byte(raw), &settings); err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Cannot parse Job settings: %s\nRaw: %s\n",
+			err.Error(),
+			raw)
+		os.Exit(126)
+	} else if err = os.Unsetenv(shimEnv); err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Cannot remove %s from environment: %s\n",
+			shimEnv,
+			err.Error())
+		os.Exit(126)
+	} else if err = applySettings(&settings); err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Cannot apply Job settings: %s\n",
+			err.Error())
+		os.Exit(126)
+	} else if path, err = exec.LookPath(cmd[0]); err != nil {
+		fmt.Fprintf(os.Stderr,
+			"Cannot find %s: %s\n",
+			cmd[0],
+			err.Error())
+		os.Exit(127)
+	}
+
+	err = syscall.Exec(path, cmd, os.Environ())
+	fmt.Fprintf(os.Stderr,
+		"Cannot execute %s: %s\n",
+		path,
+		err.Error())
+	os.Exit(126)
+} // func runShim(cmd []string)
+
+// needShim returns true if the Job's Options require us to run the command
+// through the shim.
+func (o *Options) needShim() bool {
+	return o.Nice != 0 || o.IOClass != IOClassNone || !o.Limits.IsZero()
+} // func (o *Options) needShim() bool
+
+func (o *Options) shimSettings() shimSettings {
+	return shimSettings{
+		Nice:       o.Nice,
+		IOClass:    o.IOClass,
+		IOPriority: o.IOPriority,
+		Limits:     o.Limits,
+	}
+} // func (o *Options) shimSettings() shimSettings
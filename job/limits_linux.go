@@ -0,0 +1,88 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/limits_linux.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 17:34:40 krylon>
+
+package job
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// The syscall package does not define RLIMIT_NPROC, and we don't want to pull
+// in x/sys/unix just for that. The value is the same on all the architectures
+// we care about.
+const rlimitNproc = 0x6
+
+// See ioprio_set(2)
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+// shimPath is the path we use to re-execute ourselves.
+const shimPath = "/proc/self/exe"
+
+// applySettings applies the settings to the current process. On Linux, the
+// nice value and the I/O priority are attributes of the thread, not the
+// process, but since the init functions run on the main thread, and exec
+// is called from there as well, the new program inherits them.
+func applySettings(s *shimSettings) error {
+	var err error
+
+	var limits = []struct {
+		name     string
+		resource int
+		value    int64
+	}{
+		{"address space", syscall.RLIMIT_AS, s.Limits.AddressSpace},
+		{"CPU time", syscall.RLIMIT_CPU, s.Limits.CPUSeconds},
+		{"open files", syscall.RLIMIT_NOFILE, s.Limits.OpenFiles},
+		{"processes", rlimitNproc, s.Limits.Processes},
+	}
+
+	for _, l := range limits {
+		if l.value == 0 {
+			continue
+		}
+
+		var rlim = syscall.Rlimit{
+			Cur: uint64(l.value),
+			Max: uint64(l.value),
+		}
+
+		if err = syscall.Setrlimit(l.resource, &rlim); err != nil {
+			return fmt.Errorf("Cannot set limit for %s to %d: %w",
+				l.name,
+				l.value,
+				err)
+		}
+	}
+
+	if s.Nice != 0 {
+		if err = syscall.Setpriority(syscall.PRIO_PROCESS, 0, s.Nice); err != nil {
+			return fmt.Errorf("Cannot set nice value to %d: %w",
+				s.Nice,
+				err)
+		}
+	}
+
+	if s.IOClass != IOClassNone {
+		var prio = int(s.IOClass)<<ioprioClassShift | s.IOPriority
+
+		if _, _, errno := syscall.Syscall(
+			syscall.SYS_IOPRIO_SET,
+			ioprioWhoProcess,
+			0,
+			uintptr(prio)); errno != 0 {
+			return fmt.Errorf("Cannot set I/O priority to %d/%d: %w",
+				s.IOClass,
+				s.IOPriority,
+				errno)
+		}
+	}
+
+	return nil
+} // func applySettings(s *shimSettings) error
@@ -0,0 +1,19 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/limits_other.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 17:35:02 krylon>
+
+//go:build !linux
+
+package job
+
+import "errors"
+
+// On systems other than Linux, we do not know a reliable way to re-execute
+// ourselves, so we cannot start the shim in the first place.
+const shimPath = ""
+
+func applySettings(s *shimSettings) error {
+	return errors.New("Resource limits are only supported on Linux")
+} // func applySettings(s *shimSettings) error
@@ -0,0 +1,74 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/notbefore.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 21:40:12 krylon>
+
+package job
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The formats ParseNotBefore accepts for absolute points in time, in local
+// time unless the format includes a time zone.
+var timeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseNotBefore parses a point in time at which a Job should become
+// eligible to run, like at(1) does.
+// It accepts relative times like "+2h" or "+1h30m", absolute times like
+// "2026-10-19 02:00", and times of day like "02:00", which refer to the
+// next time the clock shows that time, i.e. today or tomorrow.
+func ParseNotBefore(s string, now time.Time) (time.Time, error) {
+	var (
+		err error
+		t   time.Time
+		d   time.Duration
+	)
+
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "+") {
+		if d, err = time.ParseDuration(s[1:]); err != nil {
+			return t, makeJobError(
+				fmt.Sprintf("Cannot parse relative time %q", s),
+				err)
+		} else if d < 0 {
+			return t, makeJobError(
+				fmt.Sprintf("Relative time %q must not be negative", s),
+				ErrInvalidOption)
+		}
+
+		return now.Add(d), nil
+	}
+
+	for _, f := range timeFormats {
+		if t, err = time.ParseInLocation(f, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, err = time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		var (
+			year, month, day = now.Date()
+			today            = time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, now.Location())
+		)
+
+		if !today.After(now) {
+			return today.AddDate(0, 0, 1), nil
+		}
+
+		return today, nil
+	}
+
+	return t, makeJobError(
+		fmt.Sprintf("Cannot parse time %q", s),
+		ErrInvalidOption)
+} // func ParseNotBefore(s string, now time.Time) (time.Time, error)
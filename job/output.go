@@ -0,0 +1,147 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/output.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 16:47:09 krylon>
+
+package job
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputMode determines how the output of a Job is spooled. It is a set of
+// flags, zero means stdout and stderr go to separate files unchanged.
+type OutputMode uint8
+
+// OutputMerge means stdout and stderr are written to a single spool file,
+// SpoolOut, in the order they arrive.
+// OutputTimestamps means each line is prefixed with the time it was written.
+// OutputTags means each line is prefixed with the stream it was written to,
+// "out" or "err".
+const (
+	OutputMerge OutputMode = 1 << iota
+	OutputTimestamps
+	OutputTags
+	outputAll = OutputMerge | OutputTimestamps | OutputTags
+)
+
+var outputNames = []struct {
+	mode OutputMode
+	name string
+}{
+	{OutputMerge, "merge"},
+	{OutputTimestamps, "timestamps"},
+	{OutputTags, "tags"},
+}
+
+// ParseOutputMode parses a comma-separated list of output flags, e.g.
+// "merge,timestamps,tags".
+func ParseOutputMode(s string) (OutputMode, error) {
+	var mode OutputMode
+
+PART:
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+
+		if part == "" || part == "none" {
+			continue
+		}
+
+		for _, n := range outputNames {
+			if part == n.name {
+				mode |= n.mode
+				continue PART
+			}
+		}
+
+		return 0, makeJobError(
+			fmt.Sprintf("Invalid output mode %q", part),
+			ErrInvalidOption)
+	}
+
+	return mode, nil
+} // func ParseOutputMode(s string) (OutputMode, error)
+
+func (m OutputMode) String() string {
+	var names = make([]string, 0, len(outputNames))
+
+	for _, n := range outputNames {
+		if m&n.mode != 0 {
+			names = append(names, n.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ",")
+} // func (m OutputMode) String() string
+
+// stampFormat is the format of the timestamps in the output of Jobs with
+// OutputTimestamps.
+const stampFormat = "2006-01-02 15:04:05.000"
+
+// lineWriter prefixes each line written to it with a timestamp and/or a
+// tag, according to the OutputMode. If several lineWriters share the same
+// underlying Writer, they must share the lock, too, so lines do not get
+// mixed up.
+type lineWriter struct {
+	w       io.Writer
+	lock    *sync.Mutex
+	mode    OutputMode
+	tag     string
+	midLine bool
+}
+
+func (lw *lineWriter) prefix() []byte {
+	var buf []byte
+
+	if lw.mode&OutputTimestamps != 0 {
+		buf = time.Now().AppendFormat(buf, stampFormat)
+		buf = append(buf, ' ')
+	}
+
+	if lw.mode&OutputTags != 0 {
+		buf = append(buf, lw.tag...)
+		buf = append(buf, ' ')
+	}
+
+	return buf
+} // func (lw *lineWriter) prefix() []byte
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	var buf = make([]byte, 0, len(p)+32)
+
+	for rest := p; len(rest) > 0; {
+		var line []byte
+
+		if idx := bytes.IndexByte(rest, '\n'); idx >= 0 {
+			line, rest = rest[:idx+1], rest[idx+1:]
+		} else {
+			line, rest = rest, nil
+		}
+
+		if !lw.midLine {
+			buf = append(buf, lw.prefix()...)
+		}
+
+		buf = append(buf, line...)
+		lw.midLine = line[len(line)-1] != '\n'
+	}
+
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	if _, err := lw.w.Write(buf); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+} // func (lw *lineWriter) Write(p []byte) (int, error)
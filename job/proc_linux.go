@@ -0,0 +1,96 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/proc_linux.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 10:31:05 krylon>
+
+package job
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the unit of the times in /proc/<pid>/stat. The kernel always
+// reports them in units of USER_HZ, which is 100 on all the architectures we
+// care about.
+const clockTicks = 100
+
+// startSlack is how far the start time of a Job's process may be off from the
+// recorded start time of the Job. We record the time in whole seconds, the
+// Job is claimed a moment before its process is started, and the boot time
+// the kernel reports may drift a little.
+const startSlack = 10 * time.Second
+
+// processAlive returns true if the Job's process is still running. To make
+// sure the PID has not been reused, the process must lead its own process
+// group (we start all Jobs that way), and it must have been started at the
+// same time as the Job.
+// We do not look at the process' command line: a script shows up as its
+// interpreter, and programs may rewrite their argv.
+func (j *Job) processAlive() bool {
+	var (
+		err      error
+		raw      []byte
+		fields   []string
+		pgrp     int64
+		ticks    int64
+		boot     int64
+		statPath = fmt.Sprintf("/proc/%d/stat", j.PID)
+	)
+
+	if raw, err = os.ReadFile(statPath); err != nil {
+		return false
+	}
+
+	// The second field is the command name in parentheses, which may
+	// contain spaces and parentheses itself, so we look for the last
+	// closing parenthesis.
+	if idx := bytes.LastIndexByte(raw, ')'); idx == -1 {
+		return false
+	} else if fields = strings.Fields(string(raw[idx+1:])); len(fields) < 20 {
+		return false
+	} else if fields[0] == "Z" || fields[0] == "X" {
+		// The process has exited, its parent just has not reaped it,
+		// yet.
+		return false
+	} else if pgrp, err = strconv.ParseInt(fields[2], 10, 64); err != nil || pgrp != j.PID {
+		return false
+	} else if ticks, err = strconv.ParseInt(fields[19], 10, 64); err != nil {
+		return false
+	} else if boot, err = bootTime(); err != nil {
+		return false
+	}
+
+	var (
+		started = time.Unix(boot, 0).Add(time.Duration(ticks) * time.Second / clockTicks)
+		offset  = started.Sub(j.TimeStarted)
+	)
+
+	return offset > -startSlack && offset < startSlack
+} // func (j *Job) processAlive() bool
+
+// bootTime returns the time the system was booted, in seconds since the
+// epoch.
+func bootTime() (int64, error) {
+	var (
+		err error
+		raw []byte
+	)
+
+	if raw, err = os.ReadFile("/proc/stat"); err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			return strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+		}
+	}
+
+	return 0, fmt.Errorf("Did not find boot time in /proc/stat")
+} // func bootTime() (int64, error)
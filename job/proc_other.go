@@ -0,0 +1,16 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/proc_other.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 10:33:18 krylon>
+
+//go:build !linux
+
+package job
+
+// processAlive returns true if the Job's process is still running.
+// On systems other than Linux, we have no reliable way to tell if a PID
+// still belongs to the Job's process, so we assume it is gone.
+func (j *Job) processAlive() bool {
+	return false
+} // func (j *Job) processAlive() bool
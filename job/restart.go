@@ -0,0 +1,117 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/restart.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 10:12:47 krylon>
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RestartPolicy determines what happens to a Job that was running when the
+// Monitor went away, if its process is gone by the time the Monitor comes
+// back. Jobs whose process is still running are adopted in either case.
+type RestartPolicy uint8
+
+// RestartNever means the Job is marked as lost.
+// RestartRequeue means the Job is put back into the queue and run again.
+const (
+	RestartNever RestartPolicy = iota
+	RestartRequeue
+)
+
+var restartNames = map[RestartPolicy]string{
+	RestartNever:   "never",
+	RestartRequeue: "requeue",
+}
+
+func (r RestartPolicy) String() string {
+	if name, ok := restartNames[r]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("RestartPolicy(%d)", r)
+} // func (r RestartPolicy) String() string
+
+// ParseRestartPolicy converts the name of a RestartPolicy to its value.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch strings.ToLower(s) {
+	case "", "never", "lost":
+		return RestartNever, nil
+	case "requeue":
+		return RestartRequeue, nil
+	default:
+		return RestartNever, makeJobError(
+			fmt.Sprintf("Invalid restart policy %q", s),
+			ErrInvalidOption)
+	}
+} // func ParseRestartPolicy(s string) (RestartPolicy, error)
+
+// ErrProcessGone indicates that the process of a Job that was started by a
+// previous instance of the Monitor no longer exists, or that its PID now
+// belongs to a different process.
+var ErrProcessGone = errors.New("Process is gone")
+
+// adoptPoll is the interval at which we check if an adopted process is still
+// running. Since it is not our child, we cannot wait for it.
+const adoptPoll = time.Second
+
+// Adopt takes over a Job that was started by a previous instance of the
+// Monitor, using the PID and TimeStarted from the database. It returns
+// ErrProcessGone if the process is no longer running.
+//
+// An adopted Job can be waited for and cancelled like any other Job, but its
+// exit code cannot be determined.
+func (j *Job) Adopt() error {
+	if j.proc != nil || j.done != nil {
+		return ErrJobStarted
+	} else if j.PID <= 0 || j.TimeStarted.IsZero() {
+		return ErrJobNotStarted
+	} else if !j.processAlive() {
+		return makeJobError(
+			fmt.Sprintf("Cannot adopt Job %d (PID %d)", j.ID, j.PID),
+			ErrProcessGone)
+	}
+
+	j.adopted = true
+	j.done = make(chan struct{})
+
+	return nil
+} // func (j *Job) Adopt() error
+
+// waitAdopted is Wait for adopted Jobs. We poll the process until it is gone
+// and set the ExitCode to -1, because only the process' parent gets to know
+// its exit status.
+func (j *Job) waitAdopted() error {
+	defer close(j.done)
+
+	var (
+		timer   *time.Timer
+		expired atomic.Bool
+		ticker  = time.NewTicker(adoptPoll)
+	)
+
+	defer ticker.Stop()
+
+	timer = j.watchTimeout(&expired)
+
+	for j.processAlive() {
+		<-ticker.C
+	}
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	j.TimedOut = expired.Load()
+	j.TimeEnded = time.Now()
+	j.ExitCode = -1
+
+	return nil
+} // func (j *Job) waitAdopted() error
@@ -0,0 +1,120 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/retry.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 13:05:22 krylon>
+
+package job
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy describes if and when a Job that failed is run again.
+//
+// MaxAttempts is the maximum number of times the Job is run, including the
+// first attempt. Zero or one means the Job is not retried.
+//
+// Backoff is how long we wait before the second attempt, the delay doubles
+// with each further attempt, up to MaxBackoff, if that is set.
+//
+// ExitCodes is the list of exit codes that warrant another attempt. If it is
+// empty, the Job is retried on any failure, including a timeout.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	ExitCodes   []int `json:",omitempty"`
+}
+
+// Validate checks the RetryPolicy for invalid values.
+func (r *RetryPolicy) Validate() error {
+	if r.MaxAttempts < 0 {
+		return makeJobError(
+			fmt.Sprintf("Maximum number of attempts must not be negative: %d", r.MaxAttempts),
+			ErrInvalidOption)
+	} else if r.Backoff < 0 || r.MaxBackoff < 0 {
+		return makeJobError(
+			fmt.Sprintf("Backoff must not be negative: %s / %s", r.Backoff, r.MaxBackoff),
+			ErrInvalidOption)
+	}
+
+	for _, code := range r.ExitCodes {
+		if code < 1 || code > 255 {
+			return makeJobError(
+				fmt.Sprintf("Invalid exit code %d for retrying, must be between 1 and 255", code),
+				ErrInvalidOption)
+		}
+	}
+
+	return nil
+} // func (r *RetryPolicy) Validate() error
+
+// Delay returns how long to wait before the next attempt, after the given
+// number of attempts have failed.
+func (r *RetryPolicy) Delay(attempts int) time.Duration {
+	var d = r.Backoff
+
+	for i := 1; i < attempts && d > 0; i++ {
+		if r.MaxBackoff > 0 && d >= r.MaxBackoff {
+			break
+		} else if d > d<<1 {
+			// Overflow, practically forever.
+			break
+		}
+		d <<= 1
+	}
+
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+
+	return d
+} // func (r *RetryPolicy) Delay(attempts int) time.Duration
+
+// ParseExitCodes parses a comma-separated list of exit codes, e.g. "1,75".
+func ParseExitCodes(s string) ([]int, error) {
+	var codes []int
+
+	for _, f := range strings.Split(s, ",") {
+		var (
+			err  error
+			code int
+		)
+
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		} else if code, err = strconv.Atoi(f); err != nil {
+			return nil, makeJobError(
+				fmt.Sprintf("Invalid exit code %q", f),
+				ErrInvalidOption)
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+} // func ParseExitCodes(s string) ([]int, error)
+
+// Retryable returns true if the Job's last attempt failed in a way that
+// warrants another attempt, according to its RetryPolicy.
+func (j *Job) Retryable() bool {
+	if j.Attempts >= j.Retry.MaxAttempts || !j.TimeCancelled.IsZero() {
+		return false
+	} else if j.ExitCode == 0 && !j.TimedOut {
+		return false
+	} else if len(j.Retry.ExitCodes) == 0 {
+		return true
+	}
+
+	for _, code := range j.Retry.ExitCodes {
+		if code == j.ExitCode {
+			return true
+		}
+	}
+
+	return false
+} // func (j *Job) Retryable() bool
@@ -0,0 +1,32 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/run.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 13:11:40 krylon>
+
+package job
+
+import "time"
+
+// Run is the record of a single attempt to run a Job.
+// Seq is the number of the attempt, starting at 1. The other fields have the
+// same meaning as those of the Job. TimeEnded is zero and ExitCode is -1 while
+// the attempt is running.
+// Compress is the compression type of the spool files, see
+// Options.Compression.
+type Run struct {
+	Seq         int
+	TimeStarted time.Time
+	TimeEnded   time.Time
+	ExitCode    int
+	TimedOut    bool
+	PID         int64
+	SpoolOut    string
+	SpoolErr    string
+	Compress    string
+}
+
+// Running returns true if the attempt has not ended, yet.
+func (r *Run) Running() bool {
+	return r.TimeEnded.IsZero()
+} // func (r *Run) Running() bool
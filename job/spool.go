@@ -0,0 +1,213 @@
+// /home/krylon/go/src/github.com/blicero/jobq/job/spool.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 09:14:26 krylon>
+
+package job
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The compression types for spool files. The empty string means the output
+// is not compressed.
+const (
+	CompressNone = ""
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// Compression returns the normalized compression type of the Job's output,
+// one of CompressNone, CompressGzip or CompressZstd.
+func (o *Options) Compression() string {
+	switch strings.ToLower(o.Compress) {
+	case "gzip", "yes", "true":
+		return CompressGzip
+	case "zstd":
+		return CompressZstd
+	default:
+		return CompressNone
+	}
+} // func (o *Options) Compression() string
+
+// SpoolSuffix returns the suffix for the names of the Job's spool files that
+// indicates how they are compressed, e.g. ".gz".
+func (o *Options) SpoolSuffix() string {
+	switch o.Compression() {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+} // func (o *Options) SpoolSuffix() string
+
+// NewSpoolReader returns a Reader that decompresses the content of a spool
+// file read from r, according to the compression type, see Compression.
+// If the spool file is empty, because the Job has not written anything yet,
+// there is not even a header to read, so we return an empty Reader.
+func NewSpoolReader(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case CompressNone:
+		return io.NopCloser(r), nil
+	case CompressGzip:
+		var gz, err = gzip.NewReader(r)
+
+		if err == io.EOF {
+			return io.NopCloser(strings.NewReader("")), nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		return gz, nil
+	case CompressZstd:
+		var zr, err = zstd.NewReader(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, makeJobError(
+			fmt.Sprintf("Invalid compression type %q", compression),
+			ErrInvalidOption)
+	}
+} // func NewSpoolReader(r io.Reader, compression string) (io.ReadCloser, error)
+
+// spoolFlush is how often the compressed output of a running Job is flushed
+// to its spool file. Compressors buffer their output, and without flushing,
+// nobody could follow the output until the Job is done.
+const spoolFlush = time.Second
+
+// compressor is what gzip and zstd writers have in common.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// flushWriter flushes a compressor periodically, if anything has been written
+// to it since the last time, see spoolFlush. Flushing after every Write would
+// make the compression a lot less effective for Jobs that write output in
+// small pieces.
+type flushWriter struct {
+	lock  sync.Mutex
+	w     compressor
+	dirty bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func newFlushWriter(w compressor) *flushWriter {
+	var fw = &flushWriter{
+		w:    w,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go fw.loop()
+
+	return fw
+} // func newFlushWriter(w compressor) *flushWriter
+
+func (fw *flushWriter) loop() {
+	defer close(fw.done)
+
+	var ticker = time.NewTicker(spoolFlush)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fw.stop:
+			return
+		case <-ticker.C:
+			fw.lock.Lock()
+			if fw.dirty {
+				fw.w.Flush() // nolint: errcheck
+				fw.dirty = false
+			}
+			fw.lock.Unlock()
+		}
+	}
+} // func (fw *flushWriter) loop()
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	fw.lock.Lock()
+	defer fw.lock.Unlock()
+
+	fw.dirty = true
+	return fw.w.Write(p)
+} // func (fw *flushWriter) Write(p []byte) (int, error)
+
+// Close stops the periodic flushing and closes the compressor, which flushes
+// whatever is left.
+func (fw *flushWriter) Close() error {
+	close(fw.stop)
+	<-fw.done
+
+	fw.lock.Lock()
+	defer fw.lock.Unlock()
+
+	return fw.w.Close()
+} // func (fw *flushWriter) Close() error
+
+// openSpool creates a spool file and returns a Writer for it that
+// compresses the output as requested. The file and the compressor are
+// remembered, so closeSpool can flush and close them once the Job is done.
+// While the Job runs, compressed output is flushed periodically, see
+// spoolFlush.
+func (j *Job) openSpool(path string) (io.Writer, error) {
+	var (
+		err error
+		fh  *os.File
+		w   compressor
+	)
+
+	if fh, err = os.Create(path); err != nil {
+		return nil, err
+	}
+
+	switch j.Compression() {
+	case CompressGzip:
+		w = gzip.NewWriter(fh)
+	case CompressZstd:
+		if w, err = zstd.NewWriter(fh); err != nil {
+			fh.Close() // nolint: errcheck
+			return nil, err
+		}
+	default:
+		j.spool = append(j.spool, fh)
+		return fh, nil
+	}
+
+	// The compressor needs to be closed before the file.
+	var fw = newFlushWriter(w)
+	j.spool = append(j.spool, fw, fh)
+	return fw, nil
+} // func (j *Job) openSpool(path string) (io.Writer, error)
+
+// closeSpool flushes and closes the spool files and their compressors, if
+// any. It returns the first error it encounters, but closes everything
+// regardless.
+func (j *Job) closeSpool() error {
+	var err error
+
+	for _, c := range j.spool {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	j.spool = nil
+	return err
+} // func (j *Job) closeSpool() error
@@ -18,4 +18,19 @@ const (
 	Enqueued
 	Started
 	Finished
+	Cancelled
+	TimedOut
+	Deferred
+	Lost
 )
+
+// Final returns true if a Job with this Status is done, i.e. its Status will
+// not change anymore.
+func (s Status) Final() bool {
+	switch s {
+	case Finished, Cancelled, TimedOut, Lost:
+		return true
+	default:
+		return false
+	}
+} // func (s Status) Final() bool
@@ -21,6 +21,7 @@ const (
 	DBPool
 	Monitor
 	CLI
+	Client
 )
 
 // AllDomains returns a slice of all the valid values for ID.
@@ -32,5 +33,6 @@ func AllDomains() []ID {
 		DBPool,
 		Monitor,
 		CLI,
+		Client,
 	}
 } // func AllDomains() []ID
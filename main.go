@@ -15,7 +15,9 @@ import (
 )
 
 func main() {
-	fmt.Printf("%s %s, built on %s\n",
+	// The banner goes to stderr, so it does not end up in the output of
+	// e.g. id=$(jobq -- make) or jobq -sync.
+	fmt.Fprintf(os.Stderr, "%s %s, built on %s\n",
 		common.AppName,
 		common.Version,
 		common.BuildStamp.Format(common.TimestampFormat))
@@ -33,5 +35,5 @@ func main() {
 		os.Exit(1)
 	}
 
-	shell.Execute()
+	os.Exit(shell.Execute())
 }
@@ -7,19 +7,16 @@
 package monitor
 
 import (
-	"encoding/json"
 	"fmt"
-	"net"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/blicero/jobq/job"
-	"github.com/blicero/jobq/monitor/request"
 	"github.com/davecgh/go-spew/spew"
 )
 
-const netname = "unixpacket"
+const testSlots = 3
 
 var directories = []string{
 	"/etc",
@@ -42,7 +39,7 @@ func TestMonCreate(t *testing.T) {
 
 	socketPath = path
 
-	if mon, err = Create(name, path, 1); err != nil {
+	if mon, err = Create(name, path, testSlots); err != nil {
 		mon = nil
 		t.Fatalf("Cannot create Monitor: %s", err.Error())
 	}
@@ -52,18 +49,11 @@ func TestMonCreate(t *testing.T) {
 
 func TestMonSubmit(t *testing.T) {
 	var (
-		err    error
-		raddr  net.UnixAddr
-		conn   *net.UnixConn
-		rcvbuf = make([]byte, 65536)
+		err  error
+		conn *Conn
 	)
 
-	raddr = net.UnixAddr{
-		Net:  netname,
-		Name: socketPath,
-	}
-
-	if conn, err = net.DialUnix(netname, nil, &raddr); err != nil {
+	if conn, err = Dial(socketPath); err != nil {
 		t.Fatalf("Error connecting to Monitor %s: %s",
 			socketPath,
 			err.Error())
@@ -73,11 +63,9 @@ func TestMonSubmit(t *testing.T) {
 
 	for _, d := range directories {
 		var (
-			buf []byte
 			j   *job.Job
 			msg Message
-			res Response
-			cnt int
+			res *Response
 			opt = job.Options{
 				Directory: d,
 				Compress:  "yes",
@@ -89,24 +77,14 @@ func TestMonSubmit(t *testing.T) {
 			continue
 		}
 
-		msg = MakeMsg(request.JobSubmit.String(), j)
+		msg = MakeMsg(&JobSubmitArgs{Job: j})
 
-		if buf, err = json.Marshal(&msg); err != nil {
-			t.Fatalf("Cannot serialize Job: %s",
-				err.Error())
-		} else if _, err = conn.Write(buf); err != nil {
-			t.Errorf("Cannot send JSON payload to Monitor: %s",
+		if res, err = conn.Request(&msg); err != nil {
+			t.Errorf("Cannot talk to Monitor: %s",
 				err.Error())
-		} else if cnt, err = conn.Read(rcvbuf); err != nil {
-			t.Errorf("Cannot receive reply from Monitor: %s",
-				err.Error())
-		} else if err = json.Unmarshal(rcvbuf[:cnt], &res); err != nil {
-			t.Errorf("Failed to decode Response: %s\n\n%s",
-				err.Error(),
-				string(rcvbuf[:cnt]))
 		} else {
 			t.Logf("Received Response from Monitor: %s",
-				spew.Sdump(&res))
+				spew.Sdump(res))
 		}
 	}
 
@@ -115,22 +93,13 @@ func TestMonSubmit(t *testing.T) {
 
 func TestMonQuery(t *testing.T) {
 	var (
-		err    error
-		raddr  net.UnixAddr
-		conn   *net.UnixConn
-		rcvbuf = make([]byte, 65536)
-		sndbuf []byte
-		cnt    int
-		msg    Message
-		res    Response
+		err  error
+		conn *Conn
+		msg  Message
+		res  *Response
 	)
 
-	raddr = net.UnixAddr{
-		Net:  netname,
-		Name: socketPath,
-	}
-
-	if conn, err = net.DialUnix(netname, nil, &raddr); err != nil {
+	if conn, err = Dial(socketPath); err != nil {
 		t.Fatalf("Error connecting to Monitor %s: %s",
 			socketPath,
 			err.Error())
@@ -138,24 +107,45 @@ func TestMonQuery(t *testing.T) {
 
 	defer conn.Close() // nolint: errcheck
 
-	msg = MakeMsg(request.QueueQueryStatus.String(), nil)
+	msg = MakeMsg(&QueueQueryStatusArgs{})
 
-	if sndbuf, err = json.Marshal(&msg); err != nil {
-		t.Fatalf("Cannot serialize Job: %s",
-			err.Error())
-	} else if _, err = conn.Write(sndbuf); err != nil {
-		t.Errorf("Cannot send JSON payload to Monitor: %s",
-			err.Error())
-	} else if cnt, err = conn.Read(rcvbuf); err != nil {
-		t.Errorf("Cannot receive reply from Monitor: %s",
+	if res, err = conn.Request(&msg); err != nil {
+		t.Errorf("Cannot talk to Monitor: %s",
 			err.Error())
-	} else if err = json.Unmarshal(rcvbuf[:cnt], &res); err != nil {
-		t.Errorf("Failed to decode Response: %s\n\n%s",
-			err.Error(),
-			string(rcvbuf[:cnt]))
 	} else if len(res.Jobs) != len(directories) {
 		t.Errorf("Unexpected number of jobs: %d Expected %d",
 			len(res.Jobs),
 			len(directories))
 	}
 } // func TestMonQuery(t *testing.T)
+
+func TestMonSubmitInvalid(t *testing.T) {
+	var (
+		err     error
+		conn    *Conn
+		invalid = []*job.Job{
+			nil,
+			{Options: job.Options{}},
+			{Options: job.Options{Nice: 42}, Cmd: []string{"/bin/true"}},
+			{Options: job.Options{Compress: "rar"}, Cmd: []string{"/bin/true"}},
+		}
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for idx, j := range invalid {
+		var res = sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j}))
+
+		if !res.Error {
+			t.Errorf("Monitor accepted invalid Job %d: %s",
+				idx,
+				res.Status)
+		}
+	}
+} // func TestMonSubmitInvalid(t *testing.T)
@@ -0,0 +1,96 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/02_monitor_parallel_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 14:12:31 krylon>
+
+package monitor
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// sendMsg sends a single Message to the Monitor and returns its Response.
+func sendMsg(t *testing.T, conn *Conn, msg Message) Response {
+	var (
+		err error
+		res *Response
+	)
+
+	if res, err = conn.Request(&msg); err != nil {
+		t.Fatalf("Cannot talk to Monitor: %s",
+			err.Error())
+	}
+
+	return *res
+} // func sendMsg(t *testing.T, conn *Conn, msg Message) Response
+
+func TestMonParallel(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const sleep = 3
+	var (
+		err  error
+		conn *Conn
+		ids  = make(map[int64]bool, testSlots)
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for i := 0; i < testSlots; i++ {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{}, "/bin/sleep", strconv.Itoa(sleep)); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j}))
+	}
+
+	time.Sleep(time.Second * (sleep + 2))
+
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+
+	var (
+		lastStart, firstEnd time.Time
+	)
+
+	for _, j := range res.Jobs {
+		if len(j.Cmd) == 0 || j.Cmd[0] != "/bin/sleep" {
+			continue
+		} else if j.TimeEnded.IsZero() {
+			t.Fatalf("Job %d has not finished, yet", j.ID)
+		}
+
+		ids[j.ID] = true
+
+		if j.TimeStarted.After(lastStart) {
+			lastStart = j.TimeStarted
+		}
+		if firstEnd.IsZero() || j.TimeEnded.Before(firstEnd) {
+			firstEnd = j.TimeEnded
+		}
+	}
+
+	if len(ids) != testSlots {
+		t.Fatalf("Unexpected number of Jobs: %d (expected %d)",
+			len(ids),
+			testSlots)
+	} else if !lastStart.Before(firstEnd) {
+		t.Errorf("Jobs did not run in parallel: last Job started at %s, first Job ended at %s",
+			lastStart.Format(time.TimeOnly),
+			firstEnd.Format(time.TimeOnly))
+	}
+} // func TestMonParallel(t *testing.T)
@@ -0,0 +1,107 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/03_monitor_cancel_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 15:02:47 krylon>
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+const cancelSleep = "600"
+
+// getSleepers returns the Jobs that were submitted by TestMonCancel.
+func getSleepers(t *testing.T, conn *Conn) []job.Job {
+	var (
+		res  = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+		jobs = make([]job.Job, 0, testSlots+1)
+	)
+
+	for _, j := range res.Jobs {
+		if len(j.Cmd) == 2 && j.Cmd[0] == "/bin/sleep" && j.Cmd[1] == cancelSleep {
+			jobs = append(jobs, j)
+		}
+	}
+
+	return jobs
+} // func getSleepers(t *testing.T, conn *Conn) []job.Job
+
+func TestMonCancel(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	// We submit one Job more than we have slots, so one of them remains
+	// in the queue.
+	for i := 0; i <= testSlots; i++ {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{}, "/bin/sleep", cancelSleep); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j}))
+	}
+
+	time.Sleep(time.Second)
+
+	var (
+		running, pending int
+		jobs             = getSleepers(t, conn)
+	)
+
+	for _, j := range jobs {
+		switch j.Status() {
+		case status.Started:
+			running++
+		case status.Enqueued:
+			pending++
+		}
+	}
+
+	if running != testSlots || pending != 1 {
+		t.Fatalf("Expected %d running and 1 pending Job, got %d running and %d pending",
+			testSlots,
+			running,
+			pending)
+	}
+
+	for _, j := range jobs {
+		var res = sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: j.ID}))
+
+		t.Logf("Cancel Job %d: %s", j.ID, res.Status)
+	}
+
+	time.Sleep(time.Second * 2)
+
+	for _, j := range getSleepers(t, conn) {
+		if s := j.Status(); s != status.Cancelled {
+			t.Errorf("Unexpected status for Job %d: %s (expected %s)",
+				j.ID,
+				s,
+				status.Cancelled)
+		} else if !j.TimeStarted.IsZero() && j.TimeEnded.IsZero() {
+			t.Errorf("Job %d was cancelled, but has not ended",
+				j.ID)
+		}
+	}
+} // func TestMonCancel(t *testing.T)
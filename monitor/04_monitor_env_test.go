@@ -0,0 +1,149 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/04_monitor_env_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 19:56:03 krylon>
+
+package monitor
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+func TestMonEnv(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const expect = "42\n"
+
+	var (
+		err    error
+		conn   *Conn
+		j      *job.Job
+		res    Response
+		buf    []byte
+		filter = job.EnvFilter{
+			Mode: job.EnvFull,
+			Deny: []string{"HOME"},
+		}
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	// The variable is only set in the submitter's environment, so if the
+	// Job sees it, it must have gotten it from the Job, not from the
+	// Monitor.
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", "echo $JOBQ_SUBMITTER$HOME"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if j.Env, err = filter.Apply(append(os.Environ(), "JOBQ_SUBMITTER=42")); err != nil {
+		t.Fatalf("Cannot prepare environment: %s", err.Error())
+	}
+
+	res = sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j}))
+	if res.Error || len(res.Jobs) != 1 {
+		t.Fatalf("Failed to submit Job: %s", res.Status)
+	}
+
+	var id = res.Jobs[0].ID
+
+	for i := 0; i < 20; i++ {
+		time.Sleep(time.Millisecond * 250)
+		res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+		for _, x := range res.Jobs {
+			if x.ID == id && x.Status() == status.Finished {
+				j = &x
+				goto CHECK
+			}
+		}
+	}
+
+	t.Fatalf("Job %d did not finish in time", id)
+
+CHECK:
+	if j.ExitCode != 0 {
+		t.Errorf("Job %d failed with exit code %d",
+			id,
+			j.ExitCode)
+	} else if buf, err = os.ReadFile(j.SpoolOut); err != nil {
+		t.Fatalf("Cannot read spool file %s: %s",
+			j.SpoolOut,
+			err.Error())
+	} else if string(buf) != expect {
+		t.Errorf("Unexpected output from Job %d:\nExpected: %q\nActual:   %q",
+			id,
+			expect,
+			buf)
+	}
+} // func TestMonEnv(t *testing.T)
+
+func TestMonEnvInherit(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const expect = "|monitor|1\n"
+
+	var (
+		err    error
+		conn   *Conn
+		j      *job.Job
+		buf    []byte
+		filter = job.EnvFilter{
+			Mode: job.EnvInherit,
+			Set:  []string{"JOBQ_SET=1"},
+		}
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	// The Monitor runs in our process, so the variable we set here is
+	// part of its environment.
+	os.Setenv("JOBQ_MONITOR", "monitor") // nolint: errcheck
+	defer os.Unsetenv("JOBQ_MONITOR")    // nolint: errcheck
+
+	// JOBQ_SUBMITTER is only in the submitter's environment, so it must
+	// not reach the Job.
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", "echo \"$JOBQ_SUBMITTER|$JOBQ_MONITOR|$JOBQ_SET\""); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if j.Env, err = filter.Apply(append(os.Environ(), "JOBQ_SUBMITTER=42")); err != nil {
+		t.Fatalf("Cannot prepare environment: %s", err.Error())
+	} else if j.Env != nil {
+		t.Fatalf("A Job inheriting the Monitor's environment should not have an Env: %v", j.Env)
+	}
+
+	j.EnvSet = filter.Inherited()
+	j = waitJob(t, conn, submitJob(t, conn, j), time.Second*10)
+
+	if j.ExitCode != 0 {
+		t.Errorf("Job %d failed with exit code %d",
+			j.ID,
+			j.ExitCode)
+	} else if buf, err = os.ReadFile(j.SpoolOut); err != nil {
+		t.Fatalf("Cannot read spool file %s: %s",
+			j.SpoolOut,
+			err.Error())
+	} else if string(buf) != expect {
+		t.Errorf("Unexpected output from Job %d:\nExpected: %q\nActual:   %q",
+			j.ID,
+			expect,
+			buf)
+	}
+} // func TestMonEnvInherit(t *testing.T)
@@ -0,0 +1,132 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/05_monitor_depend_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 20:55:34 krylon>
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// submitJob submits a Job to the Monitor and returns its ID.
+func submitJob(t *testing.T, conn *Conn, j *job.Job) int64 {
+	var res = sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j}))
+
+	if res.Error || len(res.Jobs) != 1 {
+		t.Fatalf("Failed to submit Job: %s", res.Status)
+	}
+
+	return res.Jobs[0].ID
+} // func submitJob(t *testing.T, conn *Conn, j *job.Job) int64
+
+func TestMonDepend(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err        error
+		conn       *Conn
+		a, b, c, d *job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if a, err = job.New(job.Options{}, "/bin/sh", "-c", "sleep 1; exit 3"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if b, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if c, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if d, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	a.ID = submitJob(t, conn, a)
+	b.Depends = []job.Dependency{{ID: a.ID, Condition: job.AfterOK}}
+	b.ID = submitJob(t, conn, b)
+	c.Depends = []job.Dependency{{ID: a.ID, Condition: job.AfterNotOK}}
+	c.ID = submitJob(t, conn, c)
+	d.Depends = []job.Dependency{{ID: b.ID, Condition: job.AfterOK}}
+	d.ID = submitJob(t, conn, d)
+
+	var expect = map[int64]status.Status{
+		a.ID: status.Finished,
+		b.ID: status.Cancelled,
+		c.ID: status.Finished,
+		d.ID: status.Cancelled,
+	}
+
+	for i := 0; i < 40; i++ {
+		var (
+			done int
+			res  = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+		)
+
+		for _, j := range res.Jobs {
+			if s, ok := expect[j.ID]; ok && j.Status() == s {
+				done++
+			}
+		}
+
+		if done == len(expect) {
+			return
+		}
+
+		time.Sleep(time.Millisecond * 250)
+	}
+
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+
+	for _, j := range res.Jobs {
+		if s, ok := expect[j.ID]; ok && j.Status() != s {
+			t.Errorf("Unexpected status for Job %d: %s (expected %s)",
+				j.ID,
+				j.Status(),
+				s)
+		}
+	}
+} // func TestMonDepend(t *testing.T)
+
+func TestMonDependInvalid(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+		res  Response
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.Depends = []job.Dependency{{ID: 1 << 40}}
+
+	if res = sendMsg(t, conn, MakeMsg(&JobSubmitArgs{Job: j})); !res.Error {
+		t.Errorf("Submitting a Job that depends on a non-existent Job should fail")
+	}
+} // func TestMonDependInvalid(t *testing.T)
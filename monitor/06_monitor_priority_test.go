@@ -0,0 +1,68 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/06_monitor_priority_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 21:26:40 krylon>
+
+package monitor
+
+import (
+	"testing"
+
+	"github.com/blicero/jobq/job"
+)
+
+func TestMonSetPriority(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+		a, b *job.Job
+		res  Response
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	// b waits for a, so it stays pending long enough for us to change
+	// its priority.
+	if a, err = job.New(job.Options{}, "/bin/sleep", "2"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if b, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	a.ID = submitJob(t, conn, a)
+	b.Depends = []job.Dependency{{ID: a.ID, Condition: job.AfterAny}}
+	b.ID = submitJob(t, conn, b)
+
+	if res = sendMsg(t, conn, MakeMsg(&JobSetPriorityArgs{JobID: b.ID, Priority: 7})); res.Error {
+		t.Errorf("Failed to set priority of Job %d: %s",
+			b.ID,
+			res.Status)
+	} else if len(res.Jobs) != 1 || res.Jobs[0].Priority != 7 {
+		t.Errorf("Response should contain Job %d with priority 7: %#v",
+			b.ID,
+			res.Jobs)
+	}
+
+	var invalid = []JobSetPriorityArgs{
+		{},
+		{JobID: -1, Priority: 1},
+		{JobID: 1 << 40, Priority: 1},
+	}
+
+	for _, args := range invalid {
+		if res = sendMsg(t, conn, MakeMsg(&args)); !res.Error {
+			t.Errorf("Request %#v should have failed", args)
+		}
+	}
+} // func TestMonSetPriority(t *testing.T)
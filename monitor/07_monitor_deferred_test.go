@@ -0,0 +1,77 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/07_monitor_deferred_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 22:11:37 krylon>
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// jobStatus returns the status of the Job with the given ID.
+func jobStatus(t *testing.T, conn *Conn, id int64) status.Status {
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+
+	for _, j := range res.Jobs {
+		if j.ID == id {
+			return j.Status()
+		}
+	}
+
+	t.Fatalf("Job %d was not found in queue", id)
+	return status.Created
+} // func jobStatus(t *testing.T, conn *Conn, id int64) status.Status
+
+func TestMonDeferred(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+		s    status.Status
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Second * 2)
+	j.ID = submitJob(t, conn, j)
+
+	if s = jobStatus(t, conn, j.ID); s != status.Deferred {
+		t.Errorf("Unexpected status of Job %d: %s (expected %s)",
+			j.ID,
+			s,
+			status.Deferred)
+	}
+
+	// The workers' fallback ticker fires every five minutes, so if the
+	// Job finishes within a few seconds, the worker woke up on its own.
+	for i := 0; i < 20; i++ {
+		time.Sleep(time.Millisecond * 250)
+		if s = jobStatus(t, conn, j.ID); s == status.Finished {
+			return
+		}
+	}
+
+	t.Errorf("Job %d should have finished by now, its status is %s",
+		j.ID,
+		s)
+} // func TestMonDeferred(t *testing.T)
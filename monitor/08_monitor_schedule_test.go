@@ -0,0 +1,182 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/08_monitor_schedule_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 00:04:17 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/schedule"
+)
+
+func TestMonSchedule(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+		res  Response
+		sid  int64
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	var args = &ScheduleCreateArgs{
+		Schedule: &schedule.Schedule{Spec: "0 0 30 2 *", Cmd: []string{"/bin/true"}},
+	}
+
+	// Invalid Schedules are rejected.
+	if res = sendMsg(t, conn, MakeMsg(args)); !res.Error {
+		t.Errorf("Monitor should have rejected Schedule %q", args.Schedule.Spec)
+	}
+
+	args.Schedule = &schedule.Schedule{Spec: "@yearly", Cmd: []string{"/bin/true"}}
+
+	if res = sendMsg(t, conn, MakeMsg(args)); res.Error {
+		t.Fatalf("Failed to create Schedule: %s", res.Status)
+	} else if len(res.Schedules) != 1 {
+		t.Fatalf("Expected 1 Schedule in Response, got %d", len(res.Schedules))
+	} else if sid = res.Schedules[0].ID; res.Schedules[0].NextRun.Before(time.Now()) {
+		t.Errorf("Schedule %d should run in the future, not at %s",
+			sid,
+			res.Schedules[0].NextRun)
+	}
+
+	if res = sendMsg(t, conn, MakeMsg(&ScheduleListArgs{})); res.Error {
+		t.Fatalf("Failed to list Schedules: %s", res.Status)
+	} else if !hasSchedule(res.Schedules, sid) {
+		t.Errorf("Schedule %d is missing from list", sid)
+	}
+
+	type step struct {
+		args   Args
+		fail   bool
+		paused bool
+	}
+
+	var steps = []step{
+		{&SchedulePauseArgs{ScheduleID: sid}, false, true},
+		{&SchedulePauseArgs{ScheduleID: sid}, true, true},
+		{&ScheduleResumeArgs{ScheduleID: sid}, false, false},
+		{&ScheduleResumeArgs{ScheduleID: sid}, true, false},
+		{&ScheduleDeleteArgs{ScheduleID: sid}, false, false},
+		{&ScheduleDeleteArgs{ScheduleID: sid}, true, false},
+	}
+
+	for _, s := range steps {
+		var req = s.args.Request()
+
+		if res = sendMsg(t, conn, MakeMsg(s.args)); res.Error != s.fail {
+			t.Errorf("Unexpected result for %s: %s", req, res.Status)
+		} else if !s.fail && (len(res.Schedules) != 1 || res.Schedules[0].Paused != s.paused) {
+			t.Errorf("Unexpected Schedule after %s: %#v", req, res.Schedules)
+		}
+	}
+} // func TestMonSchedule(t *testing.T)
+
+func TestMonScheduleCatchUp(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		conn  *Conn
+		res   Response
+		cnt   int
+		db    = mon.pool.Get()
+		nonce = fmt.Sprintf("catchup-%d", time.Now().UnixNano())
+		hour  = time.Now().Truncate(time.Hour)
+		s     = schedule.Schedule{
+			Spec:    "@hourly",
+			Cmd:     []string{"/bin/echo", nonce},
+			Missed:  schedule.MissedCatchUp,
+			Created: hour.Add(-time.Hour * 4),
+			NextRun: hour.Add(-time.Hour * 3),
+		}
+	)
+
+	// We pretend the Monitor was down for a few hours, so the Schedule
+	// missed its last four firings.
+	err = db.ScheduleAdd(&s)
+	mon.pool.Put(db)
+
+	if err != nil {
+		t.Fatalf("Cannot add Schedule: %s", err.Error())
+	}
+
+	mon.schedTick()
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+	defer sendMsg(t, conn, MakeMsg(&ScheduleDeleteArgs{ScheduleID: s.ID}))
+
+	for i := 0; i < 20; i++ {
+		time.Sleep(time.Millisecond * 100)
+
+		res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+		if cnt = countJobs(res.Jobs, nonce); cnt >= 4 {
+			break
+		}
+	}
+
+	if cnt != 4 {
+		t.Errorf("Schedule %d should have submitted 4 Jobs, not %d",
+			s.ID,
+			cnt)
+	}
+
+	if res = sendMsg(t, conn, MakeMsg(&ScheduleListArgs{})); res.Error {
+		t.Fatalf("Failed to list Schedules: %s", res.Status)
+	}
+
+	for _, x := range res.Schedules {
+		if x.ID == s.ID && !x.NextRun.After(time.Now()) {
+			t.Errorf("Schedule %d should run next in the future, not at %s",
+				x.ID,
+				x.NextRun)
+		}
+	}
+} // func TestMonScheduleCatchUp(t *testing.T)
+
+func hasSchedule(list []schedule.Schedule, id int64) bool {
+	for _, s := range list {
+		if s.ID == id {
+			return true
+		}
+	}
+
+	return false
+} // func hasSchedule(list []schedule.Schedule, id int64) bool
+
+// countJobs counts the Jobs whose last argument is tag.
+func countJobs(jobs []job.Job, tag string) int {
+	var cnt int
+
+	for _, j := range jobs {
+		if len(j.Cmd) > 0 && j.Cmd[len(j.Cmd)-1] == tag {
+			cnt++
+		}
+	}
+
+	return cnt
+} // func countJobs(jobs []job.Job, tag string) int
@@ -0,0 +1,207 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/09_monitor_recover_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 11:58:03 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// insertRunning puts a Job into the database that looks like it was started
+// by a Monitor that died while it was running.
+// We claim the Job in the same transaction we submit it in, so none of the
+// workers snatches it.
+func insertRunning(t *testing.T, policy job.RestartPolicy, pid int, cmd ...string) *job.Job {
+	var (
+		err error
+		ok  bool
+		j   *job.Job
+		db  = mon.pool.Get()
+	)
+
+	defer mon.pool.Put(db)
+
+	if j, err = job.New(job.Options{Restart: policy}, cmd...); err != nil {
+		t.Fatalf("Cannot create Job: %s", err.Error())
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Cannot begin transaction: %s", err.Error())
+	}
+
+	j.TimeSubmitted = time.Now()
+
+	if err = db.JobSubmit(j); err != nil {
+		db.Rollback() // nolint: errcheck
+		t.Fatalf("Cannot submit Job: %s", err.Error())
+	} else if ok, err = db.JobClaim(j); err != nil || !ok {
+		db.Rollback() // nolint: errcheck
+		t.Fatalf("Cannot claim Job %d: %v", j.ID, err)
+	}
+
+	j.PID = int64(pid)
+	j.SpoolOut = fmt.Sprintf("/nonexistent/jobq.%d.out", j.ID)
+	j.SpoolErr = fmt.Sprintf("/nonexistent/jobq.%d.err", j.ID)
+
+	if err = db.JobStart(j); err != nil {
+		db.Rollback() // nolint: errcheck
+		t.Fatalf("Cannot mark Job %d as started: %s", j.ID, err.Error())
+	} else if err = db.Commit(); err != nil {
+		t.Fatalf("Cannot commit transaction: %s", err.Error())
+	}
+
+	return j
+} // func insertRunning(t *testing.T, policy job.RestartPolicy, pid int, cmd ...string) *job.Job
+
+func TestMonRecover(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err                    error
+		conn                   *Conn
+		alive, dead            *exec.Cmd
+		adopted, lost, requeue *job.Job
+	)
+
+	// The process of this Job is still running, ...
+	alive = exec.Command("/bin/sleep", "2")
+	alive.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err = alive.Start(); err != nil {
+		t.Fatalf("Cannot start %v: %s", alive.Args, err.Error())
+	}
+
+	go alive.Wait() // nolint: errcheck
+
+	// ... the process of these is gone.
+	dead = exec.Command("/bin/true")
+
+	if err = dead.Run(); err != nil {
+		t.Fatalf("Cannot run %v: %s", dead.Args, err.Error())
+	}
+
+	adopted = insertRunning(t, job.RestartNever, alive.Process.Pid, alive.Args...)
+	lost = insertRunning(t, job.RestartNever, dead.Process.Pid, dead.Args...)
+	requeue = insertRunning(t, job.RestartRequeue, dead.Process.Pid, dead.Args...)
+
+	mon.recoverJobs()
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if s := jobStatus(t, conn, lost.ID); s != status.Lost {
+		t.Errorf("Job %d should have been marked as lost, its status is %s",
+			lost.ID,
+			s)
+	}
+
+	if s := jobStatus(t, conn, adopted.ID); s != status.Started {
+		t.Errorf("Job %d should have been adopted, its status is %s",
+			adopted.ID,
+			s)
+	}
+
+	var (
+		adoptDone, requeueDone bool
+	)
+
+	for i := 0; i < 40 && !(adoptDone && requeueDone); i++ {
+		time.Sleep(time.Millisecond * 250)
+		adoptDone = jobStatus(t, conn, adopted.ID) == status.Finished
+		requeueDone = jobStatus(t, conn, requeue.ID) == status.Finished
+	}
+
+	if !adoptDone {
+		t.Errorf("Adopted Job %d should have finished by now", adopted.ID)
+	}
+
+	if !requeueDone {
+		t.Errorf("Requeued Job %d should have finished by now", requeue.ID)
+	}
+} // func TestMonRecover(t *testing.T)
+
+func TestMonRecoverScript(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		conn   *Conn
+		tmp    string
+		script string
+		proc   *exec.Cmd
+		j      *job.Job
+	)
+
+	if tmp, err = os.MkdirTemp("", "jobq-recover"); err != nil {
+		t.Fatalf("Cannot create temporary directory: %s", err.Error())
+	}
+
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	// The kernel runs the script through its interpreter, so the command
+	// line of the process is not the Job's command.
+	script = filepath.Join(tmp, "build.sh")
+
+	if err = os.WriteFile(script, []byte("#!/bin/sh\nsleep 2\n"), 0755); err != nil {
+		t.Fatalf("Cannot write %s: %s", script, err.Error())
+	}
+
+	proc = exec.Command(script, "x")
+	proc.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err = proc.Start(); err != nil {
+		t.Fatalf("Cannot start %v: %s", proc.Args, err.Error())
+	}
+
+	go proc.Wait() // nolint: errcheck
+
+	// If we did not recognize the process, the Job would be started a
+	// second time.
+	j = insertRunning(t, job.RestartRequeue, proc.Process.Pid, proc.Args...)
+
+	mon.recoverJobs()
+
+	mon.runLock.Lock()
+	var rj, adopted = mon.running[j.ID]
+	mon.runLock.Unlock()
+
+	if !adopted || rj.PID != int64(proc.Process.Pid) {
+		t.Errorf("Job %d (PID %d) should have been adopted",
+			j.ID,
+			proc.Process.Pid)
+	}
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j = waitJob(t, conn, j.ID, time.Second*10); j.Status() != status.Finished || j.Attempts != 1 {
+		t.Errorf("Job %d should have finished after one attempt: %s, %d attempts",
+			j.ID,
+			j.Status(),
+			j.Attempts)
+	}
+} // func TestMonRecoverScript(t *testing.T)
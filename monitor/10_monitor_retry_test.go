@@ -0,0 +1,166 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/10_monitor_retry_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 14:21:15 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// waitJob waits for a Job to finish and returns it.
+func waitJob(t *testing.T, conn *Conn, id int64, timeout time.Duration) *job.Job {
+	var deadline = time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+
+		for _, j := range res.Jobs {
+			if j.ID == id && j.Status() == status.Finished {
+				return &j
+			}
+		}
+
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	t.Fatalf("Job %d did not finish within %s", id, timeout)
+	return nil
+} // func waitJob(t *testing.T, conn *Conn, id int64, timeout time.Duration) *job.Job
+
+func TestMonRetry(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		cmd      string
+		retry    job.RetryPolicy
+		attempts int
+		exit     int
+	}
+
+	var (
+		err       error
+		conn      *Conn
+		marker    = filepath.Join(common.BaseDir, "retry.marker")
+		testCases = []testCase{
+			{
+				cmd:      "echo attempt; exit 3",
+				retry:    job.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond * 100},
+				attempts: 3,
+				exit:     3,
+			},
+			{
+				cmd:      "exit 3",
+				retry:    job.RetryPolicy{MaxAttempts: 3, ExitCodes: []int{75}},
+				attempts: 1,
+				exit:     3,
+			},
+			{
+				// Fails the first time, succeeds the second time.
+				cmd:      fmt.Sprintf("test -e %s && exit 0; touch %s; exit 75", marker, marker),
+				retry:    job.RetryPolicy{MaxAttempts: 3, ExitCodes: []int{75}},
+				attempts: 2,
+				exit:     0,
+			},
+		}
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for idx, c := range testCases {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{Retry: c.retry}, "/bin/sh", "-c", c.cmd); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		j.ID = submitJob(t, conn, j)
+		j = waitJob(t, conn, j.ID, time.Second*10)
+
+		if j.Attempts != c.attempts || j.ExitCode != c.exit {
+			t.Errorf("Test case %d: Job %d ran %d times with exit code %d (expected %d/%d)",
+				idx,
+				j.ID,
+				j.Attempts,
+				j.ExitCode,
+				c.attempts,
+				c.exit)
+			continue
+		}
+
+		var (
+			runs []job.Run
+			db   = mon.pool.Get()
+		)
+
+		runs, err = db.RunGetByJob(j.ID)
+		mon.pool.Put(db)
+
+		if err != nil {
+			t.Fatalf("Cannot load runs of Job %d: %s", j.ID, err.Error())
+		} else if len(runs) != c.attempts {
+			t.Errorf("Test case %d: expected %d runs of Job %d, got %d",
+				idx,
+				c.attempts,
+				j.ID,
+				len(runs))
+			continue
+		}
+
+		for _, r := range runs {
+			if _, err = os.Stat(r.SpoolOut); err != nil {
+				t.Errorf("Spool file of attempt %d of Job %d is missing: %s",
+					r.Seq,
+					j.ID,
+					err.Error())
+			} else if r.Seq < len(runs) && r.ExitCode == 0 {
+				t.Errorf("Attempt %d of Job %d should have failed", r.Seq, j.ID)
+			}
+		}
+
+		if last := runs[len(runs)-1]; last.SpoolOut != j.SpoolOut || last.ExitCode != j.ExitCode {
+			t.Errorf("Last run of Job %d does not match the Job: %#v", j.ID, last)
+		}
+
+		// The status of the queue includes the history of each Job.
+		if len(j.Runs) != len(runs) {
+			t.Errorf("Test case %d: QueueQueryStatus reports %d runs for Job %d, expected %d",
+				idx,
+				len(j.Runs),
+				j.ID,
+				len(runs))
+			continue
+		}
+
+		for ridx, r := range j.Runs {
+			if r.Seq != runs[ridx].Seq ||
+				r.PID != runs[ridx].PID ||
+				r.ExitCode != runs[ridx].ExitCode ||
+				!r.TimeStarted.Equal(runs[ridx].TimeStarted) {
+				t.Errorf("Test case %d: QueueQueryStatus reports a different run of Job %d:\nExpected: %#v\nActual:   %#v",
+					idx,
+					j.ID,
+					runs[ridx],
+					r)
+			}
+		}
+	}
+} // func TestMonRetry(t *testing.T)
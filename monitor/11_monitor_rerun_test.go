@@ -0,0 +1,116 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/11_monitor_rerun_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 10:31:17 krylon>
+
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+)
+
+func TestMonRerun(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		conn  *Conn
+		orig  *job.Job
+		rerun *job.Job
+		res   Response
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if orig, err = job.New(job.Options{Directory: common.BaseDir, Nice: 5}, "/bin/sh", "-c", "exit 3"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	orig.Env = []string{"JOBQ_TEST=rerun"}
+	orig.Priority = 2
+	orig.ID = submitJob(t, conn, orig)
+
+	// A Job that has not finished cannot be rerun.
+	var pending *job.Job
+
+	if pending, err = job.New(job.Options{}, "/bin/sleep", "2"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	pending.NotBefore = time.Now().Add(time.Hour)
+	pending.ID = submitJob(t, conn, pending)
+
+	if res = sendMsg(t, conn, MakeMsg(&JobRerunArgs{JobID: pending.ID})); !res.Error {
+		t.Errorf("Rerunning a pending Job should fail: %s", res.Status)
+	} else if res = sendMsg(t, conn, MakeMsg(&JobRerunArgs{JobID: 999999})); !res.Error {
+		t.Errorf("Rerunning a Job that does not exist should fail: %s", res.Status)
+	}
+
+	sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: pending.ID}))
+
+	orig = waitJob(t, conn, orig.ID, time.Second*5)
+
+	// A plain rerun is a copy of the original.
+	if res = sendMsg(t, conn, MakeMsg(&JobRerunArgs{JobID: orig.ID})); res.Error || len(res.Jobs) != 1 {
+		t.Fatalf("Failed to rerun Job %d: %s", orig.ID, res.Status)
+	}
+
+	rerun = waitJob(t, conn, res.Jobs[0].ID, time.Second*5)
+
+	if rerun.ID == orig.ID {
+		t.Errorf("Rerun should be a new Job, not %d", rerun.ID)
+	} else if !reflect.DeepEqual(rerun.Cmd, orig.Cmd) ||
+		!reflect.DeepEqual(rerun.Env, orig.Env) ||
+		!reflect.DeepEqual(rerun.Options, orig.Options) ||
+		rerun.Priority != orig.Priority ||
+		rerun.ExitCode != 3 {
+		t.Errorf("Rerun does not match the original Job:\nExpected: %#v\nActual:   %#v",
+			orig,
+			rerun)
+	}
+
+	// With overrides, the named fields are replaced.
+	var (
+		args = &JobRerunArgs{
+			JobID: orig.ID,
+			Job: &job.Job{
+				Cmd:      []string{"/bin/true"},
+				Priority: 9,
+				Options:  job.Options{Nice: 1},
+			},
+			Override: []string{"Cmd", "Priority"},
+		}
+	)
+
+	if res = sendMsg(t, conn, MakeMsg(args)); res.Error || len(res.Jobs) != 1 {
+		t.Fatalf("Failed to rerun Job %d with overrides: %s", orig.ID, res.Status)
+	}
+
+	rerun = waitJob(t, conn, res.Jobs[0].ID, time.Second*5)
+
+	if rerun.Cmd[0] != "/bin/true" || rerun.Priority != 9 || rerun.ExitCode != 0 {
+		t.Errorf("Fields of rerun Job were not overridden: %#v", rerun)
+	} else if rerun.Nice != orig.Nice || rerun.Directory != orig.Directory {
+		t.Errorf("Fields of rerun Job were overridden that should not have been: %#v", rerun)
+	}
+
+	args.Override = []string{"Bogus"}
+
+	if res = sendMsg(t, conn, MakeMsg(args)); !res.Error {
+		t.Errorf("Overriding an unknown field should fail: %s", res.Status)
+	}
+} // func TestMonRerun(t *testing.T)
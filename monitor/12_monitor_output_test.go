@@ -0,0 +1,199 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/12_monitor_output_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 15:02:44 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// readOutput sends a JobOutput request and collects the output the Monitor
+// sends back. It returns the output and the last Response.
+func readOutput(t *testing.T, conn *Conn, args JobOutputArgs) (string, Response) {
+	var (
+		output []byte
+		msg    = MakeMsg(&args)
+		res    = sendMsg(t, conn, msg)
+	)
+
+	for res.More {
+		var next, err = conn.Receive()
+
+		output = append(output, res.Output...)
+
+		if err != nil {
+			t.Fatalf("Cannot receive output from Monitor: %s",
+				err.Error())
+		} else if next.ID != res.ID {
+			t.Fatalf("Response %d does not belong to request %d",
+				next.ID,
+				res.ID)
+		}
+
+		res = *next
+	}
+
+	return string(output), res
+} // func readOutput(t *testing.T, conn *Conn, args JobOutputArgs) (string, Response)
+
+func TestMonOutput(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		args   JobOutputArgs
+		expect string
+		err    bool
+	}
+
+	const script = "echo hello; echo oops >&2; sleep 1; echo world"
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", script); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.ID = submitJob(t, conn, j)
+
+	// Following a Job that has not been started, yet, waits for it to
+	// start and finish.
+	var testCases = []testCase{
+		{JobOutputArgs{JobID: j.ID, Follow: true}, "hello\nworld\n", false},
+		{JobOutputArgs{JobID: j.ID}, "hello\nworld\n", false},
+		{JobOutputArgs{JobID: j.ID, Stderr: true}, "oops\n", false},
+		{JobOutputArgs{JobID: j.ID, Stderr: true, Follow: true}, "oops\n", false},
+		{JobOutputArgs{}, "", true},
+		{JobOutputArgs{JobID: 999999}, "", true},
+	}
+
+	for idx, c := range testCases {
+		var output, res = readOutput(t, conn, c.args)
+
+		if res.Error != c.err {
+			t.Errorf("Test case %d (%#v): unexpected error status: %t (%s)",
+				idx,
+				c.args,
+				res.Error,
+				res.Status)
+		} else if output != c.expect {
+			t.Errorf("Test case %d (%#v): unexpected output:\nExpected: %q\nActual:   %q",
+				idx,
+				c.args,
+				c.expect,
+				output)
+		}
+	}
+
+	// Without follow, a Job that has not been started has no output.
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Hour)
+	j.ID = submitJob(t, conn, j)
+
+	if _, res := readOutput(t, conn, JobOutputArgs{JobID: j.ID}); !res.Error {
+		t.Errorf("Output of a pending Job should be an error: %s", res.Status)
+	}
+
+	sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: j.ID}))
+} // func TestMonOutput(t *testing.T)
+
+func TestMonOutputAttempt(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		args   JobOutputArgs
+		expect string
+		err    bool
+	}
+
+	var (
+		err    error
+		conn   *Conn
+		j      *job.Job
+		tmp    string
+		script string
+	)
+
+	if tmp, err = os.MkdirTemp("", "jobq-attempt"); err != nil {
+		t.Fatalf("Cannot create temporary directory: %s", err.Error())
+	}
+
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	// The first attempt fails, the second one succeeds.
+	script = fmt.Sprintf(
+		"if [ -e %[1]s ]; then echo second; else touch %[1]s; echo first; exit 1; fi",
+		filepath.Join(tmp, "flag"))
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	var opt = job.Options{
+		Retry: job.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond * 100},
+	}
+
+	if j, err = job.New(opt, "/bin/sh", "-c", script); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.ID = submitJob(t, conn, j)
+
+	// Following an attempt waits for it to start, even if it is not the
+	// first one.
+	var testCases = []testCase{
+		{JobOutputArgs{JobID: j.ID, Follow: true, Attempt: 2}, "second\n", false},
+		{JobOutputArgs{JobID: j.ID, Attempt: 1}, "first\n", false},
+		{JobOutputArgs{JobID: j.ID}, "second\n", false},
+		{JobOutputArgs{JobID: j.ID, Follow: true, Attempt: 3}, "", true},
+	}
+
+	for idx, c := range testCases {
+		var output, res = readOutput(t, conn, c.args)
+
+		if res.Error != c.err {
+			t.Errorf("Test case %d (%#v): unexpected error status: %t (%s)",
+				idx,
+				c.args,
+				res.Error,
+				res.Status)
+		} else if output != c.expect {
+			t.Errorf("Test case %d (%#v): unexpected output:\nExpected: %q\nActual:   %q",
+				idx,
+				c.args,
+				c.expect,
+				output)
+		}
+	}
+} // func TestMonOutputAttempt(t *testing.T)
@@ -0,0 +1,73 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/13_monitor_merge_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 17:52:13 krylon>
+
+package monitor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+)
+
+func TestMonOutputMerge(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const expect = "out one\nerr two\nout three\n"
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(
+		job.Options{Output: job.OutputMerge | job.OutputTags},
+		"/bin/sh", "-c", "echo one; sleep 0.2; echo two >&2; sleep 0.2; echo three"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.ID = submitJob(t, conn, j)
+	j = waitJob(t, conn, j.ID, time.Second*5)
+
+	if j.SpoolErr != "" || filepath.Dir(j.SpoolOut) != common.SpoolDir || filepath.Ext(j.SpoolOut) != ".log" {
+		t.Errorf("Unexpected spool files for Job with merged output: %q, %q",
+			j.SpoolOut,
+			j.SpoolErr)
+	}
+
+	// Both streams are in the one spool file.
+	for _, stderr := range []bool{false, true} {
+		var output, res = readOutput(t, conn, JobOutputArgs{JobID: j.ID, Stderr: stderr})
+
+		if res.Error {
+			t.Errorf("Cannot get output (stderr: %t) of Job %d: %s", stderr, j.ID, res.Status)
+		} else if output != expect {
+			t.Errorf("Unexpected output (stderr: %t) of Job %d:\nExpected: %q\nActual:   %q",
+				stderr,
+				j.ID,
+				expect,
+				output)
+		}
+	}
+
+	// Clearing the queue must cope with the missing stderr spool file.
+	if res := sendMsg(t, conn, MakeMsg(&JobClearArgs{})); res.Error {
+		t.Errorf("Cannot clear finished Jobs: %s", res.Status)
+	}
+} // func TestMonOutputMerge(t *testing.T)
@@ -0,0 +1,165 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/14_monitor_compress_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 10:31:55 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+func TestMonOutputCompress(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		compress string
+		suffix   string
+	}
+
+	var testCases = []testCase{
+		{job.CompressGzip, ".out.gz"},
+		{job.CompressZstd, ".out.zst"},
+	}
+
+	var (
+		err    error
+		conn   *Conn
+		expect strings.Builder
+	)
+
+	for i := 1; i <= 5000; i++ {
+		fmt.Fprintf(&expect, "%d\n", i)
+	}
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for idx, c := range testCases {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{Compress: c.compress}, "seq", "1", "5000"); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		j.ID = submitJob(t, conn, j)
+		j = waitJob(t, conn, j.ID, time.Second*5)
+
+		if !strings.HasSuffix(j.SpoolOut, c.suffix) {
+			t.Errorf("Test case %d: spool file %q should end in %q",
+				idx,
+				j.SpoolOut,
+				c.suffix)
+		} else if len(j.Runs) != 1 || j.Runs[0].Compress != c.compress {
+			t.Errorf("Test case %d: run of Job %d should record compression %q: %#v",
+				idx,
+				j.ID,
+				c.compress,
+				j.Runs)
+		}
+
+		var output, res = readOutput(t, conn, JobOutputArgs{JobID: j.ID})
+
+		if res.Error {
+			t.Errorf("Test case %d: Cannot get output of Job %d: %s",
+				idx,
+				j.ID,
+				res.Status)
+		} else if output != expect.String() {
+			t.Errorf("Test case %d: output of Job %d is incomplete: expected %d bytes, got %d",
+				idx,
+				j.ID,
+				expect.Len(),
+				len(output))
+		}
+	}
+} // func TestMonOutputCompress(t *testing.T)
+
+func TestMonOutputCompressFollow(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const script = "echo hello; sleep 3; echo world"
+
+	var (
+		err  error
+		conn *Conn
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for idx, compress := range []string{job.CompressGzip, job.CompressZstd} {
+		var (
+			j     *job.Job
+			res   *Response
+			start = time.Now()
+		)
+
+		if j, err = job.New(job.Options{Compress: compress}, "/bin/sh", "-c", script); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		j.ID = submitJob(t, conn, j)
+
+		// The first line has to arrive while the Job is still
+		// running, not once it is done.
+		var msg = MakeMsg(&JobOutputArgs{JobID: j.ID, Follow: true})
+
+		if res, err = conn.Request(&msg); err != nil {
+			t.Fatalf("Test case %d: Cannot request output of Job %d: %s",
+				idx,
+				j.ID,
+				err.Error())
+		} else if res.Error || string(res.Output) != "hello\n" {
+			t.Errorf("Test case %d: unexpected first Response for Job %d: %q (%s)",
+				idx,
+				j.ID,
+				res.Output,
+				res.Status)
+		} else if elapsed := time.Since(start); elapsed > time.Millisecond*2500 {
+			t.Errorf("Test case %d: output of Job %d arrived after %s, when it was done",
+				idx,
+				j.ID,
+				elapsed)
+		}
+
+		var output = string(res.Output)
+
+		for res.More {
+			if res, err = conn.Receive(); err != nil {
+				t.Fatalf("Test case %d: Cannot receive output from Monitor: %s",
+					idx,
+					err.Error())
+			}
+
+			output += string(res.Output)
+		}
+
+		if output != "hello\nworld\n" {
+			t.Errorf("Test case %d: unexpected output of Job %d: %q",
+				idx,
+				j.ID,
+				output)
+		}
+	}
+} // func TestMonOutputCompressFollow(t *testing.T)
@@ -0,0 +1,179 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/15_monitor_retention_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 15:12:08 krylon>
+
+package monitor
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+func TestRetentionExpired(t *testing.T) {
+	type testCase struct {
+		r      Retention
+		expect []int
+	}
+
+	var (
+		now  = time.Now()
+		jobs = []job.Job{
+			{TimeEnded: now.Add(-time.Hour)},
+			{TimeCancelled: now.Add(-time.Hour * 5)},
+			{TimeEnded: now.Add(-time.Hour * 30)},
+			{TimeEnded: now.Add(-time.Hour * 100)},
+		}
+		sizes = []int64{100, 0, 300, 50}
+	)
+
+	var testCases = []testCase{
+		{Retention{}, []int{0, 1, 2, 3}},
+		{Retention{Keep: 2}, []int{2, 3}},
+		{Retention{Keep: 10}, nil},
+		{Retention{MaxAge: time.Hour * 24}, []int{2, 3}},
+		{Retention{Keep: 3, MaxAge: time.Hour * 2}, []int{3}},
+		{Retention{Keep: 1, MaxAge: time.Hour * 48}, []int{3}},
+		{Retention{MaxSize: 399}, []int{2, 3}},
+		{Retention{MaxSize: 450}, nil},
+		{Retention{Keep: 4, MaxSize: 99}, []int{0, 1, 2, 3}},
+		{Retention{Keep: 1, MaxSize: 1000}, []int{1, 2, 3}},
+	}
+
+	for idx, c := range testCases {
+		var expired = c.r.expired(jobs, sizes, now)
+
+		if !reflect.DeepEqual(expired, c.expect) {
+			t.Errorf("Test case %d (%s): expected %v, got %v",
+				idx,
+				&c.r,
+				c.expect,
+				expired)
+		}
+	}
+} // func TestRetentionExpired(t *testing.T)
+
+func TestParseAge(t *testing.T) {
+	type testCase struct {
+		s      string
+		expect time.Duration
+		err    bool
+	}
+
+	var testCases = []testCase{
+		{"7d", time.Hour * 24 * 7, false},
+		{"36h", time.Hour * 36, false},
+		{"90m", time.Minute * 90, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"week", 0, true},
+	}
+
+	for idx, c := range testCases {
+		var d, err = ParseAge(c.s)
+
+		if c.err {
+			if err == nil {
+				t.Errorf("Test case %d (%q): expected an error, got %s",
+					idx,
+					c.s,
+					d)
+			}
+		} else if err != nil {
+			t.Errorf("Test case %d (%q): unexpected error: %s",
+				idx,
+				c.s,
+				err.Error())
+		} else if d != c.expect {
+			t.Errorf("Test case %d (%q): expected %s, got %s",
+				idx,
+				c.s,
+				c.expect,
+				d)
+		}
+	}
+} // func TestParseAge(t *testing.T)
+
+func TestMonClean(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const cnt = 3
+
+	var (
+		err  error
+		conn *Conn
+		ids  [cnt]int64
+		jobs [cnt]*job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for i := range ids {
+		var j *job.Job
+
+		if j, err = job.New(job.Options{}, "/bin/echo", "clean"); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		ids[i] = submitJob(t, conn, j)
+		jobs[i] = waitJob(t, conn, ids[i], time.Second*5)
+	}
+
+	// A spool file that has gone missing must not stop the cleanup.
+	if err = os.Remove(jobs[0].SpoolOut); err != nil {
+		t.Fatalf("Cannot remove spool file %s: %s",
+			jobs[0].SpoolOut,
+			err.Error())
+	}
+
+	if res := sendMsg(t, conn, MakeMsg(&JobClearArgs{Retention: &Retention{Keep: 1}})); res.Error {
+		t.Fatalf("Cannot clean up finished Jobs: %s", res.Status)
+	}
+
+	// Only the most recent Job is left.
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+	var left []int64
+
+	for _, j := range res.Jobs {
+		if st := j.Status(); st == status.Finished || st == status.Cancelled || st == status.TimedOut {
+			left = append(left, j.ID)
+		}
+	}
+
+	if !reflect.DeepEqual(left, []int64{ids[cnt-1]}) {
+		t.Errorf("Expected only Job %d to be kept, not %v",
+			ids[cnt-1],
+			left)
+	}
+
+	for _, j := range jobs[:cnt-1] {
+		for _, path := range []string{j.SpoolOut, j.SpoolErr} {
+			if _, err = os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Spool file %s of Job %d was not removed: %v",
+					path,
+					j.ID,
+					err)
+			}
+		}
+	}
+
+	if _, err = os.Stat(jobs[cnt-1].SpoolOut); err != nil {
+		t.Errorf("Spool file %s of the Job we keep is gone: %s",
+			jobs[cnt-1].SpoolOut,
+			err.Error())
+	}
+} // func TestMonClean(t *testing.T)
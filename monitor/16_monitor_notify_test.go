@@ -0,0 +1,220 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/16_monitor_notify_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 18:20:44 krylon>
+
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+)
+
+// waitFile waits for a file to appear and returns its content.
+func waitFile(t *testing.T, path string, timeout time.Duration) string {
+	var deadline = time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		if buf, err := os.ReadFile(path); err == nil && len(buf) > 0 {
+			return string(buf)
+		}
+
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	t.Fatalf("File %s did not appear within %s", path, timeout)
+	return ""
+} // func waitFile(t *testing.T, path string, timeout time.Duration) string
+
+func TestMonHook(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	const hook = `echo "$JOBQ_ID $JOBQ_EXIT $JOBQ_STATUS $JOBQ_STDOUT $FOO" > hook.out`
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(
+		job.Options{Directory: common.BaseDir, Hook: hook},
+		"/bin/sh", "-c", "exit 3"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.Env = []string{"PATH=" + os.Getenv("PATH"), "FOO=bar"}
+	j.ID = submitJob(t, conn, j)
+	j = waitJob(t, conn, j.ID, time.Second*5)
+
+	var (
+		output = waitFile(t, filepath.Join(common.BaseDir, "hook.out"), time.Second*5)
+		expect = fmt.Sprintf("%d 3 %s %s bar\n", j.ID, status.Finished, j.SpoolOut)
+	)
+
+	if output != expect {
+		t.Errorf("Unexpected output from hook:\nExpected: %q\nActual:   %q",
+			expect,
+			output)
+	}
+} // func TestMonHook(t *testing.T)
+
+func TestMonEventPipe(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		fh       *os.File
+		line     string
+		ev       Event
+		now      = time.Now()
+		pipePath = filepath.Join(common.BaseDir, "events.fifo")
+		hookPath = filepath.Join(common.BaseDir, "queue.hook.out")
+		m        = &Monitor{name: "notify", log: mon.log}
+		j        = &job.Job{
+			ID:            4711,
+			Cmd:           []string{"/bin/true"},
+			TimeSubmitted: now.Add(-time.Minute),
+			TimeStarted:   now.Add(-time.Second),
+			TimeEnded:     now,
+			Attempts:      1,
+			SpoolOut:      "/nonexistent/jobq.4711.out",
+		}
+	)
+
+	m.SetHook(fmt.Sprintf("echo $JOBQ_QUEUE $JOBQ_ID > %s", hookPath))
+
+	if err = m.SetEventPipe(pipePath); err != nil {
+		t.Fatalf("Cannot create event pipe %s: %s",
+			pipePath,
+			err.Error())
+	}
+
+	// Without a reader, the Event is dropped, but the Monitor does not
+	// block.
+	m.notify(j)
+
+	if output := waitFile(t, hookPath, time.Second*5); output != "notify 4711\n" {
+		t.Errorf("Unexpected output from queue hook: %q", output)
+	}
+
+	if fh, err = os.OpenFile(pipePath, os.O_RDONLY|syscall.O_NONBLOCK, 0); err != nil {
+		t.Fatalf("Cannot open event pipe %s for reading: %s",
+			pipePath,
+			err.Error())
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	fh.SetReadDeadline(time.Now().Add(time.Second * 5)) // nolint: errcheck
+
+	m.notify(j)
+
+	if line, err = bufio.NewReader(fh).ReadString('\n'); err != nil {
+		t.Fatalf("Cannot read Event from pipe: %s", err.Error())
+	} else if err = json.Unmarshal([]byte(line), &ev); err != nil {
+		t.Fatalf("Cannot parse Event %q: %s", line, err.Error())
+	} else if ev.ID != j.ID || ev.Queue != "notify" || ev.Status != status.Finished.String() || ev.SpoolOut != j.SpoolOut {
+		t.Errorf("Unexpected Event: %#v", ev)
+	} else if strings.Count(line, "\n") != 1 {
+		t.Errorf("Event should be a single line: %q", line)
+	}
+} // func TestMonEventPipe(t *testing.T)
+
+func TestMonNotifyCancel(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err        error
+		conn       *Conn
+		running    *job.Job
+		pending    *job.Job
+		failed     *job.Job
+		dependent  *job.Job
+		hookOutput = func(name string) string {
+			return filepath.Join(common.BaseDir, "cancel."+name+".out")
+		}
+		hook = func(name string) job.Options {
+			return job.Options{
+				Hook: fmt.Sprintf("echo $JOBQ_STATUS > %s", hookOutput(name)),
+			}
+		}
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if running, err = job.New(hook("running"), "/bin/sleep", "30"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if pending, err = job.New(hook("pending"), "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if failed, err = job.New(job.Options{}, "/bin/false"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	} else if dependent, err = job.New(hook("dependent"), "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	// A Job that is cancelled while it runs, ...
+	running.ID = submitJob(t, conn, running)
+
+	for i := 0; i < 40 && jobStatus(t, conn, running.ID) != status.Started; i++ {
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: running.ID}))
+
+	// ... one that is cancelled before it starts, ...
+	pending.NotBefore = time.Now().Add(time.Hour)
+	pending.ID = submitJob(t, conn, pending)
+	sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: pending.ID}))
+
+	// ... and one whose dependency cannot be satisfied anymore.
+	failed.ID = submitJob(t, conn, failed)
+	dependent.Depends = []job.Dependency{{ID: failed.ID, Condition: job.AfterOK}}
+	dependent.ID = submitJob(t, conn, dependent)
+
+	for _, name := range []string{"running", "pending", "dependent"} {
+		var (
+			output = waitFile(t, hookOutput(name), time.Second*10)
+			expect = status.Cancelled.String() + "\n"
+		)
+
+		if output != expect {
+			t.Errorf("Unexpected status reported for %s Job:\nExpected: %q\nActual:   %q",
+				name,
+				expect,
+				output)
+		}
+	}
+} // func TestMonNotifyCancel(t *testing.T)
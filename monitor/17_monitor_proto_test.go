@@ -0,0 +1,226 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/17_monitor_proto_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 22. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-22 12:27:03 krylon>
+
+package monitor
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+	"github.com/blicero/jobq/monitor/request"
+)
+
+func TestMonHandshake(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		nc    net.Conn
+		conn  *Conn
+		hello Hello
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	} else if p := conn.Peer(); p.Version != ProtocolVersion || p.Name != mon.name {
+		t.Errorf("Unexpected Hello from Monitor: %#v", p)
+	}
+
+	conn.Close() // nolint: errcheck
+
+	// A client that speaks a different version is turned away.
+	if nc, err = net.Dial(common.NetName, socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	conn = newConn(nc)
+	defer conn.Close() // nolint: errcheck
+
+	if err = conn.writeFrame(&Hello{Version: ProtocolVersion + 1, Name: "future"}); err != nil {
+		t.Fatalf("Cannot send Hello: %s", err.Error())
+	} else if err = conn.readFrame(&hello); err != nil {
+		t.Fatalf("Cannot receive Hello: %s", err.Error())
+	} else if hello.Code != errcode.VersionMismatch {
+		t.Errorf("Monitor should refuse protocol version %d: %#v",
+			ProtocolVersion+1,
+			hello)
+	}
+} // func TestMonHandshake(t *testing.T)
+
+func TestMonErrorCodes(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	type testCase struct {
+		msg  any
+		code errcode.Code
+	}
+
+	var testCases = []testCase{
+		{MakeMsg(&QueueQueryStatusArgs{}), errcode.OK},
+		{wireMessage{}, errcode.UnknownRequest},
+		{wireMessage{Request: "Frobnicate"}, errcode.UnknownRequest},
+		{wireMessage{Request: request.MonitorRestart.String()}, errcode.UnknownRequest},
+		{wireMessage{Request: request.JobCancel.String(), Args: []byte(`{"JobID":"abc"}`)}, errcode.BadRequest},
+		{MakeMsg(&JobCancelArgs{}), errcode.InvalidArgument},
+		{MakeMsg(&JobCancelArgs{JobID: 999999}), errcode.NotFound},
+		{MakeMsg(&ScheduleDeleteArgs{ScheduleID: 999999}), errcode.NotFound},
+		{MakeMsg(&JobSubmitArgs{}), errcode.InvalidArgument},
+	}
+
+	var (
+		err  error
+		conn *Conn
+		res  *Response
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for idx, c := range testCases {
+		// Requests the Monitor cannot decode must still get a Response
+		// with the right ID.
+		var id = uint64(idx + 1)
+
+		switch msg := c.msg.(type) {
+		case Message:
+			msg.ID = id
+			c.msg = msg
+		case wireMessage:
+			msg.ID = id
+			c.msg = msg
+		}
+
+		if err = conn.writeFrame(c.msg); err != nil {
+			t.Fatalf("Test case %d: cannot send request: %s",
+				idx,
+				err.Error())
+		} else if res, err = conn.Receive(); err != nil {
+			t.Fatalf("Test case %d: cannot receive Response: %s",
+				idx,
+				err.Error())
+		} else if res.ID != id {
+			t.Errorf("Test case %d: Response has ID %d, expected %d",
+				idx,
+				res.ID,
+				id)
+		}
+
+		if res.Code != c.code || res.Error != (c.code != errcode.OK) {
+			t.Errorf("Test case %d: expected %s, got %s/%t (%s)",
+				idx,
+				c.code,
+				res.Code,
+				res.Error,
+				res.Status)
+		} else if c.code != errcode.OK {
+			var e *Error
+
+			if !errors.As(res.Err(), &e) || e.Code != c.code {
+				t.Errorf("Test case %d: Err() should return an *Error with code %s, not %v",
+					idx,
+					c.code,
+					res.Err())
+			}
+		}
+	}
+
+	// A frame we cannot decode gets an error, but the connection can
+	// still be used.
+	var (
+		hdr [4]byte
+		msg = MakeMsg(&QueueQueryStatusArgs{})
+	)
+
+	binary.BigEndian.PutUint32(hdr[:], 8)
+
+	if _, err = conn.conn.Write(append(hdr[:], "not json"...)); err != nil {
+		t.Fatalf("Cannot send garbage to Monitor: %s", err.Error())
+	} else if res, err = conn.Receive(); err != nil {
+		t.Fatalf("Cannot receive Response: %s", err.Error())
+	} else if res.Code != errcode.BadRequest {
+		t.Errorf("Monitor should reject garbage with %s, not %s (%s)",
+			errcode.BadRequest,
+			res.Code,
+			res.Status)
+	}
+
+	msg.ID = 4711
+
+	if res, err = conn.Request(&msg); err != nil {
+		t.Fatalf("Connection is not usable after sending garbage: %s",
+			err.Error())
+	} else if res.ID != 4711 || res.Error {
+		t.Errorf("Unexpected Response to request 4711: %d (%s)",
+			res.ID,
+			res.Status)
+	}
+} // func TestMonErrorCodes(t *testing.T)
+
+func TestMonLargeResponse(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		conn  *Conn
+		j     *job.Job
+		found bool
+		big   = strings.Repeat("x", 256<<10)
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/true", big); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Hour)
+	j.ID = submitJob(t, conn, j)
+
+	defer sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: j.ID}))
+
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{}))
+
+	for _, qj := range res.Jobs {
+		if qj.ID == j.ID {
+			found = true
+			if len(qj.Cmd) != 2 || qj.Cmd[1] != big {
+				t.Errorf("Command line of Job %d was mangled", j.ID)
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("Job %d is missing from the queue", j.ID)
+	}
+} // func TestMonLargeResponse(t *testing.T)
@@ -0,0 +1,192 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/18_monitor_args_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 22. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-22 16:40:18 krylon>
+
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+	"github.com/blicero/jobq/monitor/request"
+	"github.com/blicero/jobq/schedule"
+)
+
+func TestArgsValidate(t *testing.T) {
+	type testCase struct {
+		args  Args
+		valid bool
+	}
+
+	var testCases = []testCase{
+		{&JobSubmitArgs{Job: &job.Job{Cmd: []string{"/bin/true"}}}, true},
+		{&JobSubmitArgs{}, false},
+		{&JobSubmitArgs{Job: &job.Job{}}, false},
+		{&JobSubmitArgs{Job: &job.Job{Cmd: []string{"/bin/true"}, Options: job.Options{IOPriority: 8}}}, false},
+		{&JobCancelArgs{JobID: 1}, true},
+		{&JobCancelArgs{}, false},
+		{&JobSetPriorityArgs{JobID: 1, Priority: -5}, true},
+		{&JobSetPriorityArgs{JobID: -1}, false},
+		{&JobRerunArgs{JobID: 1}, true},
+		{&JobRerunArgs{JobID: 1, Override: []string{"Cmd"}}, false},
+		{&JobRerunArgs{JobID: 1, Job: &job.Job{}, Override: []string{"Cmd"}}, true},
+		{&JobOutputArgs{JobID: 1, Follow: true}, true},
+		{&JobOutputArgs{}, false},
+		{&JobOutputArgs{JobID: 1, Attempt: 2}, true},
+		{&JobOutputArgs{JobID: 1, Attempt: -1}, false},
+		{&JobClearArgs{}, true},
+		{&JobClearArgs{Retention: &Retention{Keep: 3}}, true},
+		{&JobClearArgs{Retention: &Retention{MaxAge: -time.Hour}}, false},
+		{&JobWaitArgs{IDs: []int64{1, 2}, Any: true, Timeout: time.Minute}, true},
+		{&JobWaitArgs{}, false},
+		{&JobWaitArgs{IDs: []int64{1, -2}}, false},
+		{&JobWaitArgs{IDs: []int64{1}, Timeout: -time.Second}, false},
+		{&QueueQueryStatusArgs{}, true},
+		{&QueueQueryStatusArgs{IDs: []int64{1, 2}}, true},
+		{&QueueQueryStatusArgs{IDs: []int64{1, 0}}, false},
+		{&ScheduleCreateArgs{Schedule: &schedule.Schedule{Spec: "@daily", Cmd: []string{"/bin/true"}}}, true},
+		{&ScheduleCreateArgs{}, false},
+		{&ScheduleCreateArgs{Schedule: &schedule.Schedule{Spec: "bogus", Cmd: []string{"/bin/true"}}}, false},
+		{&ScheduleListArgs{}, true},
+		{&SchedulePauseArgs{ScheduleID: 1}, true},
+		{&ScheduleResumeArgs{}, false},
+		{&ScheduleDeleteArgs{ScheduleID: -3}, false},
+	}
+
+	for idx, c := range testCases {
+		var err = c.args.Validate()
+
+		if c.valid && err != nil {
+			t.Errorf("Test case %d (%s): unexpected error: %s",
+				idx,
+				c.args.Request(),
+				err.Error())
+		} else if !c.valid && err == nil {
+			t.Errorf("Test case %d (%s): %#v should be invalid",
+				idx,
+				c.args.Request(),
+				c.args)
+		}
+	}
+} // func TestArgsValidate(t *testing.T)
+
+func TestMessageJSON(t *testing.T) {
+	var testCases = []Args{
+		&JobSubmitArgs{Job: &job.Job{Cmd: []string{"/bin/echo", "hello"}, Priority: 3}},
+		&JobCancelArgs{JobID: 42},
+		&JobSetPriorityArgs{JobID: 42, Priority: -1},
+		&JobRerunArgs{JobID: 42, Job: &job.Job{Cmd: []string{"/bin/true"}}, Override: []string{"Cmd"}},
+		&JobOutputArgs{JobID: 42, Stderr: true, Follow: true},
+		&JobClearArgs{Retention: &Retention{Keep: 10, MaxAge: time.Hour}},
+		&JobWaitArgs{IDs: []int64{17, 18, 19}, Any: true, Timeout: time.Minute},
+		&QueueQueryStatusArgs{IDs: []int64{1, 2, 3}},
+		&ScheduleListArgs{},
+		&SchedulePauseArgs{ScheduleID: 7},
+		&ScheduleResumeArgs{ScheduleID: 7},
+		&ScheduleDeleteArgs{ScheduleID: 7},
+	}
+
+	for idx, args := range testCases {
+		var (
+			err      error
+			buf      []byte
+			msg, dec Message
+		)
+
+		msg = MakeMsg(args)
+		msg.ID = uint64(idx + 1)
+
+		if buf, err = json.Marshal(&msg); err != nil {
+			t.Errorf("Test case %d (%s): cannot encode Message: %s",
+				idx,
+				args.Request(),
+				err.Error())
+			continue
+		} else if err = json.Unmarshal(buf, &dec); err != nil {
+			t.Errorf("Test case %d (%s): cannot decode Message: %s\n%s",
+				idx,
+				args.Request(),
+				err.Error(),
+				buf)
+			continue
+		}
+
+		if dec.ID != msg.ID || dec.Request() != args.Request() {
+			t.Errorf("Test case %d: expected %s request %d, got %s request %d",
+				idx,
+				args.Request(),
+				msg.ID,
+				dec.Request(),
+				dec.ID)
+		} else if !reflect.DeepEqual(dec.Args, args) {
+			t.Errorf("Test case %d (%s): arguments were not preserved:\nExpected: %#v\nActual:   %#v",
+				idx,
+				args.Request(),
+				args,
+				dec.Args)
+		}
+	}
+
+	var (
+		err error
+		msg Message
+		e   *Error
+	)
+
+	if err = json.Unmarshal([]byte(`{"ID":17,"Request":"Frobnicate"}`), &msg); !errors.As(err, &e) {
+		t.Errorf("Decoding an unknown request should fail with an *Error, not %v", err)
+	} else if e.Code != errcode.UnknownRequest || msg.ID != 17 {
+		t.Errorf("Unexpected result for unknown request: %s, ID %d", e, msg.ID)
+	}
+
+	if _, err = json.Marshal(&Message{}); err == nil {
+		t.Error("Encoding a Message without a request should fail")
+	}
+
+	if msg = MakeMsg(&ScheduleListArgs{}); msg.Request() != request.ScheduleList {
+		t.Errorf("MakeMsg returned a %s request", msg.Request())
+	}
+} // func TestMessageJSON(t *testing.T)
+
+func TestMonQueryFilter(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		conn *Conn
+		j    *job.Job
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	for i := 0; i < 3; i++ {
+		if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+			t.Fatalf("Failed to create Job: %s", err.Error())
+		}
+
+		j.ID = submitJob(t, conn, j)
+	}
+
+	var res = sendMsg(t, conn, MakeMsg(&QueueQueryStatusArgs{IDs: []int64{j.ID, 999999}}))
+
+	if res.Error {
+		t.Errorf("Failed to query Job %d: %s", j.ID, res.Status)
+	} else if len(res.Jobs) != 1 || res.Jobs[0].ID != j.ID {
+		t.Errorf("Query for Job %d returned %d Jobs", j.ID, len(res.Jobs))
+	}
+} // func TestMonQueryFilter(t *testing.T)
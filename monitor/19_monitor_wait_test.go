@@ -0,0 +1,216 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/19_monitor_wait_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 23. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-23 16:40:27 krylon>
+
+package monitor
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
+	"github.com/blicero/jobq/monitor/errcode"
+)
+
+func TestMonWait(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err            error
+		conn           *Conn
+		j              *job.Job
+		fast, slow     int64
+		deferred       int64
+		res            Response
+		begin          time.Time
+		waitConn       *Conn
+		cancelled      = make(chan Response, 1)
+		deferredWaitID = uint64(4711)
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", "sleep 0.2; exit 3"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	fast = submitJob(t, conn, j)
+
+	if j, err = job.New(job.Options{}, "/bin/sh", "-c", "sleep 1"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	slow = submitJob(t, conn, j)
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Hour)
+	deferred = submitJob(t, conn, j)
+
+	// Any: we are done as soon as the first Job has finished.
+	res = sendMsg(t, conn, MakeMsg(&JobWaitArgs{IDs: []int64{slow, fast}, Any: true}))
+
+	if res.Error {
+		t.Errorf("Failed to wait for any of Jobs %d, %d: %s", slow, fast, res.Status)
+	} else if len(res.Jobs) != 2 || res.Jobs[0].ID != slow || res.Jobs[1].ID != fast {
+		t.Errorf("Unexpected Jobs in Response: %d", len(res.Jobs))
+	} else if res.Jobs[1].Status() != status.Finished || res.Jobs[1].ExitCode != 3 {
+		t.Errorf("Job %d should have finished with exit code 3: %s/%d",
+			fast,
+			res.Jobs[1].Status(),
+			res.Jobs[1].ExitCode)
+	} else if res.Jobs[0].Status().Final() {
+		t.Errorf("Job %d should not be done, yet: %s",
+			slow,
+			res.Jobs[0].Status())
+	}
+
+	// All: we wait for the slow one, too.
+	res = sendMsg(t, conn, MakeMsg(&JobWaitArgs{IDs: []int64{fast, slow}}))
+
+	if res.Error {
+		t.Errorf("Failed to wait for Jobs %d, %d: %s", fast, slow, res.Status)
+	} else if len(res.Jobs) != 2 {
+		t.Errorf("Unexpected Jobs in Response: %d", len(res.Jobs))
+	} else if !res.Jobs[0].Status().Final() || !res.Jobs[1].Status().Final() {
+		t.Errorf("Both Jobs should be done: %s, %s",
+			res.Jobs[0].Status(),
+			res.Jobs[1].Status())
+	}
+
+	// The deferred Job does not finish in time.
+	begin = time.Now()
+	res = sendMsg(t, conn, MakeMsg(&JobWaitArgs{
+		IDs:     []int64{deferred},
+		Timeout: time.Millisecond * 300,
+	}))
+
+	if res.Code != errcode.Timeout {
+		t.Errorf("Waiting for deferred Job %d should time out, not %s (%s)",
+			deferred,
+			res.Code,
+			res.Status)
+	} else if d := time.Since(begin); d < time.Millisecond*300 {
+		t.Errorf("Request timed out after only %s", d)
+	} else if len(res.Jobs) != 1 || res.Jobs[0].Status() != status.Deferred {
+		t.Errorf("Response should contain deferred Job %d", deferred)
+	}
+
+	res = sendMsg(t, conn, MakeMsg(&JobWaitArgs{IDs: []int64{deferred, 999999}}))
+
+	if res.Code != errcode.NotFound {
+		t.Errorf("Waiting for an unknown Job should fail with %s, not %s (%s)",
+			errcode.NotFound,
+			res.Code,
+			res.Status)
+	}
+
+	// Cancelling a Job wakes up whoever waits for it.
+	if waitConn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer waitConn.Close() // nolint: errcheck
+
+	go func() {
+		var msg = MakeMsg(&JobWaitArgs{IDs: []int64{deferred}})
+
+		msg.ID = deferredWaitID
+
+		if r, err := waitConn.Request(&msg); err == nil {
+			cancelled <- *r
+		}
+		close(cancelled)
+	}()
+
+	time.Sleep(time.Millisecond * 200)
+
+	if res = sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: deferred})); res.Error {
+		t.Fatalf("Failed to cancel Job %d: %s", deferred, res.Status)
+	}
+
+	select {
+	case r, ok := <-cancelled:
+		if !ok {
+			t.Errorf("Failed to wait for Job %d", deferred)
+		} else if r.Error || r.ID != deferredWaitID {
+			t.Errorf("Unexpected Response %d: %s", r.ID, r.Status)
+		} else if len(r.Jobs) != 1 || r.Jobs[0].Status() != status.Cancelled {
+			t.Errorf("Job %d should have been cancelled", deferred)
+		}
+	case <-time.After(time.Second * 5):
+		t.Errorf("Cancelling Job %d did not wake up waiting client", deferred)
+	}
+} // func TestMonWait(t *testing.T)
+
+func TestMonWaitHangup(t *testing.T) {
+	if mon == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err             error
+		conn            *Conn
+		j               *job.Job
+		id              int64
+		client, server  = net.Pipe()
+		handlerReturned = make(chan error, 1)
+	)
+
+	if conn, err = Dial(socketPath); err != nil {
+		t.Fatalf("Error connecting to Monitor %s: %s",
+			socketPath,
+			err.Error())
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	if j, err = job.New(job.Options{}, "/bin/true"); err != nil {
+		t.Fatalf("Failed to create Job: %s", err.Error())
+	}
+
+	j.NotBefore = time.Now().Add(time.Hour)
+	id = submitJob(t, conn, j)
+
+	defer sendMsg(t, conn, MakeMsg(&JobCancelArgs{JobID: id}))
+
+	// We call the handler directly, so we can tell when it returns.
+	go func() {
+		handlerReturned <- mon.jobWait(1, &JobWaitArgs{IDs: []int64{id}}, newConn(server))
+	}()
+
+	time.Sleep(time.Millisecond * 200)
+
+	select {
+	case <-handlerReturned:
+		t.Fatalf("Handler returned before Job %d was done", id)
+	default:
+	}
+
+	client.Close() // nolint: errcheck
+
+	select {
+	case err = <-handlerReturned:
+		if err != nil {
+			t.Errorf("Handler failed after client hung up: %s", err.Error())
+		}
+	case <-time.After(time.Second * 5):
+		t.Errorf("Handler did not notice the client hung up")
+	}
+} // func TestMonWaitHangup(t *testing.T)
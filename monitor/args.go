@@ -0,0 +1,335 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/args.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 22. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-22 15:12:40 krylon>
+
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/request"
+	"github.com/blicero/jobq/schedule"
+)
+
+// Args is the payload of a Message. There is one type of Args for each kind
+// of request the Monitor handles. Request returns the kind of request,
+// Validate checks the arguments before the Monitor acts on them.
+type Args interface {
+	Request() request.ID
+	Validate() error
+}
+
+// newArgs returns an empty Args value for the given kind of request, or nil
+// if the Monitor does not handle such requests.
+func newArgs(id request.ID) Args {
+	switch id {
+	case request.JobSubmit:
+		return new(JobSubmitArgs)
+	case request.JobCancel:
+		return new(JobCancelArgs)
+	case request.JobSetPriority:
+		return new(JobSetPriorityArgs)
+	case request.JobRerun:
+		return new(JobRerunArgs)
+	case request.JobOutput:
+		return new(JobOutputArgs)
+	case request.JobClear:
+		return new(JobClearArgs)
+	case request.JobWait:
+		return new(JobWaitArgs)
+	case request.QueueQueryStatus:
+		return new(QueueQueryStatusArgs)
+	case request.ScheduleCreate:
+		return new(ScheduleCreateArgs)
+	case request.ScheduleList:
+		return new(ScheduleListArgs)
+	case request.SchedulePause:
+		return new(SchedulePauseArgs)
+	case request.ScheduleResume:
+		return new(ScheduleResumeArgs)
+	case request.ScheduleDelete:
+		return new(ScheduleDeleteArgs)
+	default:
+		return nil
+	}
+} // func newArgs(id request.ID) Args
+
+func validJobID(id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("Invalid Job ID %d", id)
+	}
+
+	return nil
+} // func validJobID(id int64) error
+
+func validScheduleID(id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("Invalid Schedule ID %d", id)
+	}
+
+	return nil
+} // func validScheduleID(id int64) error
+
+// JobSubmitArgs are the arguments to submit a new Job.
+type JobSubmitArgs struct {
+	Job *job.Job
+}
+
+// Request returns request.JobSubmit.
+func (a *JobSubmitArgs) Request() request.ID {
+	return request.JobSubmit
+} // func (a *JobSubmitArgs) Request() request.ID
+
+// Validate checks that the Job has a command and valid Options.
+func (a *JobSubmitArgs) Validate() error {
+	if a.Job == nil || len(a.Job.Cmd) == 0 {
+		return errors.New("JobSubmit requires a Job with a command")
+	} else if err := a.Job.Options.Validate(); err != nil {
+		return err
+	}
+
+	return a.Job.ValidateDepends()
+} // func (a *JobSubmitArgs) Validate() error
+
+// JobCancelArgs are the arguments to cancel a Job.
+type JobCancelArgs struct {
+	JobID int64
+}
+
+// Request returns request.JobCancel.
+func (a *JobCancelArgs) Request() request.ID {
+	return request.JobCancel
+} // func (a *JobCancelArgs) Request() request.ID
+
+// Validate checks the Job ID.
+func (a *JobCancelArgs) Validate() error {
+	return validJobID(a.JobID)
+} // func (a *JobCancelArgs) Validate() error
+
+// JobSetPriorityArgs are the arguments to change the priority of a pending
+// Job.
+type JobSetPriorityArgs struct {
+	JobID    int64
+	Priority int
+}
+
+// Request returns request.JobSetPriority.
+func (a *JobSetPriorityArgs) Request() request.ID {
+	return request.JobSetPriority
+} // func (a *JobSetPriorityArgs) Request() request.ID
+
+// Validate checks the Job ID.
+func (a *JobSetPriorityArgs) Validate() error {
+	return validJobID(a.JobID)
+} // func (a *JobSetPriorityArgs) Validate() error
+
+// JobRerunArgs are the arguments to rerun a finished Job. If Override is not
+// empty, the fields it names are taken from Job instead of the original Job,
+// see job.Job.Override.
+type JobRerunArgs struct {
+	JobID    int64
+	Job      *job.Job `json:",omitempty"`
+	Override []string `json:",omitempty"`
+}
+
+// Request returns request.JobRerun.
+func (a *JobRerunArgs) Request() request.ID {
+	return request.JobRerun
+} // func (a *JobRerunArgs) Request() request.ID
+
+// Validate checks the Job ID, and that there is a Job to take overridden
+// fields from.
+func (a *JobRerunArgs) Validate() error {
+	if err := validJobID(a.JobID); err != nil {
+		return err
+	} else if len(a.Override) > 0 && a.Job == nil {
+		return errors.New("JobRerun requires a Job to take overridden fields from")
+	}
+
+	return nil
+} // func (a *JobRerunArgs) Validate() error
+
+// JobOutputArgs are the arguments to get the output of a Job. By default,
+// the Monitor sends the Job's stdout, if Stderr is true, it sends stderr
+// instead. For Jobs with OutputMerge, both streams are sent either way.
+// If Follow is true, the Monitor keeps sending output until the Job has
+// finished.
+// For Jobs that are retried, Attempt selects the attempt whose output to
+// send, starting at 1. If it is 0, the Monitor sends the current attempt's
+// output. With Follow, the Monitor waits for the attempt to start.
+type JobOutputArgs struct {
+	JobID   int64
+	Stderr  bool `json:",omitempty"`
+	Follow  bool `json:",omitempty"`
+	Attempt int  `json:",omitempty"`
+}
+
+// Request returns request.JobOutput.
+func (a *JobOutputArgs) Request() request.ID {
+	return request.JobOutput
+} // func (a *JobOutputArgs) Request() request.ID
+
+// Validate checks the Job ID and the Attempt.
+func (a *JobOutputArgs) Validate() error {
+	if a.Attempt < 0 {
+		return fmt.Errorf("Invalid attempt %d", a.Attempt)
+	}
+
+	return validJobID(a.JobID)
+} // func (a *JobOutputArgs) Validate() error
+
+// JobClearArgs are the arguments to remove finished Jobs. If Retention is
+// nil, all finished Jobs are removed.
+type JobClearArgs struct {
+	Retention *Retention `json:",omitempty"`
+}
+
+// Request returns request.JobClear.
+func (a *JobClearArgs) Request() request.ID {
+	return request.JobClear
+} // func (a *JobClearArgs) Request() request.ID
+
+// Validate checks that the limits of the Retention policy are not negative.
+func (a *JobClearArgs) Validate() error {
+	if r := a.Retention; r != nil && (r.Keep < 0 || r.MaxAge < 0 || r.MaxSize < 0) {
+		return fmt.Errorf("Invalid Retention policy %#v", *r)
+	}
+
+	return nil
+} // func (a *JobClearArgs) Validate() error
+
+// JobWaitArgs are the arguments to wait for Jobs to finish. The Monitor
+// answers once all of the Jobs listed in IDs are done, or any of them, if Any
+// is true. If Timeout is positive and the Jobs are not done by then, the
+// request fails with errcode.Timeout.
+type JobWaitArgs struct {
+	IDs     []int64
+	Any     bool          `json:",omitempty"`
+	Timeout time.Duration `json:",omitempty"`
+}
+
+// Request returns request.JobWait.
+func (a *JobWaitArgs) Request() request.ID {
+	return request.JobWait
+} // func (a *JobWaitArgs) Request() request.ID
+
+// Validate checks the Job IDs and the Timeout.
+func (a *JobWaitArgs) Validate() error {
+	if len(a.IDs) == 0 {
+		return errors.New("JobWait requires at least one Job ID")
+	} else if a.Timeout < 0 {
+		return fmt.Errorf("Invalid timeout %s", a.Timeout)
+	}
+
+	for _, id := range a.IDs {
+		if err := validJobID(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (a *JobWaitArgs) Validate() error
+
+// QueueQueryStatusArgs are the arguments to list the Jobs in the queue. If
+// IDs is not empty, only the Jobs it lists are returned.
+type QueueQueryStatusArgs struct {
+	IDs []int64 `json:",omitempty"`
+}
+
+// Request returns request.QueueQueryStatus.
+func (a *QueueQueryStatusArgs) Request() request.ID {
+	return request.QueueQueryStatus
+} // func (a *QueueQueryStatusArgs) Request() request.ID
+
+// Validate checks the Job IDs.
+func (a *QueueQueryStatusArgs) Validate() error {
+	for _, id := range a.IDs {
+		if err := validJobID(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (a *QueueQueryStatusArgs) Validate() error
+
+// ScheduleCreateArgs are the arguments to create a new Schedule.
+type ScheduleCreateArgs struct {
+	Schedule *schedule.Schedule
+}
+
+// Request returns request.ScheduleCreate.
+func (a *ScheduleCreateArgs) Request() request.ID {
+	return request.ScheduleCreate
+} // func (a *ScheduleCreateArgs) Request() request.ID
+
+// Validate checks the Schedule.
+func (a *ScheduleCreateArgs) Validate() error {
+	if a.Schedule == nil {
+		return errors.New("ScheduleCreate requires a Schedule")
+	}
+
+	return a.Schedule.Validate()
+} // func (a *ScheduleCreateArgs) Validate() error
+
+// ScheduleListArgs are the (lack of) arguments to list all Schedules.
+type ScheduleListArgs struct{}
+
+// Request returns request.ScheduleList.
+func (a *ScheduleListArgs) Request() request.ID {
+	return request.ScheduleList
+} // func (a *ScheduleListArgs) Request() request.ID
+
+// Validate always succeeds.
+func (a *ScheduleListArgs) Validate() error {
+	return nil
+} // func (a *ScheduleListArgs) Validate() error
+
+// SchedulePauseArgs are the arguments to pause a Schedule.
+type SchedulePauseArgs struct {
+	ScheduleID int64
+}
+
+// Request returns request.SchedulePause.
+func (a *SchedulePauseArgs) Request() request.ID {
+	return request.SchedulePause
+} // func (a *SchedulePauseArgs) Request() request.ID
+
+// Validate checks the Schedule ID.
+func (a *SchedulePauseArgs) Validate() error {
+	return validScheduleID(a.ScheduleID)
+} // func (a *SchedulePauseArgs) Validate() error
+
+// ScheduleResumeArgs are the arguments to resume a paused Schedule.
+type ScheduleResumeArgs struct {
+	ScheduleID int64
+}
+
+// Request returns request.ScheduleResume.
+func (a *ScheduleResumeArgs) Request() request.ID {
+	return request.ScheduleResume
+} // func (a *ScheduleResumeArgs) Request() request.ID
+
+// Validate checks the Schedule ID.
+func (a *ScheduleResumeArgs) Validate() error {
+	return validScheduleID(a.ScheduleID)
+} // func (a *ScheduleResumeArgs) Validate() error
+
+// ScheduleDeleteArgs are the arguments to delete a Schedule.
+type ScheduleDeleteArgs struct {
+	ScheduleID int64
+}
+
+// Request returns request.ScheduleDelete.
+func (a *ScheduleDeleteArgs) Request() request.ID {
+	return request.ScheduleDelete
+} // func (a *ScheduleDeleteArgs) Request() request.ID
+
+// Validate checks the Schedule ID.
+func (a *ScheduleDeleteArgs) Validate() error {
+	return validScheduleID(a.ScheduleID)
+} // func (a *ScheduleDeleteArgs) Validate() error
@@ -0,0 +1,245 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/conn.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 22. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-22 10:41:17 krylon>
+
+package monitor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/monitor/errcode"
+)
+
+// ProtocolVersion is the version of the protocol the Monitor and its clients
+// speak. Both sides send it in a Hello when a connection is opened, and the
+// Monitor refuses clients that speak a different version.
+const ProtocolVersion = 1
+
+// maxFrameSize is the size of the largest frame we accept. Anything larger
+// is almost certainly garbage, and we do not want to allocate a buffer for
+// it.
+const maxFrameSize = 64 << 20 // 64 MiB
+
+// ErrFrameTooLarge indicates that the peer sent a frame larger than
+// maxFrameSize. Since we do not read it, the connection cannot be used any
+// further.
+//
+// ErrMalformed indicates that a frame could not be decoded. The connection
+// is still usable.
+var (
+	ErrFrameTooLarge = errors.New("Frame is too large")
+	ErrMalformed     = errors.New("Malformed frame")
+)
+
+// Hello is the first frame sent in either direction on a new connection.
+// Name identifies the client or the Monitor's queue. If the Monitor refuses
+// the client, it sets Code and Status to tell why and closes the connection.
+type Hello struct {
+	Version int
+	Name    string
+	Code    errcode.Code `json:",omitempty"`
+	Status  string       `json:",omitempty"`
+}
+
+// Conn is a connection between a client and the Monitor.
+// Messages and Responses are sent as frames, each frame consists of its
+// length as a 32 bit unsigned integer in network byte order, followed by
+// that many bytes of JSON.
+type Conn struct {
+	conn   net.Conn
+	rd     *bufio.Reader
+	wlock  sync.Mutex
+	lastID atomic.Uint64
+	peer   Hello
+}
+
+func newConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn: conn,
+		rd:   bufio.NewReader(conn),
+	}
+} // func newConn(conn net.Conn) *Conn
+
+// Dial connects to the Monitor listening on the Unix socket at path and
+// performs the handshake.
+func Dial(path string) (*Conn, error) {
+	var (
+		err error
+		nc  net.Conn
+		c   *Conn
+	)
+
+	if nc, err = net.Dial(common.NetName, path); err != nil {
+		return nil, err
+	}
+
+	c = newConn(nc)
+
+	if err = c.writeFrame(&Hello{Version: ProtocolVersion, Name: common.AppName}); err != nil {
+		nc.Close() // nolint: errcheck
+		return nil, err
+	} else if err = c.readFrame(&c.peer); err != nil {
+		nc.Close() // nolint: errcheck
+		return nil, err
+	} else if c.peer.Code != errcode.OK {
+		nc.Close() // nolint: errcheck
+		return nil, &Error{Code: c.peer.Code, Status: c.peer.Status}
+	}
+
+	return c, nil
+} // func Dial(path string) (*Conn, error)
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+} // func (c *Conn) Close() error
+
+// SetDeadline sets the deadline for reading from and writing to the
+// connection, see net.Conn. A zero value means no deadline.
+func (c *Conn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+} // func (c *Conn) SetDeadline(t time.Time) error
+
+// Peer returns the Hello the other side sent when the connection was opened.
+func (c *Conn) Peer() Hello {
+	return c.peer
+} // func (c *Conn) Peer() Hello
+
+// Send sends a Message to the Monitor. If the Message has no ID, yet, Send
+// assigns one.
+func (c *Conn) Send(msg *Message) error {
+	if msg.ID == 0 {
+		msg.ID = c.lastID.Add(1)
+	}
+
+	return c.writeFrame(msg)
+} // func (c *Conn) Send(msg *Message) error
+
+// Receive reads the next Response from the Monitor.
+func (c *Conn) Receive() (*Response, error) {
+	var res = new(Response)
+
+	if err := c.readFrame(res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+} // func (c *Conn) Receive() (*Response, error)
+
+// Request sends a Message to the Monitor and returns its Response. For
+// requests that are answered by more than one Response, Request returns the
+// first one, the caller must Receive the others.
+func (c *Conn) Request(msg *Message) (*Response, error) {
+	var (
+		err error
+		res *Response
+	)
+
+	if err = c.Send(msg); err != nil {
+		return nil, err
+	} else if res, err = c.Receive(); err != nil {
+		return nil, err
+	} else if res.ID != msg.ID {
+		return nil, fmt.Errorf("Response %d does not match request %d",
+			res.ID,
+			msg.ID)
+	}
+
+	return res, nil
+} // func (c *Conn) Request(msg *Message) (*Response, error)
+
+// writeFrame sends v as a frame.
+func (c *Conn) writeFrame(v any) error {
+	var (
+		err error
+		buf []byte
+	)
+
+	if buf, err = json.Marshal(v); err != nil {
+		return err
+	} else if len(buf) > maxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	var frame = make([]byte, 4, 4+len(buf))
+	binary.BigEndian.PutUint32(frame, uint32(len(buf)))
+	frame = append(frame, buf...)
+
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+
+	_, err = c.conn.Write(frame)
+	return err
+} // func (c *Conn) writeFrame(v any) error
+
+// readFrame reads the next frame and decodes it into v.
+// If the peer closed the connection, it returns io.EOF.
+func (c *Conn) readFrame(v any) error {
+	var (
+		err  error
+		size uint32
+		hdr  [4]byte
+		buf  []byte
+	)
+
+	if _, err = io.ReadFull(c.rd, hdr[:]); err != nil {
+		return err
+	} else if size = binary.BigEndian.Uint32(hdr[:]); size > maxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	buf = make([]byte, size)
+
+	if _, err = io.ReadFull(c.rd, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	} else if err = json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	return nil
+} // func (c *Conn) readFrame(v any) error
+
+// watchHangup notices if the peer closes the connection while we are not
+// reading from it, e.g. because a request takes a long time to answer. The
+// returned channel is closed if the peer hangs up. Anything the peer sends
+// in the meantime is left for the next readFrame. The returned function
+// must be called before reading from the connection again.
+func (c *Conn) watchHangup() (<-chan struct{}, func()) {
+	var (
+		hangup = make(chan struct{})
+		exited = make(chan struct{})
+	)
+
+	go func() {
+		defer close(exited)
+
+		// Peek does not consume anything, so we do not get in the way
+		// of readFrame.
+		if _, err := c.rd.Peek(1); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			close(hangup)
+		}
+	}()
+
+	return hangup, func() {
+		// A deadline in the past interrupts the pending Peek.
+		c.conn.SetReadDeadline(time.Unix(1, 0)) // nolint: errcheck
+		<-exited
+		c.conn.SetReadDeadline(time.Time{}) // nolint: errcheck
+	}
+} // func (c *Conn) watchHangup() (<-chan struct{}, func())
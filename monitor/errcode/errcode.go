@@ -0,0 +1,40 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/errcode/errcode.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 22. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-22 09:12:40 krylon>
+
+// Package errcode defines the error codes the Monitor sends to its clients
+// to indicate why a request failed.
+package errcode
+
+//go:generate stringer -type=Code
+
+// Code identifies the kind of error that made a request fail.
+type Code uint8
+
+// OK means the request was carried out.
+// BadRequest means the Monitor could not decode the request.
+// UnknownRequest means the Monitor does not know the type of request.
+// InvalidArgument means the arguments or the payload of the request are
+// missing or invalid.
+// NotFound means the Job or Schedule the request refers to does not exist.
+// InvalidState means the Job or Schedule is not in a state that permits the
+// request, e.g. cancelling a Job that has finished already.
+// Internal means the Monitor failed to carry out a valid request, e.g.
+// because of a database error.
+// VersionMismatch means the client and the Monitor do not speak the same
+// version of the protocol.
+// Timeout means the request could not be carried out in the time the client
+// allowed for it, e.g. the Jobs a client waits for did not finish in time.
+const (
+	OK Code = iota
+	BadRequest
+	UnknownRequest
+	InvalidArgument
+	NotFound
+	InvalidState
+	Internal
+	VersionMismatch
+	Timeout
+)
@@ -7,32 +7,164 @@
 package monitor
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+	"github.com/blicero/jobq/monitor/request"
+	"github.com/blicero/jobq/schedule"
 )
 
 // Message is data format for communication between client and server.
+// ID identifies the request, the Monitor's Response carries the same ID. It
+// is assigned by Conn.Send, unless the caller has set it.
+// Args determines the kind of request and carries its arguments.
 type Message struct {
+	ID        uint64
+	Timestamp time.Time
+	Args      Args
+}
+
+// wireMessage is how a Message is encoded. Since Args is an interface, we
+// need to send the kind of request along with it, so the Monitor knows what
+// type to decode it into.
+type wireMessage struct {
+	ID        uint64
 	Timestamp time.Time
-	Job       *job.Job
 	Request   string
+	Args      json.RawMessage `json:",omitempty"`
 }
 
 // MakeMsg returns a new message.
-func MakeMsg(req string, j *job.Job) Message {
+func MakeMsg(args Args) Message {
 	msg := Message{
 		Timestamp: time.Now(),
-		Job:       j,
-		Request:   req,
+		Args:      args,
 	}
 	return msg
-} // func MakeMsg(req string, j *job.Job)
+} // func MakeMsg(args Args) Message
+
+// Request returns the kind of request the Message carries.
+func (msg *Message) Request() request.ID {
+	if msg.Args == nil {
+		return request.Invalid
+	}
+
+	return msg.Args.Request()
+} // func (msg *Message) Request() request.ID
+
+// MarshalJSON implements json.Marshaler.
+func (msg Message) MarshalJSON() ([]byte, error) {
+	var (
+		err  error
+		wire = wireMessage{
+			ID:        msg.ID,
+			Timestamp: msg.Timestamp,
+		}
+	)
+
+	if msg.Args == nil {
+		return nil, errors.New("Message has no request")
+	} else if wire.Args, err = json.Marshal(msg.Args); err != nil {
+		return nil, err
+	}
+
+	wire.Request = msg.Args.Request().String()
+
+	return json.Marshal(&wire)
+} // func (msg Message) MarshalJSON() ([]byte, error)
+
+// UnmarshalJSON implements json.Unmarshaler. If the Message is for a kind of
+// request the Monitor does not handle, it returns an *Error with
+// errcode.UnknownRequest.
+// The ID of the Message is set even if the rest cannot be decoded, so the
+// Monitor can tell the client which request it failed to understand.
+func (msg *Message) UnmarshalJSON(buf []byte) error {
+	var (
+		err  error
+		id   request.ID
+		wire wireMessage
+	)
+
+	if err = json.Unmarshal(buf, &wire); err != nil {
+		return err
+	}
+
+	msg.ID = wire.ID
+	msg.Timestamp = wire.Timestamp
+
+	if id, err = request.Parse(wire.Request); err != nil {
+		return &Error{Code: errcode.UnknownRequest, Status: err.Error()}
+	} else if msg.Args = newArgs(id); msg.Args == nil {
+		return &Error{
+			Code:   errcode.UnknownRequest,
+			Status: fmt.Sprintf("Request %s is not supported", id),
+		}
+	} else if len(wire.Args) > 0 {
+		if err = json.Unmarshal(wire.Args, msg.Args); err != nil {
+			return fmt.Errorf("Cannot decode arguments for %s: %w",
+				id,
+				err)
+		}
+	}
+
+	return nil
+} // func (msg *Message) UnmarshalJSON(buf []byte) error
 
 // Response is the basic response the Monitor sends after handling a Message.
+// ID is the ID of the Message the Response answers.
+// Error is true if the Monitor could not carry out the request, in which case
+// Code tells what kind of error occurred, and Status contains the reason.
+// Jobs and Schedules contain the Jobs and Schedules the request refers to,
+// if any.
+// Output contains a piece of a Job's output. If More is true, the Response is
+// followed by another one, until the Monitor sends a Response with More set
+// to false.
 type Response struct {
+	ID        uint64
 	Timestamp time.Time
 	Sequence  int64
 	Status    string
+	Error     bool
+	Code      errcode.Code `json:",omitempty"`
 	Jobs      []job.Job
+	Schedules []schedule.Schedule `json:",omitempty"`
+	Output    []byte              `json:",omitempty"`
+	More      bool                `json:",omitempty"`
+}
+
+// Err returns an *Error if the Response indicates the request failed, nil
+// otherwise.
+func (r *Response) Err() error {
+	if !r.Error {
+		return nil
+	}
+
+	return &Error{Code: r.Code, Status: r.Status}
+} // func (r *Response) Err() error
+
+// Error is the error returned when the Monitor could not carry out a
+// request.
+type Error struct {
+	Code   errcode.Code
+	Status string
 }
+
+func (e *Error) Error() string {
+	if e.Status == "" {
+		return e.Code.String()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Status)
+} // func (e *Error) Error() string
+
+// Is returns true if target is an *Error with the same Code and either the
+// same or no Status, so errors.Is can be used to check for a kind of error.
+func (e *Error) Is(target error) bool {
+	var t, ok = target.(*Error)
+
+	return ok && t.Code == e.Code && (t.Status == "" || t.Status == e.Status)
+} // func (e *Error) Is(target error) bool
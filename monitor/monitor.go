@@ -8,7 +8,7 @@
 package monitor
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,16 +16,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/blicero/jobq/common"
 	"github.com/blicero/jobq/database"
 	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/job/status"
 	"github.com/blicero/jobq/logdomain"
+	"github.com/blicero/jobq/monitor/errcode"
 	"github.com/blicero/jobq/monitor/request"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/google/shlex"
 )
 
 const minDbCnt = 4
@@ -42,6 +44,15 @@ type Monitor struct {
 	seqCnt    atomic.Int64
 	jobq      chan int
 	jobTicker *time.Ticker
+	runLock   sync.Mutex
+	running   map[int64]*job.Job
+	aging     time.Duration
+	retention Retention
+	hook      string
+	fifo      string
+	schedq    chan int
+	doneLock  sync.Mutex
+	done      chan struct{}
 }
 
 // Create creates and returns a new Monitor.
@@ -52,8 +63,10 @@ func Create(name, sock string, slots int) (*Monitor, error) {
 			name:      name,
 			path:      sock,
 			slots:     slots,
-			jobq:      make(chan int),
 			jobTicker: time.NewTicker(time.Minute * 5),
+			running:   make(map[int64]*job.Job),
+			schedq:    make(chan int, 1),
+			done:      make(chan struct{}),
 		}
 		addr = net.UnixAddr{
 			Name: sock,
@@ -63,12 +76,23 @@ func Create(name, sock string, slots int) (*Monitor, error) {
 
 	if m.log, err = common.GetLogger(logdomain.Monitor); err != nil {
 		return nil, err
-	} else if m.pool, err = database.NewPool(minDbCnt); err != nil {
+	} else if slots < 1 {
+		m.log.Printf("[ERROR] Invalid number of slots: %d\n",
+			slots)
+		return nil, fmt.Errorf("Number of slots must be positive, not %d",
+			slots)
+	}
+
+	// Each idle worker waits on jobq, so we want to be able to wake up
+	// all of them without blocking the sender.
+	m.jobq = make(chan int, slots)
+
+	if m.pool, err = database.NewPool(minDbCnt); err != nil {
 		m.log.Printf("[ERROR] Cannot open database at %s: %s\n",
 			common.DbPath,
 			err.Error())
 		return nil, err
-	} else if m.ctl, err = net.ListenUnix("unixpacket", &addr); err != nil {
+	} else if m.ctl, err = net.ListenUnix(common.NetName, &addr); err != nil {
 		m.log.Printf("[ERROR] Cannot open control socket %s: %s\n",
 			sock,
 			err.Error())
@@ -78,15 +102,21 @@ func Create(name, sock string, slots int) (*Monitor, error) {
 	return m, nil
 } // func Create(name, sock string, slots int) (*Monitor, error)
 
+// SetAging sets the interval after which the priority of a pending Job is
+// raised by one. Zero, the default, means priorities do not change over time.
+// It must be called before the Monitor is started.
+func (m *Monitor) SetAging(d time.Duration) {
+	m.aging = d
+} // func (m *Monitor) SetAging(d time.Duration)
+
+// jobTick wakes up an idle worker, if there is one.
+// If the channel's buffer is full, there are enough wakeup calls pending
+// already, so we do not need to wait.
 func (m *Monitor) jobTick() {
-	var t = time.NewTimer(time.Second * 10)
 	select {
 	case m.jobq <- 1:
-		if !t.Stop() {
-			<-t.C
-		}
-	case <-t.C:
-		m.log.Printf("[ERROR] Timeout waiting to send job signal\n")
+	default:
+		m.log.Printf("[TRACE] Job signal queue is full, all workers are busy\n")
 	}
 } // func (m *Monitor) jobTick()
 
@@ -99,13 +129,28 @@ func (m *Monitor) Start() {
 
 	m.active.Store(true)
 
+	// This must happen before the workers start, so they do not
+	// overcommit the slots with Jobs we are about to adopt.
+	m.recoverJobs()
+
 	go m.ctlLoop()
-	go m.jobLoop()
+	go m.schedLoop()
+
+	if !m.retention.IsZero() {
+		go m.sweepLoop()
+	}
+
+	for i := 0; i < m.slots; i++ {
+		go m.jobLoop(i)
+	}
 } // func (m *Monitor) Start()
 
 // Stop tells the Monitor to stop.
 func (m *Monitor) Stop() {
 	m.active.Store(false)
+	// Wake up clients waiting for Jobs, so they notice we are shutting
+	// down.
+	m.jobsDone()
 } // func (m *Monitor) Stop()
 
 // Active returns the Monitor's active flag
@@ -125,187 +170,546 @@ func (m *Monitor) ctlLoop() {
 		if conn, err = m.ctl.AcceptUnix(); err != nil {
 			m.log.Printf("[ERROR] Error accepting new connection: %s\n",
 				err.Error())
+			continue
 		}
 
-		go m.handleClient(conn)
+		go m.handleClient(newConn(conn))
 	}
 } // func (m *Monitor) ctlLoop()
 
-func (m *Monitor) handleClient(client *net.UnixConn) {
+// handshake reads the client's Hello and answers with our own. If the client
+// speaks a different version of the protocol, it is told so, and handshake
+// returns an error.
+func (m *Monitor) handshake(client *Conn) error {
+	var (
+		err   error
+		hello = Hello{Version: ProtocolVersion, Name: m.name}
+	)
+
+	if err = client.readFrame(&client.peer); err != nil {
+		m.log.Printf("[ERROR] Failed to read Hello from client: %s\n",
+			err.Error())
+		return err
+	} else if client.peer.Version != ProtocolVersion {
+		hello.Code = errcode.VersionMismatch
+		hello.Status = fmt.Sprintf("Protocol version %d is not supported, Monitor speaks version %d",
+			client.peer.Version,
+			ProtocolVersion)
+		m.log.Printf("[ERROR] Refusing client %q: %s\n",
+			client.peer.Name,
+			hello.Status)
+		client.writeFrame(&hello) // nolint: errcheck
+		return &Error{Code: hello.Code, Status: hello.Status}
+	}
+
+	return client.writeFrame(&hello)
+} // func (m *Monitor) handshake(client *Conn) error
+
+func (m *Monitor) handleClient(client *Conn) {
 	const maxErr = 5
 	var (
-		err         error
-		msg         Message
-		cnt, errcnt int
-		buffer      = make([]byte, common.BufferSize)
+		err    error
+		errcnt int
 	)
 
 	defer client.Close() // nolint: errcheck
 
+	if err = m.handshake(client); err != nil {
+		return
+	}
+
 	for m.active.Load() && errcnt < maxErr {
-		if cnt, err = client.Read(buffer); err != nil {
+		var msg Message
+
+		if err = client.readFrame(&msg); err != nil {
 			if err == io.EOF {
 				m.log.Println("[INFO] Client closed connection.")
 				break
+			} else if !errors.Is(err, ErrMalformed) {
+				// We cannot tell where the next frame starts.
+				m.log.Printf("[ERROR] Failed to read from Client: %s\n",
+					err.Error())
+				break
 			}
-			m.log.Printf("[ERROR] Failed to read from Client: %s\n",
+
+			m.log.Printf("[ERROR] Failed to decode message: %s\n",
 				err.Error())
-			errcnt++
-			continue
-		} else if err = json.Unmarshal(buffer[:cnt], &msg); err != nil {
-			m.log.Printf("[ERROR] Failed to decode message: %s\nRaw: %s\n\n",
-				err.Error(),
-				string(buffer[:cnt]))
-			errcnt++
+
+			var (
+				e    *Error
+				code = errcode.BadRequest
+			)
+
+			// A request we do not know is not the client's fault, it
+			// may just be newer than we are. A client that keeps
+			// sending garbage is cut off.
+			if errors.As(err, &e) {
+				code = e.Code
+			} else {
+				errcnt++
+			}
+
+			var res = m.makeError(code, err.Error())
+			if err = m.sendResponse(client, msg.ID, &res); err != nil {
+				break
+			}
 			continue
 		} else if err = m.handleMessage(msg, client); err != nil {
-			m.log.Printf("[ERROR] Error handling message from %s: %s\n",
-				client.RemoteAddr(),
+			m.log.Printf("[ERROR] Error handling message from client %q: %s\n",
+				client.peer.Name,
 				err.Error())
 			errcnt++
 			continue
 		}
 	}
-} // func (m *Monitor) handleClient(client net.Conn)
+} // func (m *Monitor) handleClient(client *Conn)
 
-func (m *Monitor) handleMessage(msg Message, conn *net.UnixConn) error {
+func (m *Monitor) handleMessage(msg Message, conn *Conn) error {
 	m.log.Printf("[DEBUG] Handle message: %s\n",
 		spew.Sdump(&msg))
 
 	var (
 		err error
-		req []string
-		cmd request.ID
 		res Response
 		str string
 	)
 
-	if req, err = shlex.Split(msg.Request); err != nil {
-		m.log.Printf("[ERROR] Cannot parse Request: %s\nRaw: %s\n",
-			err.Error(),
-			msg.Request)
-		return err
-	} else if cmd, err = request.Parse(req[0]); err != nil {
-		m.log.Printf("[ERROR] Don't understand request %q: %s\n",
-			req[0],
+	if err = msg.Args.Validate(); err != nil {
+		str = fmt.Sprintf("Invalid arguments for %s: %s",
+			msg.Request(),
 			err.Error())
-		return err
+		m.log.Printf("[ERROR] %s\n", str)
+		res = m.makeError(errcode.InvalidArgument, str)
+		return m.sendResponse(conn, msg.ID, &res)
+	}
+
+	// Streaming a Job's output or waiting for Jobs may take a long time,
+	// so they must not hold on to a database connection.
+	switch args := msg.Args.(type) {
+	case *JobOutputArgs:
+		return m.jobOutput(msg.ID, args, conn)
+	case *JobWaitArgs:
+		return m.jobWait(msg.ID, args, conn)
 	}
 
 	var db = m.pool.Get()
 	defer m.pool.Put(db)
 
-	switch cmd {
-	case request.JobSubmit:
-		msg.Job.TimeSubmitted = time.Now()
-		if err = db.JobSubmit(msg.Job); err != nil {
-			str = fmt.Sprintf("Failed to submit Job: %s",
-				err.Error())
-			m.log.Printf("[ERROR] %s\n", str)
-			res = m.makeResponse(str)
-		} else {
-			str = fmt.Sprintf("Job submitted, Job ID is %d",
-				msg.Job.ID)
-			m.log.Printf("[DEBUG] %s\n", str)
-			res = m.makeResponse(str)
-			go m.jobTick()
-		}
-	case request.JobCancel:
-		var response = "Job cancellation is not implemented, yet."
-		res = m.makeResponse(response)
-		// FIXME: I already wrote most of the code, but I commented it
-		// out and moved it to the end of the file for readibility.
-	case request.JobClear:
-		// remove all finished jobs the database.
-		// m( I cannot just delete the finished jobs, I need to get
-		// them from the database to get the names of the spool files,
-		// delete those, THEN I can delete the jobs from the database.
-		var jobs []job.Job
-
-		if jobs, err = db.JobGetFinished(-1); err != nil {
-			str = fmt.Sprintf("Error loading finished Jobs from database: %s",
-				err.Error())
-			m.log.Printf("[ERROR] %s\n",
-				err.Error())
-			res = m.makeResponse(str)
-		}
+	switch args := msg.Args.(type) {
+	case *JobSubmitArgs:
+		res = m.jobSubmit(db, args.Job)
+	case *JobRerunArgs:
+		res = m.jobRerun(db, args)
+	case *JobCancelArgs:
+		res = m.jobCancel(db, args.JobID)
+	case *JobSetPriorityArgs:
+		res = m.jobSetPriority(db, args.JobID, args.Priority)
+	case *JobClearArgs:
+		// Without a Retention policy, all finished Jobs are removed.
+		var (
+			r       Retention
+			removed []job.Job
+			freed   int64
+		)
 
-		for _, j := range jobs {
-			if err = os.Remove(j.SpoolOut); err != nil {
-				str = fmt.Sprintf("Cannot delete spool file %q: %s",
-					j.SpoolOut,
-					err.Error())
-				m.log.Printf("[ERROR] %s\n",
-					err.Error())
-				res = m.makeResponse(str)
-				break
-			} else if err = os.Remove(j.SpoolErr); err != nil {
-				str = fmt.Sprintf("Cannot delete spool file %q: %s",
-					j.SpoolOut,
-					err.Error())
-				m.log.Printf("[ERROR] %s\n",
-					err.Error())
-				res = m.makeResponse(str)
-				break
-			} else if err = db.JobDelete(&j); err != nil {
-				str = fmt.Sprintf("Failed to remove Job %d from database: %s",
-					j.ID,
-					err.Error())
-				m.log.Printf("[ERROR] %s\n",
-					err.Error())
-				res = m.makeResponse(str)
-				break
-			}
+		if args.Retention != nil {
+			r = *args.Retention
 		}
 
-		if err == nil {
-			str = fmt.Sprintf("Removed %d finished Jobs from database",
-				len(jobs))
+		if removed, freed, err = m.sweep(db, &r); err != nil {
+			str = fmt.Sprintf("Removed %d finished Jobs, but some could not be removed: %s",
+				len(removed),
+				err.Error())
+			res = m.makeError(errcode.Internal, str)
+		} else {
+			str = fmt.Sprintf("Removed %d finished Jobs from database, freed %d bytes",
+				len(removed),
+				freed)
 			res = m.makeResponse(str)
 		}
-	case request.QueueQueryStatus:
-		var jobs []job.Job
+		res.Jobs = removed
+	case *ScheduleCreateArgs:
+		res = m.scheduleCreate(db, args.Schedule)
+	case *ScheduleListArgs:
+		res = m.scheduleList(db)
+	case *SchedulePauseArgs:
+		res = m.scheduleUpdate(db, request.SchedulePause, args.ScheduleID)
+	case *ScheduleResumeArgs:
+		res = m.scheduleUpdate(db, request.ScheduleResume, args.ScheduleID)
+	case *ScheduleDeleteArgs:
+		res = m.scheduleUpdate(db, request.ScheduleDelete, args.ScheduleID)
+	case *QueueQueryStatusArgs:
+		var (
+			jobs []job.Job
+			runs map[int64][]job.Run
+		)
+
 		if jobs, err = db.JobGetAll(); err != nil {
 			str = fmt.Sprintf("Failed to query all Jobs: %s",
 				err.Error())
 			m.log.Printf("[ERROR] %s\n", str)
-			res = m.makeResponse(str)
+			res = m.makeError(errcode.Internal, str)
+		} else if runs, err = db.RunGetAll(); err != nil {
+			str = fmt.Sprintf("Failed to query the history of Jobs: %s",
+				err.Error())
+			m.log.Printf("[ERROR] %s\n", str)
+			res = m.makeError(errcode.Internal, str)
 		} else {
+			jobs = filterJobs(jobs, args.IDs)
+			for idx := range jobs {
+				jobs[idx].Runs = runs[jobs[idx].ID]
+			}
 			res = m.makeResponse("OK")
 			res.Jobs = jobs
 		}
 	default:
-		str = fmt.Sprintf("I don't know how to handle %s", cmd)
+		str = fmt.Sprintf("I don't know how to handle %s", msg.Request())
 		m.log.Printf("[INFO] %s\n", str)
-		res = m.makeResponse(str)
+		res = m.makeError(errcode.UnknownRequest, str)
+	}
+
+	return m.sendResponse(conn, msg.ID, &res)
+} // func (m *Monitor) handleMessage(msg Message, conn *Conn) error
+
+// filterJobs returns the Jobs whose IDs are listed in ids, or all Jobs, if
+// ids is empty.
+func filterJobs(jobs []job.Job, ids []int64) []job.Job {
+	if len(ids) == 0 {
+		return jobs
 	}
 
 	var (
-		buf  []byte
-		cnt  int
-		addr = conn.RemoteAddr()
+		wanted   = make(map[int64]bool, len(ids))
+		filtered = make([]job.Job, 0, len(ids))
 	)
 
-	if buf, err = json.Marshal(&res); err != nil {
-		m.log.Printf("[ERROR] Cannot serialize Response to %s: %s\n",
-			addr,
-			err.Error())
-		return err
-	} else if cnt, err = conn.Write(buf); err != nil {
-		m.log.Printf("[ERROR] Failed to send Response to %s: %s\n",
-			addr,
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	for _, j := range jobs {
+		if wanted[j.ID] {
+			filtered = append(filtered, j)
+		}
+	}
+
+	return filtered
+} // func filterJobs(jobs []job.Job, ids []int64) []job.Job
+
+// sendResponse sends the Response to the request with the given ID to a
+// client.
+func (m *Monitor) sendResponse(conn *Conn, id uint64, res *Response) error {
+	res.ID = id
+
+	if err := conn.writeFrame(res); err != nil {
+		m.log.Printf("[ERROR] Failed to send Response to client %q: %s\n",
+			conn.peer.Name,
 			err.Error())
 		return err
-	} else if cnt != len(buf) {
-		// In this day and age, this shouldn't happen, now, should it?
-		m.log.Printf("[ERROR] Unexpected number of bytes sent in response: %d (expected %d)\n",
-			cnt,
-			len(buf))
-		return err
 	}
 
 	return nil
-} // func (m *Monitor) handleMessage(msg Message, conn *net.UnixConn) error
+} // func (m *Monitor) sendResponse(conn *Conn, id uint64, res *Response) error
+
+// jobSubmit validates a new Job and adds it to the queue.
+func (m *Monitor) jobSubmit(db *database.Database, j *job.Job) Response {
+	var (
+		err error
+		str string
+	)
+
+	if err = j.Options.Validate(); err != nil {
+		str = fmt.Sprintf("Invalid Job: %s", err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.InvalidArgument, str)
+	} else if err = j.ValidateDepends(); err != nil {
+		str = fmt.Sprintf("Invalid Job: %s", err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.InvalidArgument, str)
+	}
+
+	j.TimeSubmitted = time.Now()
+	if err = db.JobSubmit(j); err != nil {
+		str = fmt.Sprintf("Failed to submit Job: %s",
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	}
+
+	str = fmt.Sprintf("Job submitted, Job ID is %d",
+		j.ID)
+	m.log.Printf("[DEBUG] %s\n", str)
+
+	var res = m.makeResponse(str)
+	res.Jobs = []job.Job{*j}
+	// The Job may depend on a Job that has failed already.
+	if len(j.Depends) > 0 {
+		m.resolveDependencies(db)
+	}
+	go m.jobTick()
+	return res
+} // func (m *Monitor) jobSubmit(db *database.Database, j *job.Job) Response
+
+// jobRerun handles a request to submit a copy of a Job that has finished.
+// If args.Override is not empty, the fields it names are taken from args.Job
+// instead of the original Job.
+func (m *Monitor) jobRerun(db *database.Database, args *JobRerunArgs) Response {
+	var (
+		err  error
+		str  string
+		orig *job.Job
+		j    *job.Job
+		jid  = args.JobID
+	)
+
+	if orig, err = db.JobGetByID(jid); err != nil {
+		str = fmt.Sprintf("Error looking up Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if orig == nil {
+		str = fmt.Sprintf("Did not find Job %d in database",
+			jid)
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.NotFound, str)
+	} else if st := orig.Status(); st == status.Enqueued || st == status.Deferred || st == status.Started {
+		str = fmt.Sprintf("Job %d has not finished, yet (%s)",
+			jid,
+			st)
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.InvalidState, str)
+	}
+
+	j = orig.Clone()
+
+	if len(args.Override) > 0 {
+		if err = j.Override(args.Job, args.Override...); err != nil {
+			str = fmt.Sprintf("Cannot rerun Job %d: %s",
+				jid,
+				err.Error())
+			m.log.Printf("[ERROR] %s\n", str)
+			return m.makeError(errcode.InvalidArgument, str)
+		} else if len(j.Cmd) == 0 {
+			str = "JobRerun requires a Job with a command"
+			m.log.Printf("[ERROR] %s\n", str)
+			return m.makeError(errcode.InvalidArgument, str)
+		}
+	}
+
+	m.log.Printf("[INFO] Rerunning Job %d\n", jid)
+
+	return m.jobSubmit(db, j)
+} // func (m *Monitor) jobRerun(db *database.Database, args *JobRerunArgs) Response
+
+// spoolFiles returns the spool files of all attempts to run a Job.
+func spoolFiles(j *job.Job, runs []job.Run) []string {
+	var (
+		paths = make([]string, 0, 2*len(runs)+2)
+		seen  = make(map[string]bool, 2*len(runs)+2)
+	)
+
+	for _, p := range []string{j.SpoolOut, j.SpoolErr} {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	for _, r := range runs {
+		for _, p := range []string{r.SpoolOut, r.SpoolErr} {
+			if p != "" && !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	return paths
+} // func spoolFiles(j *job.Job, runs []job.Run) []string
+
+// removeSpool removes spool files. Jobs that were cancelled before they were
+// started have no spool files, and Jobs whose output is merged have only
+// one, so an empty path is not an error, and neither is a file that does
+// not exist. removeSpool tries to remove all files, even if it fails to
+// remove one of them, and returns the first error it encounters.
+func removeSpool(paths ...string) error {
+	var err error
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		} else if rerr := os.Remove(path); rerr != nil && !os.IsNotExist(rerr) && err == nil {
+			err = rerr
+		}
+	}
+
+	return err
+} // func removeSpool(paths ...string) error
+
+// jobCancel handles a request to cancel a Job. Jobs that have not been
+// started, yet, are simply marked as cancelled, Jobs that are currently
+// running get terminated.
+func (m *Monitor) jobCancel(db *database.Database, jid int64) Response {
+	var (
+		err error
+		ok  bool
+		str string
+		j   *job.Job
+	)
+
+	if j, err = db.JobGetByID(jid); err != nil {
+		str = fmt.Sprintf("Error looking up Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if j == nil {
+		str = fmt.Sprintf("Did not find Job %d in database",
+			jid)
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.NotFound, str)
+	}
+
+	switch j.Status() {
+	case status.Finished, status.TimedOut:
+		str = fmt.Sprintf("Job %d has finished already", jid)
+		return m.makeError(errcode.InvalidState, str)
+	case status.Cancelled:
+		str = fmt.Sprintf("Job %d has been cancelled already", jid)
+		return m.makeError(errcode.InvalidState, str)
+	}
+
+	// We hold the lock while we update the database, so no worker can
+	// start the Job in the meantime.
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	if ok, err = db.JobCancel(j); err != nil {
+		str = fmt.Sprintf("Failed to cancel Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if !ok {
+		str = fmt.Sprintf("Job %d has finished or been cancelled already", jid)
+		return m.makeError(errcode.InvalidState, str)
+	}
+
+	var (
+		rj    *job.Job
+		found bool
+	)
+
+	if rj, found = m.running[jid]; !found {
+		str = fmt.Sprintf("Job %d has been removed from the queue", jid)
+		m.log.Printf("[INFO] %s\n", str)
+		m.notify(j)
+		m.jobsDone()
+		m.resolveDependencies(db)
+		return m.makeResponse(str)
+	}
+
+	// The worker running the Job reports on it once its process has
+	// exited, see jobEnd. It only looks at the Job while holding the lock.
+	rj.TimeCancelled = j.TimeCancelled
+
+	go func() {
+		m.log.Printf("[INFO] Terminating Job %d (PID %d)\n",
+			rj.ID,
+			rj.PID)
+		if err := rj.Cancel(common.KillGrace); err != nil {
+			m.log.Printf("[ERROR] Failed to terminate Job %d: %s\n",
+				rj.ID,
+				err.Error())
+		}
+	}()
+
+	str = fmt.Sprintf("Job %d is being terminated", jid)
+	return m.makeResponse(str)
+} // func (m *Monitor) jobCancel(db *database.Database, jid int64) Response
+
+// jobSetPriority handles a request to change the priority of a pending Job.
+func (m *Monitor) jobSetPriority(db *database.Database, jid int64, prio int) Response {
+	var (
+		err error
+		ok  bool
+		str string
+		j   *job.Job
+	)
+
+	if j, err = db.JobGetByID(jid); err != nil {
+		str = fmt.Sprintf("Error looking up Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if j == nil {
+		str = fmt.Sprintf("Did not find Job %d in database",
+			jid)
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.NotFound, str)
+	} else if ok, err = db.JobSetPriority(j, prio); err != nil {
+		str = fmt.Sprintf("Failed to set priority of Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if !ok {
+		str = fmt.Sprintf("Job %d is not waiting to be started, its priority cannot be changed",
+			jid)
+		return m.makeError(errcode.InvalidState, str)
+	}
+
+	str = fmt.Sprintf("Priority of Job %d is now %d", jid, prio)
+	m.log.Printf("[INFO] %s\n", str)
+
+	var res = m.makeResponse(str)
+	res.Jobs = []job.Job{*j}
+	return res
+} // func (m *Monitor) jobSetPriority(db *database.Database, jid int64, prio int) Response
+
+// resolveDependencies cancels all Jobs whose dependencies can no longer be
+// satisfied, and wakes up the workers, because other Jobs' dependencies may
+// have become satisfied.
+// It must be called whenever a Job finishes or gets cancelled.
+func (m *Monitor) resolveDependencies(db *database.Database) {
+	for {
+		var (
+			err error
+			ids []int64
+		)
+
+		if ids, err = db.JobCancelUnsatisfiable(); err != nil {
+			m.log.Printf("[ERROR] Cannot cancel Jobs with unsatisfiable dependencies: %s\n",
+				err.Error())
+			return
+		} else if len(ids) == 0 {
+			break
+		}
+
+		m.log.Printf("[INFO] Cancelled Jobs %v, their dependencies cannot be satisfied\n",
+			ids)
+
+		for _, id := range ids {
+			var j *job.Job
+
+			if j, err = db.JobGetByID(id); err != nil {
+				m.log.Printf("[ERROR] Cannot load cancelled Job %d: %s\n",
+					id,
+					err.Error())
+			} else if j != nil {
+				m.notify(j)
+			}
+		}
+
+		m.jobsDone()
+	}
+
+	for i := 0; i < m.slots; i++ {
+		m.jobTick()
+	}
+} // func (m *Monitor) resolveDependencies(db *database.Database)
 
 func (m *Monitor) makeResponse(status string) Response {
 	return Response{
@@ -315,21 +719,62 @@ func (m *Monitor) makeResponse(status string) Response {
 	}
 } // func (m *Monitor) makeResponse(status string) Response
 
-func (m *Monitor) jobLoop() {
+// makeError returns a Response indicating the request could not be handled.
+func (m *Monitor) makeError(code errcode.Code, status string) Response {
+	var res = m.makeResponse(status)
+	res.Error = true
+	res.Code = code
+	return res
+} // func (m *Monitor) makeError(code errcode.Code, status string) Response
+
+// jobLoop is the main loop of a worker. The Monitor runs one worker per slot,
+// each of them runs one Job at a time.
+func (m *Monitor) jobLoop(worker int) {
+	m.log.Printf("[DEBUG] Worker %d starting\n", worker)
+	defer m.log.Printf("[DEBUG] Worker %d quitting\n", worker)
+
 	for m.active.Load() {
-		m.jobStep()
+		m.jobStep(worker)
 	}
-} // func (m *Monitor) jobLoop()
+} // func (m *Monitor) jobLoop(worker int)
+
+// jobClaim fetches the list of pending Jobs and tries to claim one of them.
+// It returns nil if there was no Job to claim.
+func (m *Monitor) jobClaim(db *database.Database) (*job.Job, error) {
+	var (
+		err  error
+		ok   bool
+		jobs []job.Job
+	)
 
-// I really should try to break up this method in two, so I can return the
-// database connection to the pool while the job is running.
+	// Other workers may snatch Jobs away from under our nose, so we
+	// fetch a few more than just the first one.
+	if jobs, err = db.JobGetPending(int64(m.slots), m.aging); err != nil {
+		m.log.Printf("[ERROR] Cannot query pending Jobs: %s\n",
+			err.Error())
+		return nil, err
+	}
 
-func (m *Monitor) jobStep() {
+	for idx := range jobs {
+		var j = &jobs[idx]
+		if ok, err = db.JobClaim(j); err != nil {
+			m.log.Printf("[ERROR] Cannot claim Job %d: %s\n",
+				j.ID,
+				err.Error())
+			return nil, err
+		} else if ok {
+			return j, nil
+		}
+	}
+
+	return nil, nil
+} // func (m *Monitor) jobClaim(db *database.Database) (*job.Job, error)
+
+func (m *Monitor) jobStep(worker int) {
 	var (
 		err              error
 		db               *database.Database
-		jobs             []job.Job
-		j                job.Job
+		j                *job.Job
 		outpath, errpath string
 		outbase, errbase string
 	)
@@ -341,13 +786,43 @@ func (m *Monitor) jobStep() {
 		}
 	}()
 
-	if jobs, err = db.JobGetPending(1); err != nil {
-		m.log.Printf("[ERROR] Cannot query pending Jobs: %s\n",
-			err.Error())
-		return
-	} else if len(jobs) == 0 {
-		m.log.Println("[TRACE] Database returned 0 pending jobs.")
+	// Claiming and starting the Job happens under the lock, so Jobs
+	// cannot get cancelled halfway through.
+	m.runLock.Lock()
+
+	if len(m.running) >= m.slots {
+		// All slots are taken by Jobs we adopted after a restart.
+		j = nil
+	} else if j, err = m.jobClaim(db); err != nil {
+		// jobClaim has logged the error already. We wait as if there
+		// were no pending Jobs, rather than spinning against a
+		// database that is locked or broken.
+		j = nil
+	}
+
+	if j == nil {
+		m.runLock.Unlock()
+		m.log.Printf("[TRACE] Worker %d found no pending jobs.\n",
+			worker)
+
+		// If there are Jobs waiting for their time to come, we wake
+		// up when the first of them becomes eligible.
+		var (
+			next time.Time
+			wake <-chan time.Time
+		)
+
+		if next, err = db.JobGetNextDeferred(); err == nil && !next.IsZero() {
+			var timer = time.NewTimer(time.Until(next))
+			defer timer.Stop()
+			wake = timer.C
+		}
+
+		m.pool.Put(db)
+		db = nil
 		select {
+		case <-wake:
+			return
 		case <-m.jobTicker.C:
 			return
 		case <-m.jobq:
@@ -355,29 +830,78 @@ func (m *Monitor) jobStep() {
 		}
 	}
 
-	j = jobs[0]
-
-	m.log.Printf("[DEBUG] Starting Job %d, submitted %s ago (%q)\n",
+	m.log.Printf("[DEBUG] Worker %d starting Job %d, submitted %s ago (%q)\n",
+		worker,
 		j.ID,
 		time.Since(j.TimeSubmitted),
 		strings.Join(j.Cmd, " "))
 
 	// generate file names for spooling
-	outbase = fmt.Sprintf("jobq.%d.out", j.ID)
-	errbase = fmt.Sprintf("jobq.%d.err", j.ID)
+	if j.Attempts > 1 {
+		// Each attempt gets its own spool files.
+		outbase = fmt.Sprintf("jobq.%d.%d.out", j.ID, j.Attempts)
+		errbase = fmt.Sprintf("jobq.%d.%d.err", j.ID, j.Attempts)
+	} else {
+		outbase = fmt.Sprintf("jobq.%d.out", j.ID)
+		errbase = fmt.Sprintf("jobq.%d.err", j.ID)
+	}
 
-	outpath = filepath.Join(common.SpoolDir, outbase)
-	errpath = filepath.Join(common.SpoolDir, errbase)
+	// If stdout and stderr are merged, there is only one spool file.
+	if j.Output&job.OutputMerge != 0 {
+		outbase = strings.TrimSuffix(outbase, ".out") + ".log"
+	}
+
+	outpath = filepath.Join(common.SpoolDir, outbase+j.SpoolSuffix())
+	errpath = filepath.Join(common.SpoolDir, errbase+j.SpoolSuffix())
 
 	if err = j.Start(outpath, errpath); err != nil {
+		m.runLock.Unlock()
 		m.log.Printf("[ERROR] Failed to start job %d: %s\n",
 			j.ID,
 			err.Error())
-		return // Really? Just bail? No! FIXME
-	} else if err = db.JobStart(&j); err != nil {
+		// We claimed the Job, so we have to mark it as finished,
+		// otherwise it would appear to be running forever.
+		// Retrying is pointless, the next attempt would fail the
+		// same way.
+		// The attempt is recorded nonetheless, so the Job's history
+		// is complete. If that fails, we finish the Job all the same,
+		// see jobEnd.
+		j.ExitCode = -1
+		if err = db.JobStart(j); err != nil {
+			m.log.Printf("[ERROR] Cannot record failed attempt %d of Job %d: %s\n",
+				j.Attempts,
+				j.ID,
+				err.Error())
+		}
+		j.TimeEnded = time.Now()
+		m.jobEnd(db, j, false)
+		return
+	}
+
+	m.running[j.ID] = j
+	m.runLock.Unlock()
+
+	defer func() {
+		m.runLock.Lock()
+		delete(m.running, j.ID)
+		m.runLock.Unlock()
+	}()
+
+	if err = db.JobStart(j); err != nil {
 		m.log.Printf("[ERROR] Cannot mark Job %d as started in database: %s\n",
 			j.ID,
 			err.Error())
+		// Without a record of the attempt, we cannot keep track of
+		// the Job, so we stop it and finish it like a Job that failed
+		// to start. The process still has to be reaped, though, or
+		// it lingers as a zombie with its spool files open.
+		m.pool.Put(db)
+		db = nil
+		go j.Cancel(common.KillGrace) // nolint: errcheck
+		j.Wait()                      // nolint: errcheck
+		j.ExitCode = -1
+		db = m.pool.Get()
+		m.jobEnd(db, j, false)
 		return
 	}
 
@@ -392,53 +916,111 @@ func (m *Monitor) jobStep() {
 	}
 
 	db = m.pool.Get()
+	m.jobEnd(db, j, true)
+} // func (m *Monitor) jobStep(worker int)
 
-	if err = db.JobFinish(&j); err != nil {
-		m.log.Printf("[ERROR] Failed to mark Job %d as finished: %s\n",
+// jobEnd records the outcome of an attempt to run a Job. If the attempt
+// failed, and retry is true, the Job is put back into the queue according to
+// its RetryPolicy, otherwise it is marked as finished.
+func (m *Monitor) jobEnd(db *database.Database, j *job.Job, retry bool) {
+	var (
+		err     error
+		retried bool
+		delay   time.Duration
+	)
+
+	// jobCancel marks running Jobs as cancelled while holding the lock.
+	// Once we hold it, the Job is no longer running, and it cannot be
+	// cancelled behind our back until we have recorded its outcome.
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	delete(m.running, j.ID)
+
+	if retry = retry && j.Retryable(); retry {
+		delay = j.Retry.Delay(j.Attempts)
+	}
+
+	if retried, err = m.jobRecord(db, j, retry, delay); err != nil {
+		// jobRecord has logged the error already. If we left it at
+		// that, the Job would look like it is running forever,
+		// clients waiting for it would hang, and Jobs depending on
+		// it would never be resolved. So we finish it for good, if
+		// the database lets us.
+		retried = false
+		if err = db.JobFinish(j); err != nil {
+			m.log.Printf("[ERROR] Failed to mark Job %d as finished: %s\n",
+				j.ID,
+				err.Error())
+		}
+	}
+
+	if retried {
+		m.log.Printf("[INFO] Attempt %d of %d of Job %d failed, trying again in %s\n",
+			j.Attempts,
+			j.Retry.MaxAttempts,
+			j.ID,
+			delay)
+		return
+	}
+
+	m.notify(j)
+	m.jobsDone()
+	m.resolveDependencies(db)
+} // func (m *Monitor) jobEnd(db *database.Database, j *job.Job, retry bool)
+
+// jobRecord records the outcome of an attempt to run a Job in the database,
+// in a single transaction. If retry is true, the Job is put back into the
+// queue, to be started again after delay, unless it has been cancelled in
+// the meantime, otherwise it is marked as finished.
+// It returns true if the Job is retried.
+func (m *Monitor) jobRecord(db *database.Database, j *job.Job, retry bool, delay time.Duration) (bool, error) {
+	var (
+		err     error
+		retried bool
+	)
+
+	if err = db.Begin(); err != nil {
+		m.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+			err.Error())
+		return false, err
+	} else if err = db.RunFinish(j); err != nil {
+		m.log.Printf("[ERROR] Failed to record the end of attempt %d of Job %d: %s\n",
+			j.Attempts,
 			j.ID,
 			err.Error())
+		db.Rollback() // nolint: errcheck
+		return false, err
+	}
+
+	// If the Job has been cancelled in the meantime, JobRetry returns
+	// false, and we finish it for good.
+	if retry {
+		if retried, err = db.JobRetry(j, time.Now().Add(delay)); err != nil {
+			m.log.Printf("[ERROR] Failed to put Job %d back into the queue: %s\n",
+				j.ID,
+				err.Error())
+			db.Rollback() // nolint: errcheck
+			return false, err
+		}
 	}
-} // func (m *Monitor) jobStep() error
-
-//////////////////////////////////////////////////////////////////
-// HANDLING JOB CANCELLATION /////////////////////////////////////
-//////////////////////////////////////////////////////////////////
-// var (
-// 	j   *job.Job
-// 	jid int64
-// )
-
-// if jid, err = strconv.ParseInt(req[1], 10, 64); err != nil {
-// 	str = fmt.Sprintf("Cannot parse Job ID %q: %s",
-// 		req[1],
-// 		err.Error())
-// 	m.log.Printf("[ERROR] %s\n", str)
-// 	res = m.makeResponse(str)
-// } else if j, err = db.JobGetByID(jid); err != nil {
-// 	str = fmt.Sprintf("Error looking up Job %d: %s",
-// 		jid,
-// 		err.Error())
-// 	m.log.Printf("[ERROR] %s\n", str)
-// 	res = m.makeResponse(str)
-// } else if j == nil {
-// 	str = fmt.Sprintf("Did not find Job %d in database",
-// 		jid)
-// 	m.log.Printf("[ERROR] %s\n", str)
-// 	res = m.makeResponse(str)
-// }
-
-// // We can safely ignore status.Created, because a Job that is not
-// // "in the system" yet can simply be discarded.
-// switch j.Status() {
-// case status.Enqueued:
-// 	if err = db.JobDelete(j); err != nil {
-// 		m.log.Printf("[ERROR] Cannot delete Job %d: %s\n",
-// 			j.ID,
-// 			err.Error())
-// 	}
-// 	// delete
-// case status.Started:
-// 	// kill process, delete spool files, delete job
-// case status.Finished:
-// 	// delete spool files, delete job
-// }
+
+	if !retried {
+		if err = db.JobFinish(j); err != nil {
+			m.log.Printf("[ERROR] Failed to mark Job %d as finished: %s\n",
+				j.ID,
+				err.Error())
+			db.Rollback() // nolint: errcheck
+			return false, err
+		}
+	}
+
+	if err = db.Commit(); err != nil {
+		m.log.Printf("[ERROR] Cannot commit transaction: %s\n",
+			err.Error())
+		db.Rollback() // nolint: errcheck
+		return false, err
+	}
+
+	return retried, nil
+} // func (m *Monitor) jobRecord(db *database.Database, j *job.Job, retry bool, delay time.Duration) (bool, error)
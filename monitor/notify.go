@@ -0,0 +1,194 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/notify.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 17:48:31 krylon>
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// hookTimeout is how long a hook command may run before it is killed.
+const hookTimeout = time.Minute
+
+// Event describes a Job that has finished. The Monitor writes it to its
+// event pipe as a single line of JSON, the same information is passed to
+// hook commands in environment variables, see Env.
+type Event struct {
+	Queue    string
+	ID       int64
+	Status   string
+	ExitCode int
+	TimedOut bool
+	Attempts int
+	Cmd      []string
+	SpoolOut string
+	SpoolErr string
+	Started  time.Time
+	Ended    time.Time
+}
+
+func makeEvent(queue string, j *job.Job) Event {
+	return Event{
+		Queue:    queue,
+		ID:       j.ID,
+		Status:   j.Status().String(),
+		ExitCode: j.ExitCode,
+		TimedOut: j.TimedOut,
+		Attempts: j.Attempts,
+		Cmd:      j.Cmd,
+		SpoolOut: j.SpoolOut,
+		SpoolErr: j.SpoolErr,
+		Started:  j.TimeStarted,
+		Ended:    j.TimeEnded,
+	}
+} // func makeEvent(queue string, j *job.Job) Event
+
+// Env returns the environment variables that describe the Event to a hook
+// command.
+func (ev *Event) Env() []string {
+	return []string{
+		"JOBQ_QUEUE=" + ev.Queue,
+		"JOBQ_ID=" + strconv.FormatInt(ev.ID, 10),
+		"JOBQ_STATUS=" + ev.Status,
+		"JOBQ_EXIT=" + strconv.Itoa(ev.ExitCode),
+		"JOBQ_TIMEDOUT=" + strconv.FormatBool(ev.TimedOut),
+		"JOBQ_ATTEMPTS=" + strconv.Itoa(ev.Attempts),
+		"JOBQ_CMD=" + strings.Join(ev.Cmd, " "),
+		"JOBQ_STDOUT=" + ev.SpoolOut,
+		"JOBQ_STDERR=" + ev.SpoolErr,
+	}
+} // func (ev *Event) Env() []string
+
+// SetHook sets a shell command the Monitor runs whenever a Job has finished,
+// in addition to the Job's own Hook, if it has one.
+// It must be called before the Monitor is started.
+func (m *Monitor) SetHook(cmd string) {
+	m.hook = cmd
+} // func (m *Monitor) SetHook(cmd string)
+
+// SetEventPipe sets the path of a named pipe the Monitor writes an Event to
+// whenever a Job has finished. If the pipe does not exist, it is created.
+// If nobody is reading from the pipe, Events are dropped.
+// It must be called before the Monitor is started.
+func (m *Monitor) SetEventPipe(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err = syscall.Mkfifo(path, 0600); err != nil {
+			m.log.Printf("[ERROR] Cannot create named pipe %s: %s\n",
+				path,
+				err.Error())
+			return err
+		}
+	} else if err != nil {
+		m.log.Printf("[ERROR] Cannot access event pipe %s: %s\n",
+			path,
+			err.Error())
+		return err
+	}
+
+	m.fifo = path
+	return nil
+} // func (m *Monitor) SetEventPipe(path string) error
+
+// notify tells the world that a Job has finished: It runs the Job's hook and
+// the Monitor's hook, if there are any, and writes an Event to the event
+// pipe. Hooks run in the background, so notify does not block.
+func (m *Monitor) notify(j *job.Job) {
+	var ev = makeEvent(m.name, j)
+
+	if j.Hook != "" {
+		go m.runHook(&ev, j.Hook, j.Directory, j.Environ())
+	}
+
+	if m.hook != "" {
+		go m.runHook(&ev, m.hook, "", os.Environ())
+	}
+
+	if m.fifo != "" {
+		m.writeEvent(&ev)
+	}
+} // func (m *Monitor) notify(j *job.Job)
+
+// runHook runs a hook command for an Event.
+func (m *Monitor) runHook(ev *Event, hook, dir string, env []string) {
+	var (
+		err         error
+		output      []byte
+		ctx, cancel = context.WithTimeout(context.Background(), hookTimeout)
+		cmd         = exec.CommandContext(ctx, "/bin/sh", "-c", hook)
+	)
+
+	defer cancel()
+
+	cmd.Dir = dir
+	cmd.Env = append(env[:len(env):len(env)], ev.Env()...)
+
+	if output, err = cmd.CombinedOutput(); err != nil {
+		m.log.Printf("[ERROR] Hook %q for Job %d failed: %s\n%s\n",
+			hook,
+			ev.ID,
+			err.Error(),
+			output)
+		return
+	}
+
+	m.log.Printf("[DEBUG] Ran hook %q for Job %d\n",
+		hook,
+		ev.ID)
+} // func (m *Monitor) runHook(ev *Event, hook, dir string, env []string)
+
+// writeEvent writes an Event to the event pipe. If nobody is reading from the
+// pipe, or the reader cannot keep up, the Event is dropped rather than
+// blocking the Monitor.
+func (m *Monitor) writeEvent(ev *Event) {
+	var (
+		err error
+		buf []byte
+		fh  *os.File
+	)
+
+	if buf, err = json.Marshal(ev); err != nil {
+		m.log.Printf("[ERROR] Cannot serialize Event for Job %d: %s\n",
+			ev.ID,
+			err.Error())
+		return
+	}
+
+	buf = append(buf, '\n')
+
+	if fh, err = os.OpenFile(m.fifo, os.O_WRONLY|os.O_APPEND|syscall.O_NONBLOCK, 0600); err != nil {
+		if errors.Is(err, syscall.ENXIO) {
+			m.log.Printf("[DEBUG] Nobody is listening on %s, dropping Event for Job %d\n",
+				m.fifo,
+				ev.ID)
+		} else {
+			m.log.Printf("[ERROR] Cannot open event pipe %s: %s\n",
+				m.fifo,
+				err.Error())
+		}
+		return
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	fh.SetWriteDeadline(time.Now().Add(time.Second)) // nolint: errcheck
+
+	if _, err = fh.Write(buf); err != nil {
+		m.log.Printf("[ERROR] Cannot write Event for Job %d to %s: %s\n",
+			ev.ID,
+			m.fifo,
+			err.Error())
+	}
+} // func (m *Monitor) writeEvent(ev *Event)
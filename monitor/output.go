@@ -0,0 +1,227 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/output.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 20. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-20 14:18:52 krylon>
+
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+)
+
+// outputChunk is the maximum amount of output sent in a single Response.
+const outputChunk = 32 * 1024 // 32 KiB
+
+// outputPoll is the interval at which we check for new output of a running
+// Job, or for a pending Job to start.
+const outputPoll = time.Millisecond * 250
+
+// followReader reads a spool file. When it reaches the end of the file, it
+// waits for more output to arrive until done returns true.
+type followReader struct {
+	f    *os.File
+	done func() bool
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		var n, err = r.f.Read(p)
+
+		if n > 0 || err != io.EOF {
+			return n, err
+		} else if r.done() {
+			// The Job may have written more output after our last
+			// attempt, so we take one more look.
+			return r.f.Read(p)
+		}
+
+		time.Sleep(outputPoll)
+	}
+} // func (r *followReader) Read(p []byte) (int, error)
+
+// isRunning returns true if the given attempt of the Job with the given ID is
+// currently running.
+func (m *Monitor) isRunning(id int64, seq int) bool {
+	m.runLock.Lock()
+	defer m.runLock.Unlock()
+
+	var j, ok = m.running[id]
+	return ok && j.Attempts == seq
+} // func (m *Monitor) isRunning(id int64, seq int) bool
+
+// jobOutput handles a request to stream the output of a Job, see
+// JobOutputArgs. The output is sent in a series of Responses, see Response.
+// Without follow, we send the output the Job has produced so far. With
+// follow, we wait for a pending Job to start and keep sending its output
+// until the Job has finished. If the Job is retried, only one attempt is
+// sent, the current one, unless the client asks for a specific one.
+func (m *Monitor) jobOutput(id uint64, args *JobOutputArgs, conn *Conn) error {
+	var (
+		err, rerr error
+		str       string
+		j         *job.Job
+		path      string
+		fh        *os.File
+		run       job.Run
+		rd        io.ReadCloser
+		res       Response
+		buf       []byte
+		cnt       int
+		sent      bool
+		code      errcode.Code
+		jid       = args.JobID
+		stderr    = args.Stderr
+		follow    = args.Follow
+	)
+
+	// Wait for the Job to start, if it has not done so, yet.
+	for {
+		var (
+			runs []job.Run
+			db   = m.pool.Get()
+		)
+
+		if j, err = db.JobGetByID(jid); err == nil && j != nil && j.SpoolOut != "" {
+			runs, err = db.RunGetByJob(jid)
+		}
+		m.pool.Put(db)
+
+		if err != nil {
+			code = errcode.Internal
+			str = fmt.Sprintf("Error looking up Job %d: %s",
+				jid,
+				err.Error())
+		} else if j == nil {
+			code = errcode.NotFound
+			str = fmt.Sprintf("Did not find Job %d in database",
+				jid)
+		} else if args.Attempt == 0 && len(runs) > 0 {
+			// The current run is the last one.
+			run = runs[len(runs)-1]
+			break
+		} else if r := findRun(runs, args.Attempt); r != nil {
+			run = *r
+			break
+		} else if !j.TimeEnded.IsZero() || !j.TimeCancelled.IsZero() {
+			// There will be no more attempts.
+			code = errcode.InvalidState
+			if args.Attempt > 0 {
+				str = fmt.Sprintf("Job %d has no attempt %d",
+					jid,
+					args.Attempt)
+			} else {
+				str = fmt.Sprintf("Job %d has no output",
+					jid)
+			}
+		} else if !follow {
+			code = errcode.InvalidState
+			str = fmt.Sprintf("Job %d has not been started, yet",
+				jid)
+		} else if !m.active.Load() {
+			code = errcode.Internal
+			str = "Monitor is shutting down"
+		} else {
+			time.Sleep(outputPoll)
+			continue
+		}
+
+		m.log.Printf("[ERROR] %s\n", str)
+		res = m.makeError(code, str)
+		return m.sendResponse(conn, id, &res)
+	}
+
+	// If stdout and stderr are merged, there is only one spool file, and
+	// that is what we send, whichever stream was asked for.
+	if path = run.SpoolOut; stderr && run.SpoolErr != "" {
+		path = run.SpoolErr
+	}
+
+	if fh, err = os.Open(path); err != nil {
+		str = fmt.Sprintf("Cannot open spool file of Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		res = m.makeError(errcode.Internal, str)
+		return m.sendResponse(conn, id, &res)
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	var fr = &followReader{
+		f: fh,
+		done: func() bool {
+			return !follow || !m.active.Load() || !m.isRunning(jid, run.Seq)
+		},
+	}
+
+	if rd, err = job.NewSpoolReader(fr, run.Compress); err != nil {
+		str = fmt.Sprintf("Cannot decompress output of Job %d: %s",
+			jid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		res = m.makeError(errcode.Internal, str)
+		return m.sendResponse(conn, id, &res)
+	}
+
+	defer rd.Close() // nolint: errcheck
+
+	buf = make([]byte, outputChunk)
+
+	for {
+		if cnt, rerr = rd.Read(buf); cnt > 0 {
+			res = m.makeResponse("OK")
+			res.Output = buf[:cnt]
+			res.More = true
+
+			if err = m.sendResponse(conn, id, &res); err != nil {
+				return err
+			}
+
+			sent = true
+		}
+
+		if rerr == nil {
+			continue
+		} else if rerr == io.EOF || (errors.Is(rerr, io.ErrUnexpectedEOF) && m.isRunning(jid, run.Seq)) {
+			// The compressed output of a running Job is not
+			// complete, yet, so we cannot tell where it ends.
+			break
+		}
+
+		str = fmt.Sprintf("Error reading output of Job %d: %s",
+			jid,
+			rerr.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		res = m.makeError(errcode.Internal, str)
+		return m.sendResponse(conn, id, &res)
+	}
+
+	if sent {
+		str = fmt.Sprintf("End of output of Job %d", jid)
+	} else {
+		str = fmt.Sprintf("Job %d has not produced any output", jid)
+	}
+
+	res = m.makeResponse(str)
+	return m.sendResponse(conn, id, &res)
+} // func (m *Monitor) jobOutput(id uint64, args *JobOutputArgs, conn *Conn) error
+
+// findRun returns the attempt with the given sequence number, or nil if
+// there is no such attempt.
+func findRun(runs []job.Run, seq int) *job.Run {
+	for idx := range runs {
+		if runs[idx].Seq == seq {
+			return &runs[idx]
+		}
+	}
+
+	return nil
+} // func findRun(runs []job.Run, seq int) *job.Run
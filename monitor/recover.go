@@ -0,0 +1,170 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/recover.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 19. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-19 11:02:36 krylon>
+
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/database"
+	"github.com/blicero/jobq/job"
+)
+
+// recoverJobs deals with the Jobs the database lists as running when the
+// Monitor starts. Since no worker is running yet, these were left behind by a
+// previous instance of the Monitor that died without cleaning up after
+// itself.
+// Jobs whose process is still running are adopted, the others are requeued
+// or marked as lost, according to their RestartPolicy.
+func (m *Monitor) recoverJobs() {
+	var (
+		err     error
+		running []job.Job
+		changed bool
+		db      = m.pool.Get()
+	)
+
+	defer m.pool.Put(db)
+
+	if running, err = db.JobGetRunning(); err != nil {
+		m.log.Printf("[ERROR] Cannot query running Jobs: %s\n",
+			err.Error())
+		return
+	}
+
+	for _, r := range running {
+		var (
+			j     *job.Job
+			known bool
+		)
+
+		m.runLock.Lock()
+		_, known = m.running[r.ID]
+		m.runLock.Unlock()
+
+		// JobGetRunning does not give us the Job's Options.
+		if known {
+			continue
+		} else if j, err = db.JobGetByID(r.ID); err != nil {
+			m.log.Printf("[ERROR] Cannot load Job %d: %s\n",
+				r.ID,
+				err.Error())
+			continue
+		} else if j == nil {
+			continue
+		} else if m.recoverJob(db, j) {
+			changed = true
+		}
+	}
+
+	if changed {
+		m.resolveDependencies(db)
+	}
+} // func (m *Monitor) recoverJobs()
+
+// recoverJob adopts, requeues or marks as lost a single Job that was left
+// running. It returns true if the Job is no longer running.
+func (m *Monitor) recoverJob(db *database.Database, j *job.Job) bool {
+	var err error
+
+	if err = j.Adopt(); err == nil {
+		m.log.Printf("[INFO] Adopting Job %d (PID %d)\n",
+			j.ID,
+			j.PID)
+
+		m.runLock.Lock()
+		m.running[j.ID] = j
+		m.runLock.Unlock()
+
+		go m.jobWatch(j)
+
+		// The previous Monitor might have died while it was
+		// terminating the Job, so we pick up where it left off.
+		if !j.TimeCancelled.IsZero() {
+			go func() {
+				if err := j.Cancel(common.KillGrace); err != nil {
+					m.log.Printf("[ERROR] Failed to terminate Job %d: %s\n",
+						j.ID,
+						err.Error())
+				}
+			}()
+		}
+
+		return false
+	} else if !errors.Is(err, job.ErrProcessGone) && !errors.Is(err, job.ErrJobNotStarted) {
+		m.log.Printf("[ERROR] Cannot adopt Job %d: %s\n",
+			j.ID,
+			err.Error())
+		return false
+	}
+
+	// We close the Job's current run first, whatever happens to the Job
+	// itself.
+	j.TimeEnded = time.Now()
+	j.ExitCode = -1
+
+	if err = db.RunFinish(j); err != nil {
+		return false
+	}
+
+	// A Job that was cancelled is not restarted, of course.
+	if j.Restart == job.RestartRequeue && j.TimeCancelled.IsZero() {
+		var ok bool
+
+		if ok, err = db.JobRequeue(j); err != nil {
+			m.log.Printf("[ERROR] Cannot requeue Job %d: %s\n",
+				j.ID,
+				err.Error())
+			return false
+		} else if ok {
+			m.log.Printf("[INFO] Process of Job %d (PID %d) is gone, Job has been requeued\n",
+				j.ID,
+				j.PID)
+			return true
+		}
+	}
+
+	if err = db.JobLost(j); err != nil {
+		m.log.Printf("[ERROR] Cannot mark Job %d as lost: %s\n",
+			j.ID,
+			err.Error())
+		return false
+	}
+
+	m.log.Printf("[INFO] Process of Job %d (PID %d) is gone, Job has been marked as lost\n",
+		j.ID,
+		j.PID)
+	return true
+} // func (m *Monitor) recoverJob(db *database.Database, j *job.Job) bool
+
+// jobWatch waits for an adopted Job to finish. Adopted Jobs count against the
+// Monitor's slots like any other running Job.
+func (m *Monitor) jobWatch(j *job.Job) {
+	var err error
+
+	defer func() {
+		m.runLock.Lock()
+		delete(m.running, j.ID)
+		m.runLock.Unlock()
+	}()
+
+	if err = j.Wait(); err != nil {
+		m.log.Printf("[ERROR] Error waiting for adopted Job %d: %s\n",
+			j.ID,
+			err.Error())
+	}
+
+	var db = m.pool.Get()
+	defer m.pool.Put(db)
+
+	m.log.Printf("[INFO] Adopted Job %d has finished\n", j.ID)
+
+	// We do not know the exit code of adopted Jobs, so we cannot tell
+	// if the attempt failed.
+	m.jobEnd(db, j, false)
+} // func (m *Monitor) jobWatch(j *job.Job)
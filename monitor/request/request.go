@@ -18,8 +18,17 @@ const (
 	Invalid ID = iota
 	JobSubmit
 	JobCancel
+	JobSetPriority
+	JobRerun
+	JobOutput
 	JobClear
+	JobWait
 	QueueQueryStatus
+	ScheduleCreate
+	ScheduleList
+	SchedulePause
+	ScheduleResume
+	ScheduleDelete
 	MonitorStop
 	MonitorRestart // ???
 )
@@ -32,10 +41,28 @@ func Parse(s string) (ID, error) {
 		id = JobSubmit
 	case "JobCancel":
 		id = JobCancel
+	case "JobSetPriority":
+		id = JobSetPriority
+	case "JobRerun":
+		id = JobRerun
+	case "JobOutput":
+		id = JobOutput
 	case "JobClear":
 		id = JobClear
+	case "JobWait":
+		id = JobWait
 	case "QueueQueryStatus":
 		id = QueueQueryStatus
+	case "ScheduleCreate":
+		id = ScheduleCreate
+	case "ScheduleList":
+		id = ScheduleList
+	case "SchedulePause":
+		id = SchedulePause
+	case "ScheduleResume":
+		id = ScheduleResume
+	case "ScheduleDelete":
+		id = ScheduleDelete
 	case "MonitorStop":
 		id = MonitorStop
 	case "MonitorRestart":
@@ -0,0 +1,226 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/retention.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 21. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-21 14:37:50 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/jobq/database"
+	"github.com/blicero/jobq/job"
+)
+
+// sweepInterval is how often the Monitor removes the finished Jobs its
+// Retention policy does not keep.
+const sweepInterval = time.Minute * 15
+
+// Retention determines which finished Jobs - and their spool files - are
+// kept. A finished Job is kept if it is one of the Keep most recently
+// finished Jobs, or if it finished less than MaxAge ago. If MaxSize is
+// positive, the spool files of the Jobs we keep may not take up more than
+// MaxSize bytes in total, counting from the most recent Job; once that
+// limit is passed, all older Jobs are removed as well.
+// Zero values mean the respective limit does not apply. A zero Retention
+// keeps nothing, i.e. all finished Jobs are removed.
+type Retention struct {
+	Keep    int           `json:",omitempty"`
+	MaxAge  time.Duration `json:",omitempty"`
+	MaxSize int64         `json:",omitempty"`
+}
+
+// IsZero returns true if none of the limits of the Retention policy are set.
+func (r *Retention) IsZero() bool {
+	return r.Keep <= 0 && r.MaxAge <= 0 && r.MaxSize <= 0
+} // func (r *Retention) IsZero() bool
+
+func (r *Retention) String() string {
+	var parts = make([]string, 0, 3)
+
+	if r.Keep > 0 {
+		parts = append(parts, fmt.Sprintf("keep %d", r.Keep))
+	}
+
+	if r.MaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("older than %s", r.MaxAge))
+	}
+
+	if r.MaxSize > 0 {
+		parts = append(parts, fmt.Sprintf("spool size %d", r.MaxSize))
+	}
+
+	if len(parts) == 0 {
+		return "remove all"
+	}
+
+	return strings.Join(parts, ", ")
+} // func (r *Retention) String() string
+
+// finishedAt returns the time a finished Job ended, or was cancelled, if it
+// never ran.
+func finishedAt(j *job.Job) time.Time {
+	if !j.TimeEnded.IsZero() {
+		return j.TimeEnded
+	}
+
+	return j.TimeCancelled
+} // func finishedAt(j *job.Job) time.Time
+
+// expired returns the indices of the Jobs the Retention policy does not keep.
+// jobs must be sorted by the time they finished, most recent first, sizes
+// contains the total size of each Job's spool files.
+func (r *Retention) expired(jobs []job.Job, sizes []int64, now time.Time) []int {
+	var (
+		total int64
+		full  bool
+		idx   []int
+	)
+
+	for i := range jobs {
+		var keep bool
+
+		switch {
+		case r.IsZero():
+		case r.Keep <= 0 && r.MaxAge <= 0:
+			// Only the size of the spool files matters.
+			keep = true
+		default:
+			keep = (r.Keep > 0 && i < r.Keep) ||
+				(r.MaxAge > 0 && now.Sub(finishedAt(&jobs[i])) < r.MaxAge)
+		}
+
+		if keep && r.MaxSize > 0 {
+			total += sizes[i]
+			full = full || total > r.MaxSize
+			keep = !full
+		}
+
+		if !keep {
+			idx = append(idx, i)
+		}
+	}
+
+	return idx
+} // func (r *Retention) expired(jobs []job.Job, sizes []int64, now time.Time) []int
+
+// ParseAge parses the maximum age of finished Jobs for a Retention policy.
+// In addition to the format understood by time.ParseDuration, it accepts a
+// number of days, e.g. "7d".
+func ParseAge(s string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(s, "d"); found {
+		var n, err = strconv.Atoi(days)
+
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("Invalid number of days: %q", s)
+		}
+
+		return time.Duration(n) * time.Hour * 24, nil
+	}
+
+	return time.ParseDuration(s)
+} // func ParseAge(s string) (time.Duration, error)
+
+// SetRetention sets the Retention policy for finished Jobs. If it is not
+// zero, the Monitor periodically removes the finished Jobs the policy does
+// not keep. It must be called before the Monitor is started.
+func (m *Monitor) SetRetention(r Retention) {
+	m.retention = r
+} // func (m *Monitor) SetRetention(r Retention)
+
+// sweepLoop periodically applies the Monitor's Retention policy.
+func (m *Monitor) sweepLoop() {
+	m.log.Printf("[DEBUG] Sweeper starting, policy is %s\n", &m.retention)
+	defer m.log.Printf("[DEBUG] Sweeper quitting\n")
+
+	var ticker = time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for m.active.Load() {
+		var db = m.pool.Get()
+		m.sweep(db, &m.retention) // nolint: errcheck
+		m.pool.Put(db)
+
+		<-ticker.C
+	}
+} // func (m *Monitor) sweepLoop()
+
+// sweep removes the finished Jobs the Retention policy does not keep from
+// the database, along with their spool files. Spool files that have gone
+// missing already are fine. If removing a Job fails, sweep carries on with
+// the others and returns the first error it encountered.
+// It returns the Jobs it removed and the number of bytes that were freed.
+func (m *Monitor) sweep(db *database.Database, r *Retention) ([]job.Job, int64, error) {
+	var (
+		err, ferr error
+		jobs      []job.Job
+		removed   []job.Job
+		runs      map[int64][]job.Run
+		freed     int64
+		files     [][]string
+		sizes     []int64
+	)
+
+	if jobs, err = db.JobGetFinished(-1); err != nil {
+		m.log.Printf("[ERROR] Error loading finished Jobs from database: %s\n",
+			err.Error())
+		return nil, 0, err
+	} else if runs, err = db.RunGetAll(); err != nil {
+		m.log.Printf("[ERROR] Error loading the history of Jobs from database: %s\n",
+			err.Error())
+		return nil, 0, err
+	}
+
+	files = make([][]string, len(jobs))
+	sizes = make([]int64, len(jobs))
+
+	for idx := range jobs {
+		files[idx] = spoolFiles(&jobs[idx], runs[jobs[idx].ID])
+
+		for _, path := range files[idx] {
+			if info, err := os.Stat(path); err == nil {
+				sizes[idx] += info.Size()
+			}
+		}
+	}
+
+	for _, idx := range r.expired(jobs, sizes, time.Now()) {
+		var j = &jobs[idx]
+
+		if err = removeSpool(files[idx]...); err != nil {
+			m.log.Printf("[ERROR] Cannot delete spool files of Job %d: %s\n",
+				j.ID,
+				err.Error())
+		} else if err = db.JobDelete(j); err != nil {
+			m.log.Printf("[ERROR] Failed to remove Job %d from database: %s\n",
+				j.ID,
+				err.Error())
+		} else {
+			m.log.Printf("[INFO] Removed Job %d (%s), freed %d bytes\n",
+				j.ID,
+				strings.Join(j.Cmd, " "),
+				sizes[idx])
+			removed = append(removed, *j)
+			freed += sizes[idx]
+			continue
+		}
+
+		if ferr == nil {
+			ferr = fmt.Errorf("Cannot remove Job %d: %w", j.ID, err)
+		}
+	}
+
+	if len(removed) > 0 {
+		m.log.Printf("[INFO] Removed %d finished Jobs, freed %d bytes (%s)\n",
+			len(removed),
+			freed,
+			r)
+	}
+
+	return removed, freed, ferr
+} // func (m *Monitor) sweep(db *database.Database, r *Retention) ([]job.Job, int64, error)
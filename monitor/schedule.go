@@ -0,0 +1,298 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/schedule.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 23:24:51 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blicero/jobq/common"
+	"github.com/blicero/jobq/database"
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+	"github.com/blicero/jobq/monitor/request"
+	"github.com/blicero/jobq/schedule"
+)
+
+// schedTick wakes up the scheduler, so it picks up changes to the Schedules.
+func (m *Monitor) schedTick() {
+	select {
+	case m.schedq <- 1:
+	default:
+	}
+} // func (m *Monitor) schedTick()
+
+// schedLoop submits Jobs whenever a Schedule fires.
+func (m *Monitor) schedLoop() {
+	m.log.Printf("[DEBUG] Scheduler starting\n")
+	defer m.log.Printf("[DEBUG] Scheduler quitting\n")
+
+	var ticker = time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for m.active.Load() {
+		var (
+			timer *time.Timer
+			wake  <-chan time.Time
+			next  = m.schedStep()
+		)
+
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			wake = timer.C
+		}
+
+		select {
+		case <-wake:
+		case <-m.schedq:
+		case <-ticker.C:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+} // func (m *Monitor) schedLoop()
+
+// schedStep fires all Schedules that are due and returns the time the next
+// Schedule fires, or the zero time if there are no active Schedules.
+func (m *Monitor) schedStep() time.Time {
+	var (
+		err  error
+		list []schedule.Schedule
+		next time.Time
+		now  = time.Now()
+		db   = m.pool.Get()
+	)
+
+	defer m.pool.Put(db)
+
+	if list, err = db.ScheduleGetAll(); err != nil {
+		m.log.Printf("[ERROR] Cannot load Schedules: %s\n",
+			err.Error())
+		return next
+	}
+
+	for idx := range list {
+		var s = &list[idx]
+
+		if s.Paused {
+			continue
+		} else if err = m.schedFire(db, s, now); err != nil {
+			continue
+		} else if next.IsZero() || s.NextRun.Before(next) {
+			next = s.NextRun
+		}
+	}
+
+	return next
+} // func (m *Monitor) schedStep() time.Time
+
+// schedFire submits the Jobs for a Schedule that is due and updates the time
+// it fires next.
+func (m *Monitor) schedFire(db *database.Database, s *schedule.Schedule, now time.Time) error {
+	var (
+		err  error
+		cnt  int
+		next time.Time
+		last = s.LastRun
+	)
+
+	if cnt, next, err = s.Due(now); err != nil {
+		m.log.Printf("[ERROR] Cannot determine if Schedule %d is due: %s\n",
+			s.ID,
+			err.Error())
+		return err
+	} else if next.Equal(s.NextRun) {
+		return nil
+	} else if next.IsZero() {
+		// This should not happen, we checked when the Schedule was
+		// created.
+		m.log.Printf("[ERROR] Schedule %d (%q) will never fire again, pausing it\n",
+			s.ID,
+			s.Spec)
+		return db.ScheduleSetPaused(s, true, s.NextRun)
+	} else if cnt > 0 {
+		last = now
+	}
+
+	if cnt > 1 || cnt == 0 && s.NextRun.Before(now.Add(-schedule.Grace)) {
+		m.log.Printf("[INFO] Schedule %d missed firings since %s, policy is %s, submitting %d Jobs\n",
+			s.ID,
+			s.NextRun.Format(common.TimestampFormat),
+			s.Missed,
+			cnt)
+	}
+
+	if err = db.Begin(); err != nil {
+		m.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	for i := 0; i < cnt; i++ {
+		var j *job.Job
+
+		if j, err = s.MakeJob(); err != nil {
+			m.log.Printf("[ERROR] Cannot create Job for Schedule %d: %s\n",
+				s.ID,
+				err.Error())
+			db.Rollback() // nolint: errcheck
+			return err
+		}
+
+		j.TimeSubmitted = now
+
+		if err = db.JobSubmit(j); err != nil {
+			m.log.Printf("[ERROR] Cannot submit Job for Schedule %d: %s\n",
+				s.ID,
+				err.Error())
+			db.Rollback() // nolint: errcheck
+			return err
+		}
+
+		m.log.Printf("[INFO] Schedule %d submitted Job %d\n",
+			s.ID,
+			j.ID)
+	}
+
+	if err = db.ScheduleFired(s, last, next); err != nil {
+		db.Rollback() // nolint: errcheck
+		return err
+	} else if err = db.Commit(); err != nil {
+		m.log.Printf("[ERROR] Cannot commit transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	for i := 0; i < cnt && i < m.slots; i++ {
+		m.jobTick()
+	}
+
+	return nil
+} // func (m *Monitor) schedFire(db *database.Database, s *schedule.Schedule, now time.Time) error
+
+// scheduleCreate handles a request to create a new Schedule.
+func (m *Monitor) scheduleCreate(db *database.Database, s *schedule.Schedule) Response {
+	var (
+		err error
+		str string
+		now = time.Now()
+	)
+
+	s.Created = now
+	s.LastRun = time.Time{}
+
+	if s.NextRun, err = s.Next(now); err != nil {
+		str = fmt.Sprintf("Invalid Schedule: %s", err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.InvalidArgument, str)
+	} else if err = db.ScheduleAdd(s); err != nil {
+		str = fmt.Sprintf("Failed to create Schedule: %s", err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	}
+
+	m.schedTick()
+
+	str = fmt.Sprintf("Schedule created, Schedule ID is %d, next run at %s",
+		s.ID,
+		s.NextRun.Format(common.TimestampFormat))
+	m.log.Printf("[INFO] %s\n", str)
+
+	var res = m.makeResponse(str)
+	res.Schedules = []schedule.Schedule{*s}
+	return res
+} // func (m *Monitor) scheduleCreate(db *database.Database, s *schedule.Schedule) Response
+
+// scheduleList handles a request to list all Schedules.
+func (m *Monitor) scheduleList(db *database.Database) Response {
+	var (
+		err  error
+		list []schedule.Schedule
+	)
+
+	if list, err = db.ScheduleGetAll(); err != nil {
+		var str = fmt.Sprintf("Failed to query Schedules: %s",
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	}
+
+	var res = m.makeResponse("OK")
+	res.Schedules = list
+	return res
+} // func (m *Monitor) scheduleList(db *database.Database) Response
+
+// scheduleUpdate handles requests to pause, resume or delete a Schedule.
+func (m *Monitor) scheduleUpdate(db *database.Database, cmd request.ID, sid int64) Response {
+	var (
+		err error
+		str string
+		s   *schedule.Schedule
+	)
+
+	if s, err = db.ScheduleGetByID(sid); err != nil {
+		str = fmt.Sprintf("Error looking up Schedule %d: %s",
+			sid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	} else if s == nil {
+		str = fmt.Sprintf("Did not find Schedule %d in database",
+			sid)
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.NotFound, str)
+	}
+
+	switch cmd {
+	case request.SchedulePause:
+		if s.Paused {
+			return m.makeError(errcode.InvalidState, fmt.Sprintf("Schedule %d is paused already", sid))
+		} else if err = db.ScheduleSetPaused(s, true, s.NextRun); err == nil {
+			str = fmt.Sprintf("Schedule %d has been paused", sid)
+		}
+	case request.ScheduleResume:
+		// The time the Schedule was paused does not count as missed,
+		// so we start over from now.
+		var next time.Time
+
+		if !s.Paused {
+			return m.makeError(errcode.InvalidState, fmt.Sprintf("Schedule %d is not paused", sid))
+		} else if next, err = s.Next(time.Now()); err != nil {
+			break
+		} else if err = db.ScheduleSetPaused(s, false, next); err == nil {
+			str = fmt.Sprintf("Schedule %d has been resumed, next run at %s",
+				sid,
+				next.Format(common.TimestampFormat))
+		}
+	case request.ScheduleDelete:
+		if err = db.ScheduleDelete(s); err == nil {
+			str = fmt.Sprintf("Schedule %d has been deleted", sid)
+		}
+	default:
+		str = fmt.Sprintf("I don't know how to handle %s", cmd)
+		m.log.Printf("[CANTHAPPEN] %s\n", str)
+		return m.makeError(errcode.UnknownRequest, str)
+	}
+
+	if err != nil {
+		str = fmt.Sprintf("%s %d failed: %s",
+			cmd,
+			sid,
+			err.Error())
+		m.log.Printf("[ERROR] %s\n", str)
+		return m.makeError(errcode.Internal, str)
+	}
+
+	m.schedTick()
+	m.log.Printf("[INFO] %s\n", str)
+
+	var res = m.makeResponse(str)
+	res.Schedules = []schedule.Schedule{*s}
+	return res
+} // func (m *Monitor) scheduleUpdate(db *database.Database, cmd request.ID, sid int64) Response
@@ -0,0 +1,123 @@
+// /home/krylon/go/src/github.com/blicero/jobq/monitor/wait.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 23. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-23 16:02:11 krylon>
+
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blicero/jobq/job"
+	"github.com/blicero/jobq/monitor/errcode"
+)
+
+// jobsDone wakes up all clients waiting for Jobs to finish. It is called
+// whenever a Job has reached its final state, so the waiters can check if
+// the Jobs they are waiting for are done.
+func (m *Monitor) jobsDone() {
+	m.doneLock.Lock()
+	defer m.doneLock.Unlock()
+
+	close(m.done)
+	m.done = make(chan struct{})
+} // func (m *Monitor) jobsDone()
+
+// doneSignal returns a channel that is closed the next time a Job is done.
+func (m *Monitor) doneSignal() <-chan struct{} {
+	m.doneLock.Lock()
+	defer m.doneLock.Unlock()
+
+	return m.done
+} // func (m *Monitor) doneSignal() <-chan struct{}
+
+// jobWait handles a request to wait for Jobs to finish, see JobWaitArgs.
+// Instead of polling the database, we only look at the Jobs again when
+// jobsDone tells us some Job has finished. If the client hangs up in the
+// meantime, we stop waiting.
+func (m *Monitor) jobWait(id uint64, args *JobWaitArgs, conn *Conn) error {
+	var (
+		err     error
+		str     string
+		res     Response
+		timeout <-chan time.Time
+	)
+
+	var hangup, stop = conn.watchHangup()
+	defer stop()
+
+	if args.Timeout > 0 {
+		var timer = time.NewTimer(args.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// We have to get the signal before we look at the Jobs, or
+		// we might miss a Job that finishes in between.
+		var (
+			j    *job.Job
+			cnt  int
+			jobs = make([]job.Job, 0, len(args.IDs))
+			done = m.doneSignal()
+			db   = m.pool.Get()
+		)
+
+		for _, jid := range args.IDs {
+			if j, err = db.JobGetByID(jid); err != nil || j == nil {
+				break
+			} else if j.Status().Final() {
+				cnt++
+			}
+
+			jobs = append(jobs, *j)
+		}
+
+		m.pool.Put(db)
+
+		if err != nil {
+			str = fmt.Sprintf("Error looking up Jobs %v: %s",
+				args.IDs,
+				err.Error())
+			m.log.Printf("[ERROR] %s\n", str)
+			res = m.makeError(errcode.Internal, str)
+			return m.sendResponse(conn, id, &res)
+		} else if j == nil {
+			str = fmt.Sprintf("Did not find Job %d in database",
+				args.IDs[len(jobs)])
+			m.log.Printf("[ERROR] %s\n", str)
+			res = m.makeError(errcode.NotFound, str)
+			return m.sendResponse(conn, id, &res)
+		} else if cnt == len(jobs) || (args.Any && cnt > 0) {
+			res = m.makeResponse(fmt.Sprintf("%d of %d Jobs are done",
+				cnt,
+				len(jobs)))
+			res.Jobs = jobs
+			return m.sendResponse(conn, id, &res)
+		} else if !m.active.Load() {
+			str = "Monitor is shutting down"
+			m.log.Printf("[ERROR] %s\n", str)
+			res = m.makeError(errcode.Internal, str)
+			return m.sendResponse(conn, id, &res)
+		}
+
+		select {
+		case <-done:
+			continue
+		case <-hangup:
+			m.log.Printf("[INFO] Client hung up while waiting for Jobs %v\n",
+				args.IDs)
+			return nil
+		case <-timeout:
+			str = fmt.Sprintf("Timed out after %s waiting for Jobs %v",
+				args.Timeout,
+				args.IDs)
+			m.log.Printf("[INFO] %s\n", str)
+			res = m.makeError(errcode.Timeout, str)
+			res.Jobs = jobs
+			return m.sendResponse(conn, id, &res)
+		}
+	}
+} // func (m *Monitor) jobWait(id uint64, args *JobWaitArgs, conn *Conn) error
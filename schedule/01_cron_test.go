@@ -0,0 +1,92 @@
+// /home/krylon/go/src/github.com/blicero/jobq/schedule/01_cron_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 23:41:09 krylon>
+
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	type testCase struct {
+		spec   string
+		from   time.Time
+		expect time.Time
+	}
+
+	var (
+		// 2026-10-18 is a Sunday.
+		from      = time.Date(2026, 10, 18, 10, 7, 30, 0, time.UTC)
+		testCases = []testCase{
+			{"*/15 * * * *", from, time.Date(2026, 10, 18, 10, 15, 0, 0, time.UTC)},
+			{"* * * * *", from, time.Date(2026, 10, 18, 10, 8, 0, 0, time.UTC)},
+			{"7 * * * *", from, time.Date(2026, 10, 18, 11, 7, 0, 0, time.UTC)},
+			{"0 2 * * *", from, time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+			{"30 4 1 * *", from, time.Date(2026, 11, 1, 4, 30, 0, 0, time.UTC)},
+			{"0 0 * * mon", from, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+			{"0 12 * * 7", from, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+			{"0 9-17/4 * * 1-5", from, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+			{"15,45 * * * *", from, time.Date(2026, 10, 18, 10, 15, 0, 0, time.UTC)},
+			// Either the 13th or a Friday
+			{"0 0 13 * fri", from, time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+			{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+			{"@yearly", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{"@hourly", from, time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)},
+			{"59 23 31 dec *", time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2027, 12, 31, 23, 59, 0, 0, time.UTC)},
+			{"0 0 30 2 *", from, time.Time{}},
+		}
+	)
+
+	for _, c := range testCases {
+		var (
+			err  error
+			cron *Cron
+			next time.Time
+		)
+
+		if cron, err = ParseCron(c.spec); err != nil {
+			t.Errorf("Cannot parse %q: %s",
+				c.spec,
+				err.Error())
+		} else if next = cron.Next(c.from); !next.Equal(c.expect) {
+			t.Errorf("Unexpected next time for %q after %s: %s (expected %s)",
+				c.spec,
+				c.from,
+				next,
+				c.expect)
+		}
+	}
+} // func TestCronNext(t *testing.T)
+
+func TestCronInvalid(t *testing.T) {
+	var invalid = []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	}
+
+	for _, spec := range invalid {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("Parsing %q should have failed", spec)
+		} else if !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("Error parsing %q should wrap ErrInvalidCron: %s",
+				spec,
+				err.Error())
+		}
+	}
+} // func TestCronInvalid(t *testing.T)
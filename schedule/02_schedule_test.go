@@ -0,0 +1,102 @@
+// /home/krylon/go/src/github.com/blicero/jobq/schedule/02_schedule_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 23:49:30 krylon>
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleDue(t *testing.T) {
+	type testCase struct {
+		policy  Policy
+		nextRun time.Time
+		now     time.Time
+		expect  int
+	}
+
+	var (
+		base      = time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+		next      = base.Add(time.Hour)
+		testCases = []testCase{
+			// Not due, yet
+			{MissedSkip, base, base.Add(-time.Second), 0},
+			// Right on time
+			{MissedSkip, base, base.Add(time.Second * 5), 1},
+			{MissedCatchUp, base, base.Add(time.Second * 5), 1},
+			// A single firing, but too late
+			{MissedSkip, base, base.Add(time.Minute * 5), 0},
+			{MissedRunOnce, base, base.Add(time.Minute * 5), 1},
+			{MissedCatchUp, base, base.Add(time.Minute * 5), 1},
+			// The Monitor was down for three and a half hours
+			{MissedSkip, base, base.Add(time.Minute * 210), 0},
+			{MissedRunOnce, base, base.Add(time.Minute * 210), 1},
+			{MissedCatchUp, base, base.Add(time.Minute * 210), 4},
+			// The Monitor was down for three hours, but came back in
+			// time for the latest firing.
+			{MissedSkip, base, base.Add(time.Hour*3 + time.Second), 1},
+			{MissedCatchUp, base, base.Add(time.Hour*3 + time.Second), 4},
+			// The Monitor was down for a long time
+			{MissedCatchUp, base, base.AddDate(1, 0, 0), MaxCatchUp},
+		}
+	)
+
+	for idx, c := range testCases {
+		var (
+			err error
+			cnt int
+			nxt time.Time
+			s   = Schedule{
+				Spec:    "@hourly",
+				Cmd:     []string{"/bin/true"},
+				Missed:  c.policy,
+				NextRun: c.nextRun,
+			}
+		)
+
+		if cnt, nxt, err = s.Due(c.now); err != nil {
+			t.Errorf("Test case %d: %s", idx, err.Error())
+		} else if cnt != c.expect {
+			t.Errorf("Test case %d (%s): unexpected number of Jobs due: %d (expected %d)",
+				idx,
+				c.policy,
+				cnt,
+				c.expect)
+		} else if !nxt.After(c.now) {
+			t.Errorf("Test case %d: next run %s should be after %s",
+				idx,
+				nxt,
+				c.now)
+		} else if c.expect == 0 && c.now.Before(c.nextRun) && !nxt.Equal(c.nextRun) {
+			t.Errorf("Test case %d: next run should be unchanged: %s (expected %s)",
+				idx,
+				nxt,
+				next)
+		}
+	}
+} // func TestScheduleDue(t *testing.T)
+
+func TestScheduleValidate(t *testing.T) {
+	var invalid = []Schedule{
+		{Spec: "* * * *", Cmd: []string{"/bin/true"}},
+		{Spec: "0 0 30 2 *", Cmd: []string{"/bin/true"}},
+		{Spec: "@daily"},
+		{Spec: "@daily", Cmd: []string{"/bin/true"}, Missed: MissedCatchUp + 1},
+	}
+
+	for idx, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Schedule %d should be invalid: %#v", idx, s)
+		}
+	}
+
+	var s = Schedule{Spec: "@daily", Cmd: []string{"/bin/true"}, Missed: MissedRunOnce}
+
+	if err := s.Validate(); err != nil {
+		t.Errorf("Schedule should be valid: %s", err.Error())
+	}
+} // func TestScheduleValidate(t *testing.T)
@@ -0,0 +1,245 @@
+// /home/krylon/go/src/github.com/blicero/jobq/schedule/cron.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 22:40:18 krylon>
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron indicates that a cron expression could not be parsed.
+var ErrInvalidCron = errors.New("Invalid cron expression")
+
+// Cron is a parsed cron expression in the format used by crontab(5):
+//
+//	minute hour day-of-month month day-of-week
+//
+// Each field may be "*", a number, a range ("1-5"), a list ("1,3,5"), or any
+// of these followed by a step ("*/15", "0-30/10"). Months and weekdays may
+// also be given by their English three-letter abbreviations.
+// Like cron, if both day-of-month and day-of-week are restricted, a day
+// matches if either of them does.
+//
+// Instead of the five fields, the shorthands @yearly (or @annually),
+// @monthly, @weekly, @daily (or @midnight) and @hourly are accepted.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, monthNames},
+	// 7 is Sunday, too, we fold it into 0 after parsing.
+	{"day of week", 0, 7, dayNames},
+}
+
+// ParseCron parses a cron expression.
+func ParseCron(expr string) (*Cron, error) {
+	var (
+		err    error
+		c      = new(Cron)
+		fields []string
+		masks  = []*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	)
+
+	expr = strings.TrimSpace(expr)
+
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	if fields = strings.Fields(expr); len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w %q: expected %d fields, not %d",
+			ErrInvalidCron,
+			expr,
+			len(cronFields),
+			len(fields))
+	}
+
+	for idx, f := range fields {
+		if *masks[idx], err = cronFields[idx].parse(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.dow&(1<<7) != 0 {
+		c.dow = (c.dow | 1) &^ (1 << 7)
+	}
+
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return c, nil
+} // func ParseCron(expr string) (*Cron, error)
+
+// parse parses one field of a cron expression into a bitmask of the values
+// it matches.
+func (f *cronField) parse(s string) (uint64, error) {
+	var mask uint64
+
+	for _, part := range strings.Split(s, ",") {
+		var (
+			err        error
+			lo, hi     int
+			step       = 1
+			rng, stepS string
+			hasStep    bool
+		)
+
+		rng, stepS, hasStep = strings.Cut(part, "/")
+
+		if hasStep {
+			if step, err = strconv.Atoi(stepS); err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: invalid step %q in %s field",
+					ErrInvalidCron,
+					stepS,
+					f.name)
+			}
+		}
+
+		if rng == "*" {
+			lo, hi = f.min, f.max
+		} else if a, b, isRange := strings.Cut(rng, "-"); isRange {
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			} else if hi, err = f.value(b); err != nil {
+				return 0, err
+			} else if lo > hi {
+				return 0, fmt.Errorf("%w: invalid range %q in %s field",
+					ErrInvalidCron,
+					rng,
+					f.name)
+			}
+		} else if lo, err = f.value(rng); err != nil {
+			return 0, err
+		} else if hasStep {
+			// Like cron, "5/15" means "5-max/15"
+			hi = f.max
+		} else {
+			hi = lo
+		}
+
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+
+	return mask, nil
+} // func (f *cronField) parse(s string) (uint64, error)
+
+// value parses a single value of a field, either a number or a name.
+func (f *cronField) value(s string) (int, error) {
+	for idx, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return idx, nil
+		}
+	}
+
+	var v, err = strconv.Atoi(s)
+
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: invalid value %q in %s field (%d-%d)",
+			ErrInvalidCron,
+			s,
+			f.name,
+			f.min,
+			f.max)
+	}
+
+	return v, nil
+} // func (f *cronField) value(s string) (int, error)
+
+// maxYears is how far into the future Next looks for a matching time before
+// giving up. Expressions like "0 0 30 2 *" never match.
+const maxYears = 5
+
+// Next returns the first time after t that matches the expression, with a
+// resolution of one minute. If there is no such time within the next few
+// years, it returns the zero time.
+func (c *Cron) Next(t time.Time) time.Time {
+	var (
+		loc   = t.Location()
+		limit = t.Year() + maxYears
+	)
+
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+
+WRAP:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !c.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t
+} // func (c *Cron) Next(t time.Time) time.Time
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	var (
+		dom = c.dom&(1<<uint(t.Day())) != 0
+		dow = c.dow&(1<<uint(t.Weekday())) != 0
+	)
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+} // func (c *Cron) dayMatches(t time.Time) bool
@@ -0,0 +1,210 @@
+// /home/krylon/go/src/github.com/blicero/jobq/schedule/schedule.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 18. 10. 2026 by Benjamin Walkenhorst
+// (c) 2026 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 22:58:44 krylon>
+
+// Package schedule provides recurring Jobs, which the Monitor submits to the
+// queue according to a cron expression.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blicero/jobq/job"
+)
+
+// Policy determines what happens to firings of a Schedule that were missed,
+// usually because the Monitor was not running at the time.
+type Policy uint8
+
+// MissedSkip means missed firings are ignored.
+// MissedRunOnce means a single Job is submitted for any number of missed
+// firings.
+// MissedCatchUp means one Job is submitted for each missed firing, up to
+// MaxCatchUp Jobs.
+const (
+	MissedSkip Policy = iota
+	MissedRunOnce
+	MissedCatchUp
+)
+
+var policyNames = map[Policy]string{
+	MissedSkip:    "skip",
+	MissedRunOnce: "once",
+	MissedCatchUp: "catchup",
+}
+
+func (p Policy) String() string {
+	if name, ok := policyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Policy(%d)", p)
+} // func (p Policy) String() string
+
+// ParsePolicy converts the name of a Policy to its value.
+func ParsePolicy(s string) (Policy, error) {
+	switch strings.ToLower(s) {
+	case "skip":
+		return MissedSkip, nil
+	case "once", "run-once":
+		return MissedRunOnce, nil
+	case "catchup", "catch-up":
+		return MissedCatchUp, nil
+	default:
+		return MissedSkip, fmt.Errorf("%w: invalid policy for missed firings %q",
+			ErrInvalidSchedule,
+			s)
+	}
+} // func ParsePolicy(s string) (Policy, error)
+
+// Grace is how late a firing may be handled before it counts as missed.
+const Grace = time.Minute
+
+// MaxCatchUp is the maximum number of Jobs submitted at once for the missed
+// firings of a Schedule with the MissedCatchUp policy.
+const MaxCatchUp = 100
+
+// ErrInvalidSchedule indicates that a Schedule is not valid.
+var ErrInvalidSchedule = errors.New("Invalid Schedule")
+
+// Schedule describes a Job that is run repeatedly.
+//
+// Spec is the cron expression that determines when the Schedule fires, see
+// Cron.
+//
+// Cmd, Options, Env, EnvSet and Priority are used for the Jobs the Schedule
+// creates, see job.Job.
+//
+// Missed is the Policy for firings that were missed.
+//
+// Paused Schedules do not fire.
+//
+// LastRun is the last time the Schedule fired, NextRun is the next time it
+// will fire.
+type Schedule struct {
+	ID       int64
+	Spec     string
+	Cmd      []string
+	Options  job.Options
+	Env      []string
+	EnvSet   []string
+	Priority int
+	Missed   Policy
+	Paused   bool
+	Created  time.Time
+	LastRun  time.Time
+	NextRun  time.Time
+}
+
+// Validate checks the Schedule for invalid values.
+func (s *Schedule) Validate() error {
+	var (
+		err error
+		c   *Cron
+	)
+
+	if c, err = ParseCron(s.Spec); err != nil {
+		return err
+	} else if c.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: %q never fires",
+			ErrInvalidSchedule,
+			s.Spec)
+	} else if len(s.Cmd) == 0 {
+		return fmt.Errorf("%w: no command given",
+			ErrInvalidSchedule)
+	} else if _, ok := policyNames[s.Missed]; !ok {
+		return fmt.Errorf("%w: invalid policy for missed firings %d",
+			ErrInvalidSchedule,
+			s.Missed)
+	}
+
+	return s.Options.Validate()
+} // func (s *Schedule) Validate() error
+
+// Next returns the first time after t at which the Schedule fires.
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	var (
+		err error
+		c   *Cron
+	)
+
+	if c, err = ParseCron(s.Spec); err != nil {
+		return time.Time{}, err
+	}
+
+	return c.Next(t), nil
+} // func (s *Schedule) Next(t time.Time) (time.Time, error)
+
+// Due returns the number of Jobs to submit for the firings of the Schedule
+// up to now, and the time the Schedule fires next.
+// A firing that is handled within Grace is run normally, firings older than
+// that are handled according to the Schedule's Policy.
+func (s *Schedule) Due(now time.Time) (int, time.Time, error) {
+	var (
+		err  error
+		c    *Cron
+		cnt  int
+		last time.Time
+		next time.Time
+	)
+
+	if c, err = ParseCron(s.Spec); err != nil {
+		return 0, time.Time{}, err
+	} else if s.Paused || s.NextRun.After(now) {
+		return 0, s.NextRun, nil
+	}
+
+	for t := s.NextRun; !t.IsZero() && !t.After(now) && cnt <= MaxCatchUp; t = c.Next(t) {
+		cnt++
+		last = t
+	}
+
+	next = c.Next(now)
+
+	if cnt == 1 && now.Sub(last) <= Grace {
+		return 1, next, nil
+	}
+
+	switch s.Missed {
+	case MissedRunOnce:
+		return 1, next, nil
+	case MissedCatchUp:
+		if cnt > MaxCatchUp {
+			cnt = MaxCatchUp
+		}
+		return cnt, next, nil
+	default:
+		// We skip the firings we missed, but if the last one is
+		// recent enough, we run that one.
+		if recent := c.Next(now.Add(-Grace)); !recent.IsZero() && !recent.After(now) {
+			return 1, next, nil
+		}
+		return 0, next, nil
+	}
+} // func (s *Schedule) Due(now time.Time) (int, time.Time, error)
+
+// MakeJob creates a new Job from the Schedule.
+func (s *Schedule) MakeJob() (*job.Job, error) {
+	var (
+		err error
+		j   *job.Job
+		cmd = make([]string, len(s.Cmd))
+	)
+
+	copy(cmd, s.Cmd)
+
+	if j, err = job.New(s.Options, cmd...); err != nil {
+		return nil, err
+	}
+
+	j.Env = s.Env
+	j.EnvSet = s.EnvSet
+	j.Priority = s.Priority
+
+	return j, nil
+} // func (s *Schedule) MakeJob() (*job.Job, error)